@@ -0,0 +1,52 @@
+package main
+
+import (
+	"ct-padel-s/src/features/padel/fsck"
+	"ct-padel-s/src/infrastructure/database"
+	_ "ct-padel-s/src/infrastructure/logging" // Import for colored logging init
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// Checks every match for rows without a parent, numbering gaps and
+// duplicates, plays after the play that ended a point and plays by players
+// who are not in the match. By default it only reports what it finds and
+// exits with status 1 if anything is wrong; with -repair it fixes it all in
+// one transaction.
+func main() {
+	repair := flag.Bool("repair", false, "fix the problems found instead of only reporting them")
+	flag.Parse()
+
+	db, err := database.Initialize()
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	if !*repair {
+		problems, err := fsck.Check(db)
+		if err != nil {
+			panic(err)
+		}
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+		if len(problems) > 0 {
+			slog.Warn("Found problems; run with -repair to fix them", "problems", len(problems))
+			os.Exit(1)
+		}
+		slog.Info("No problems found")
+		return
+	}
+
+	repaired, err := fsck.Repair(db)
+	if err != nil {
+		panic(err)
+	}
+	for _, problem := range repaired {
+		fmt.Println(problem)
+	}
+	slog.Info("Repair completed successfully", "problems", len(repaired))
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"ct-padel-s/src/features/padel/rating/ratingservice"
+	"ct-padel-s/src/infrastructure/database"
+	_ "ct-padel-s/src/infrastructure/logging" // Import for colored logging init
+	"log/slog"
+)
+
+// Recomputes every player rating from scratch by replaying all completed
+// matches in date order. Run it after editing or deleting points of matches
+// that were already rated.
+func main() {
+	db, err := database.Initialize()
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	rated, err := ratingservice.Recompute(db)
+	if err != nil {
+		panic(err)
+	}
+
+	slog.Info("Ratings recomputed successfully", "matches", rated)
+}
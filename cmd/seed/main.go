@@ -0,0 +1,45 @@
+package main
+
+import (
+	"ct-padel-s/src/features/padel/seed"
+	"ct-padel-s/src/infrastructure/database"
+	_ "ct-padel-s/src/infrastructure/logging" // Import for colored logging init
+	"flag"
+	"log/slog"
+	"time"
+)
+
+// Generates players and complete, rated matches for local development and
+// demos. The same -seed always generates the same players and rallies; run
+// it against an empty database, as it adds to whatever is already there.
+func main() {
+	seedValue := flag.Uint64("seed", 1, "seed for the random generator")
+	players := flag.Int("players", 8, "number of players to create")
+	matches := flag.Int("matches", 6, "number of matches to play between them")
+	start := flag.String("start", "2025-01-06", "date of the first match (YYYY-MM-DD); the rest follow a day apart")
+	flag.Parse()
+
+	startDate, err := time.Parse(time.DateOnly, *start)
+	if err != nil {
+		panic(err)
+	}
+
+	db, err := database.Initialize()
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	summary, err := seed.Run(db, seed.Options{
+		Seed:    *seedValue,
+		Players: *players,
+		Matches: *matches,
+		// Evening matches
+		Start: startDate.Add(19 * time.Hour),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	slog.Info("Seeded successfully", "players", summary.Players, "matches", summary.Matches, "points", summary.Points, "plays", summary.Plays)
+}
@@ -1,14 +1,27 @@
 package src
 
 import (
+	"ct-padel-s/src/features/health"
 	"ct-padel-s/src/features/home"
+	"ct-padel-s/src/features/padel/analytics"
 	"ct-padel-s/src/features/padel/game"
+	"ct-padel-s/src/features/padel/hierarchy"
 	"ct-padel-s/src/features/padel/match"
+	"ct-padel-s/src/features/padel/pairing"
 	"ct-padel-s/src/features/padel/play"
+	"ct-padel-s/src/features/padel/player"
 	"ct-padel-s/src/features/padel/point"
+	"ct-padel-s/src/features/padel/rating"
+	"ct-padel-s/src/features/padel/schedule"
 	"ct-padel-s/src/features/padel/set"
+	"ct-padel-s/src/features/padel/shot"
+	"ct-padel-s/src/features/padel/simulation"
+	"ct-padel-s/src/features/padel/tournament"
+	"ct-padel-s/src/features/padel/video"
 	"ct-padel-s/src/infrastructure/database"
 	"ct-padel-s/src/infrastructure/fileserver"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/infrastructure/metrics"
 	"log"
 	"log/slog"
 	"net/http"
@@ -27,31 +40,78 @@ func App() {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
+	metrics.RegisterDB(db)
+
 	// Routes
 	mux := http.NewServeMux()
 
-	// Hypermedia routes (HTML)
+	// Operational routes
+	mux.HandleFunc("GET /healthz", health.Healthz)
+	mux.HandleFunc("GET /readyz", health.Readyz)
+	mux.HandleFunc("GET /version", health.Version)
+	mux.Handle("GET /metrics", metrics.Handler())
+
+	// Hypermedia routes (HTML). Routes below a match are wrapped with
+	// hierarchy.Load, which resolves and ownership-checks every ID in the path.
 	mux.HandleFunc("GET /matches", match.GetAll)
 	mux.HandleFunc("POST /matches", match.Create)
-	mux.HandleFunc("GET /matches/{matchID}", match.Get)
-	mux.HandleFunc("DELETE /matches/{matchID}", match.Delete)
+	mux.HandleFunc("GET /matches/{matchID}", hierarchy.Load(match.Get))
+	mux.HandleFunc("DELETE /matches/{matchID}", hierarchy.Load(match.Delete))
+	mux.HandleFunc("POST /matches/{matchID}/complete", hierarchy.Load(match.Complete))
+	mux.HandleFunc("POST /matches/{matchID}/rally-checks", hierarchy.Load(match.SetRallyChecks))
+	mux.HandleFunc("GET /matches/{matchID}/analytics", hierarchy.Load(analytics.Get))
+	mux.HandleFunc("POST /matches/{matchID}/video", hierarchy.Load(video.SetURL))
+	mux.HandleFunc("POST /matches/{matchID}/video/offsets", hierarchy.Load(video.SetOffsets))
+	mux.HandleFunc("GET /matches/{matchID}/video/chapters.vtt", hierarchy.Load(video.Chapters))
+
+	mux.HandleFunc("GET /players", player.GetAll)
+	mux.HandleFunc("GET /players/{playerID}", player.Get)
+	mux.HandleFunc("GET /pairings", pairing.Get)
+	mux.HandleFunc("GET /simulate", simulation.Get)
+	mux.HandleFunc("GET /ratings", rating.Leaderboard)
+
+	mux.HandleFunc("GET /players/{playerID}/calendar.ics", schedule.Calendar)
+
+	mux.HandleFunc("GET /schedule", schedule.Get)
+	mux.HandleFunc("POST /schedule/courts", schedule.CreateCourt)
+	mux.HandleFunc("POST /schedule/slots", schedule.CreateSlot)
+	mux.HandleFunc("DELETE /schedule/slots/{slotID}", schedule.DeleteSlot)
+	mux.HandleFunc("POST /schedule/slots/{slotID}/booking", schedule.Book)
+	mux.HandleFunc("DELETE /schedule/slots/{slotID}/booking", schedule.Release)
+
+	mux.HandleFunc("GET /shots", shot.Get)
+	mux.HandleFunc("POST /shots/contact-types", shot.CreateContactType)
+	mux.HandleFunc("POST /shots/contact-types/{code}", shot.UpdateContactType)
+	mux.HandleFunc("POST /shots/effects", shot.CreateEffect)
+	mux.HandleFunc("POST /shots/effects/{code}", shot.UpdateEffect)
+
+	mux.HandleFunc("GET /tournaments", tournament.GetAll)
+	mux.HandleFunc("POST /tournaments", tournament.Create)
+	mux.HandleFunc("GET /tournaments/{tournamentID}", tournament.Get)
+	mux.HandleFunc("DELETE /tournaments/{tournamentID}", tournament.Delete)
+	mux.HandleFunc("POST /tournaments/{tournamentID}/divisions", tournament.CreateDivision)
+	mux.HandleFunc("POST /tournaments/{tournamentID}/divisions/{divisionID}/pairs", tournament.CreatePair)
+	mux.HandleFunc("POST /tournaments/{tournamentID}/divisions/{divisionID}/fixtures", tournament.GenerateFixtures)
 
-	mux.HandleFunc("POST /matches/{matchID}/sets", set.Create)
-	mux.HandleFunc("GET /matches/{matchID}/sets/{setID}", set.Get)
-	mux.HandleFunc("DELETE /matches/{matchID}/sets/{setID}", set.Delete)
+	mux.HandleFunc("POST /matches/{matchID}/sets", hierarchy.Load(set.Create))
+	mux.HandleFunc("GET /matches/{matchID}/sets/{setID}", hierarchy.Load(set.Get))
+	mux.HandleFunc("DELETE /matches/{matchID}/sets/{setID}", hierarchy.Load(set.Delete))
 
-	mux.HandleFunc("POST /matches/{matchID}/sets/{setID}/games", game.Create)
-	mux.HandleFunc("GET /matches/{matchID}/sets/{setID}/games/{gameID}", game.Get)
-	mux.HandleFunc("DELETE /matches/{matchID}/sets/{setID}/games/{gameID}", game.Delete)
+	mux.HandleFunc("POST /matches/{matchID}/sets/{setID}/games", hierarchy.Load(game.Create))
+	mux.HandleFunc("GET /matches/{matchID}/sets/{setID}/games/{gameID}", hierarchy.Load(game.Get))
+	mux.HandleFunc("DELETE /matches/{matchID}/sets/{setID}/games/{gameID}", hierarchy.Load(game.Delete))
 
-	mux.HandleFunc("POST /matches/{matchID}/sets/{setID}/games/{gameID}/points", point.Create)
-	mux.HandleFunc("GET /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}", point.Get)
-	mux.HandleFunc("DELETE /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}", point.Delete)
+	mux.HandleFunc("POST /matches/{matchID}/sets/{setID}/games/{gameID}/points", hierarchy.Load(point.Create))
+	mux.HandleFunc("GET /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}", hierarchy.Load(point.Get))
+	mux.HandleFunc("DELETE /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}", hierarchy.Load(point.Delete))
+	mux.HandleFunc("POST /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}/start", hierarchy.Load(point.Start))
+	mux.HandleFunc("POST /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}/end", hierarchy.Load(point.End))
 
-	mux.HandleFunc("POST /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}/plays", play.Create)
-	mux.HandleFunc("GET /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}/plays/{playID}", play.Get)
-	mux.HandleFunc("PATCH /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}/plays/{playID}", play.Patch)
-	mux.HandleFunc("DELETE /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}/plays/{playID}", play.Delete)
+	mux.HandleFunc("POST /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}/plays", hierarchy.Load(play.Create))
+	mux.HandleFunc("GET /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}/plays/{playID}", hierarchy.Load(play.Get))
+	mux.HandleFunc("PATCH /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}/plays/{playID}", hierarchy.Load(play.Patch))
+	mux.HandleFunc("POST /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}/plays/{playID}/commit", hierarchy.Load(play.Commit))
+	mux.HandleFunc("DELETE /matches/{matchID}/sets/{setID}/games/{gameID}/points/{pointID}/plays/{playID}", hierarchy.Load(play.Delete))
 
 	// Home page
 	mux.HandleFunc("/", home.Handler)
@@ -66,5 +126,5 @@ func App() {
 	mux.Handle("/static/", http.StripPrefix("/static/", cachedFS))
 
 	slog.Info("Server starting on http://localhost:8080")
-	log.Fatal(http.ListenAndServe("localhost:8080", mux))
+	log.Fatal(http.ListenAndServe("localhost:8080", logging.Middleware(metrics.Middleware(mux))))
 }
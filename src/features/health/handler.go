@@ -0,0 +1,98 @@
+package health
+
+import (
+	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+)
+
+type readyResponse struct {
+	Status            string `json:"status"`
+	Database          string `json:"database"`
+	PendingMigrations []int  `json:"pending_migrations,omitempty"`
+}
+
+type versionResponse struct {
+	Module    string `json:"module"`
+	Version   string `json:"version"`
+	GoVersion string `json:"go_version"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified"`
+}
+
+// Healthz reports that the process is alive and serving requests.
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz reports whether the app can serve traffic: the database must answer a
+// ping and every registered migration must be recorded in schema_migrations.
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	db := database.GetDB()
+
+	if !db.IsHealthy() {
+		logger.Warn("Readiness check failed", "reason", "database unreachable")
+		writeJSON(w, http.StatusServiceUnavailable, readyResponse{Status: "unavailable", Database: "unreachable"})
+		return
+	}
+
+	pending, err := database.PendingMigrations(db)
+	if err != nil {
+		logger.Error("Failed to check migrations", "error", err)
+		writeJSON(w, http.StatusServiceUnavailable, readyResponse{Status: "unavailable", Database: "ok"})
+		return
+	}
+
+	if len(pending) > 0 {
+		versions := make([]int, 0, len(pending))
+		for _, migration := range pending {
+			versions = append(versions, migration.Version)
+		}
+		logger.Warn("Readiness check failed", "reason", "pending migrations", "versions", versions)
+		writeJSON(w, http.StatusServiceUnavailable, readyResponse{Status: "unavailable", Database: "ok", PendingMigrations: versions})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, readyResponse{Status: "ok", Database: "ok"})
+}
+
+// Version reports the build information embedded by the Go toolchain.
+func Version(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		logger.Error("Build info not available")
+		http.Error(w, "Build info not available", http.StatusInternalServerError)
+		return
+	}
+
+	version := versionResponse{
+		Module:    info.Main.Path,
+		Version:   info.Main.Version,
+		GoVersion: info.GoVersion,
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			version.Revision = setting.Value
+		case "vcs.time":
+			version.Time = setting.Value
+		case "vcs.modified":
+			version.Modified = setting.Value == "true"
+		}
+	}
+
+	writeJSON(w, http.StatusOK, version)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
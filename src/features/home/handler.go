@@ -1,14 +1,15 @@
 package home
 
 import (
+	"ct-padel-s/src/infrastructure/logging"
 	"ct-padel-s/src/shared/components/footer"
 	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
 	"ct-padel-s/src/shared/templates"
 	"ct-padel-s/src/shared/utils"
 	_ "embed"
 	"html/template"
 	"io"
-	"log/slog"
 	"net/http"
 )
 
@@ -17,30 +18,31 @@ var homeHTML string
 var homeComponent = utils.NewComponent("home.html", homeHTML)
 
 func Handler(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Rendering index page", "pages", "index", "path", r.URL.Path)
+	logger := logging.FromRequest(r)
+	logger.Debug("Rendering index page", "pages", "index", "path", r.URL.Path)
 	if r.URL.Path != "/" {
-		slog.Warn("Path not found", "path", r.URL.Path)
-		http.NotFound(w, r)
+		logger.Warn("Path not found", "path", r.URL.Path)
+		httperror.NotFound(w, r, "The page you are looking for does not exist.")
 		return
 	}
 
 	header, err := header.Render(header.Data{Title: "CT Padel Tracker"})
 
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
 	footer, err := footer.Render(footer.Data{})
 
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
 	content, err := homeComponent.Render(nil)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
@@ -52,10 +54,10 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	slog.Info("Index page rendered successfully", "pages", "index")
+	logger.Info("Index page rendered successfully", "pages", "index")
 	io.WriteString(w, string(page))
 }
@@ -0,0 +1,126 @@
+package analyticsmodel
+
+import (
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/shot/shotmodel"
+	"database/sql"
+	"strings"
+)
+
+// Result types recorded on the play that ends a point
+const (
+	ResultWinner        = string(playmodel.ResultNoReturnWinner)
+	ResultError         = string(playmodel.ResultError)
+	ResultUnforcedError = string(playmodel.ResultUnforcedError)
+)
+
+type Team int
+
+const (
+	TeamUnknown Team = iota
+	Team1
+	Team2
+)
+
+// Opponent returns the other team, or TeamUnknown for TeamUnknown.
+func (t Team) Opponent() Team {
+	switch t {
+	case Team1:
+		return Team2
+	case Team2:
+		return Team1
+	}
+	return TeamUnknown
+}
+
+// TeamOf returns the team playerID plays for in match.
+func TeamOf(match *matchmodel.MatchWithPlayers, playerID int64) Team {
+	switch int(playerID) {
+	case match.Team1Player1ID, match.Team1Player2ID:
+		return Team1
+	case match.Team2Player1ID, match.Team2Player2ID:
+		return Team2
+	}
+	return TeamUnknown
+}
+
+// Rally is a point together with its plays in play_number order.
+type Rally struct {
+	PointID       int               `json:"point_id"`
+	SetNumber     int               `json:"set_number"`
+	GameNumber    int               `json:"game_number"`
+	PointNumber   int               `json:"point_number"`
+	VideoOffsetMs sql.NullInt64     `json:"video_offset_ms"`
+	StartedAt     sql.NullTime      `json:"started_at"`
+	EndedAt       sql.NullTime      `json:"ended_at"`
+	Plays         []*playmodel.Play `json:"plays"`
+}
+
+// Length is the number of shots in the rally.
+func (r *Rally) Length() int {
+	return len(r.Plays)
+}
+
+// Last returns the final play of the rally, or nil if none were recorded.
+func (r *Rally) Last() *playmodel.Play {
+	if len(r.Plays) == 0 {
+		return nil
+	}
+	return r.Plays[len(r.Plays)-1]
+}
+
+// Result is the result type of the final play, empty while the point is
+// still being recorded.
+func (r *Rally) Result() string {
+	last := r.Last()
+	if last == nil || !last.ResultType.Valid {
+		return ""
+	}
+	return last.ResultType.String
+}
+
+// Complete reports whether the rally ended with a winner or an error.
+func (r *Rally) Complete() bool {
+	return r.Result() != ""
+}
+
+// EndedByError reports whether the rally ended with a forced or unforced error.
+func (r *Rally) EndedByError() bool {
+	result := r.Result()
+	return result == ResultError || result == ResultUnforcedError
+}
+
+// Winner returns the team that won the point: the hitter's team for a
+// winner, their opponents for an error. It is TeamUnknown for incomplete
+// rallies and for final plays without a player.
+func (r *Rally) Winner(match *matchmodel.MatchWithPlayers) Team {
+	last := r.Last()
+	if !r.Complete() || !last.PlayerID.Valid {
+		return TeamUnknown
+	}
+
+	hitter := TeamOf(match, last.PlayerID.Int64)
+	if r.Result() == ResultWinner {
+		return hitter
+	}
+	return hitter.Opponent()
+}
+
+// ShotLabel names a play by how the ball was struck, e.g. "serve",
+// "volley drop" or "bandeja", using the catalogue's labels. An effect tied
+// to a contact type names the shot on its own.
+func ShotLabel(play *playmodel.Play, catalogue *shotmodel.Catalogue) string {
+	if effect := catalogue.Effect(play.ShotEffect.String); effect != nil && effect.ContactType.Valid {
+		return strings.ToLower(effect.Label)
+	}
+
+	label := "unknown"
+	if play.ContactType.Valid && play.ContactType.String != "" {
+		label = strings.ToLower(catalogue.ContactLabel(play.ContactType.String))
+	}
+	if play.ShotEffect.Valid && play.ShotEffect.String != "" && play.ShotEffect.String != string(playmodel.EffectFlat) {
+		label += " " + strings.ToLower(catalogue.EffectLabel(play.ShotEffect.String))
+	}
+	return label
+}
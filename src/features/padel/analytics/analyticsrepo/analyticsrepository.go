@@ -0,0 +1,79 @@
+package analyticsrepo
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// GetRalliesByMatch loads every point of a match that has at least one play,
+// ordered by set, game and point number, with plays in play_number order.
+func GetRalliesByMatch(db *database.DB, matchID int) ([]*analyticsmodel.Rally, error) {
+	query := `SELECT s.set_number, g.game_number, pt.point_number, pt.video_offset_ms, pt.started_at, pt.ended_at,
+			  pl.id, pl.point_id, pl.play_number, pl.player_id, pl.ball_position_x, pl.ball_position_y, pl.result_type, pl.hand_side, pl.contact_type, pl.shot_effect, pl.wall_bounces, pl.exit_court, pl.video_offset_ms, pl.hit_at, pl.version, pl.created_at, pl.updated_at
+			  FROM sets s
+			  JOIN games g ON g.set_id = s.id
+			  JOIN points pt ON pt.game_id = g.id
+			  JOIN plays pl ON pl.point_id = pt.id
+			  WHERE s.match_id = $1
+			  ORDER BY s.set_number, g.game_number, pt.point_number, pl.play_number`
+	rows, err := db.Query(query, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rallies []*analyticsmodel.Rally
+	var current *analyticsmodel.Rally
+	for rows.Next() {
+		var setNumber, gameNumber, pointNumber int
+		var pointOffset sql.NullInt64
+		var startedAt, endedAt sql.NullTime
+		var play playmodel.Play
+		err := rows.Scan(
+			&setNumber,
+			&gameNumber,
+			&pointNumber,
+			&pointOffset,
+			&startedAt,
+			&endedAt,
+			&play.ID,
+			&play.PointID,
+			&play.PlayNumber,
+			&play.PlayerID,
+			&play.BallPositionX,
+			&play.BallPositionY,
+			&play.ResultType,
+			&play.HandSide,
+			&play.ContactType,
+			&play.ShotEffect,
+			pq.Array(&play.WallBounces),
+			&play.ExitCourt,
+			&play.VideoOffsetMs,
+			&play.HitAt,
+			&play.Version,
+			&play.CreatedAt,
+			&play.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if current == nil || current.PointID != play.PointID {
+			current = &analyticsmodel.Rally{
+				PointID:       play.PointID,
+				SetNumber:     setNumber,
+				GameNumber:    gameNumber,
+				PointNumber:   pointNumber,
+				VideoOffsetMs: pointOffset,
+				StartedAt:     startedAt,
+				EndedAt:       endedAt,
+			}
+			rallies = append(rallies, current)
+		}
+		current.Plays = append(current.Plays, &play)
+	}
+	return rallies, rows.Err()
+}
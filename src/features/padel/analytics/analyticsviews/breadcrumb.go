@@ -0,0 +1,16 @@
+package analyticsviews
+
+import (
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+)
+
+//go:embed breadcrumb.html
+var breadcrumbHTML string
+var breadcrumbComponent = utils.NewComponent("breadcrumb.html", breadcrumbHTML)
+
+func RenderBreadcrumb(match *matchmodel.MatchWithPlayers) (template.HTML, error) {
+	return breadcrumbComponent.Render(map[string]any{"Match": match})
+}
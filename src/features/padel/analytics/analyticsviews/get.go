@@ -0,0 +1,36 @@
+package analyticsviews
+
+import (
+	"ct-padel-s/src/features/padel/analytics/rallystats"
+	"ct-padel-s/src/features/padel/analytics/timing"
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+)
+
+//go:embed get.html
+var getHTML string
+var getComponent = utils.NewComponent("get.html", getHTML)
+
+type timingView struct {
+	timing.Report
+	Duration      string
+	BetweenPoints string
+	Rally         string
+	LongestRally  string
+}
+
+func RenderGet(match *matchmodel.MatchWithPlayers, rallies rallystats.Report, timingReport timing.Report) (template.HTML, error) {
+	return getComponent.Render(map[string]any{
+		"Match":   match,
+		"Rallies": rallies,
+		"Timing": timingView{
+			Report:        timingReport,
+			Duration:      timing.Format(timingReport.Duration),
+			BetweenPoints: timing.Format(timingReport.BetweenPoints),
+			Rally:         timing.Format(timingReport.Rally),
+			LongestRally:  timing.Format(timingReport.LongestRally),
+		},
+	})
+}
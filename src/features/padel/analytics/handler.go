@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsrepo"
+	"ct-padel-s/src/features/padel/analytics/analyticsviews"
+	"ct-padel-s/src/features/padel/analytics/rallystats"
+	"ct-padel-s/src/features/padel/analytics/timing"
+	"ct-padel-s/src/features/padel/hierarchy"
+	"ct-padel-s/src/features/padel/shot/shotrepo"
+	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/shared/components/footer"
+	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
+	"ct-padel-s/src/shared/templates"
+	"io"
+	"net/http"
+)
+
+func Get(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+	match := hierarchy.FromRequest(r).Match
+
+	rallies, err := analyticsrepo.GetRalliesByMatch(db, match.ID)
+	if err != nil {
+		logger.Error("Failed to get rallies", "error", err, "matchID", match.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get rallies")
+		return
+	}
+
+	catalogue, err := shotrepo.GetCatalogue(db)
+	if err != nil {
+		logger.Error("Failed to get shot catalogue", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get shot catalogue")
+		return
+	}
+
+	report := rallystats.Build(match, rallies, catalogue)
+	timingReport := timing.Build(rallies)
+
+	// Load shared components
+	title := "Analytics: " + match.Name()
+
+	breadcrumb, err := analyticsviews.RenderBreadcrumb(match)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	headerHTML, err := header.Render(header.Data{Title: title + " - Padel Tracker", Breadcrumb: breadcrumb})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	footerHTML, err := footer.Render(footer.Data{})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load feature content and render with data
+	contentHTML, err := analyticsviews.RenderGet(match, report, timingReport)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Compose final page
+	page, err := templates.Render(templates.Data{
+		Title:       title + " - Padel Tracker",
+		HeaderHTML:  headerHTML,
+		ContentHTML: contentHTML,
+		FooterHTML:  footerHTML,
+	})
+
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	io.WriteString(w, string(page))
+}
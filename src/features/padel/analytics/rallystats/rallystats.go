@@ -0,0 +1,405 @@
+package rallystats
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"ct-padel-s/src/features/padel/court"
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"ct-padel-s/src/features/padel/shot/shotmodel"
+	"sort"
+	"strings"
+)
+
+// TopSequences is how many shot sequences each ranking keeps
+const TopSequences = 5
+
+// Bucket groups rallies by length. Max of 0 means no upper bound.
+type Bucket struct {
+	Label string
+	Min   int
+	Max   int
+}
+
+// Buckets are the rally length ranges win percentages are reported for
+var Buckets = []Bucket{
+	{Label: "1-3 shots", Min: 1, Max: 3},
+	{Label: "4-6 shots", Min: 4, Max: 6},
+	{Label: "7-9 shots", Min: 7, Max: 9},
+	{Label: "10+ shots", Min: 10},
+}
+
+func (b Bucket) contains(length int) bool {
+	return length >= b.Min && (b.Max == 0 || length <= b.Max)
+}
+
+type LengthCount struct {
+	Length  int     `json:"length"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+type BucketStat struct {
+	Label           string  `json:"label"`
+	Rallies         int     `json:"rallies"`
+	Team1Wins       int     `json:"team1_wins"`
+	Team2Wins       int     `json:"team2_wins"`
+	Team1WinPercent float64 `json:"team1_win_percent"`
+	Team2WinPercent float64 `json:"team2_win_percent"`
+}
+
+type PlayerBucketStat struct {
+	Label      string  `json:"label"`
+	Rallies    int     `json:"rallies"`
+	Won        int     `json:"won"`
+	WinPercent float64 `json:"win_percent"`
+}
+
+type PlayerStat struct {
+	Player        playermodel.Player  `json:"player"`
+	Team          analyticsmodel.Team `json:"team"`
+	Rallies       int                 `json:"rallies"`
+	AverageLength float64             `json:"average_length"`
+	LongestRally  int                 `json:"longest_rally"`
+	Buckets       []PlayerBucketStat  `json:"buckets"`
+}
+
+type SequenceCount struct {
+	Shots   []string `json:"shots"`
+	Count   int      `json:"count"`
+	Percent float64  `json:"percent"`
+}
+
+// Label renders the sequence as "serve → volley → smash".
+func (s SequenceCount) Label() string {
+	return strings.Join(s.Shots, " → ")
+}
+
+// AreaStat counts the shots hit from one area of the court and how many of
+// them ended the rally.
+type AreaStat struct {
+	Area          court.Area `json:"area"`
+	Label         string     `json:"label"`
+	Shots         int        `json:"shots"`
+	Winners       int        `json:"winners"`
+	Errors        int        `json:"errors"`
+	WinnerPercent float64    `json:"winner_percent"`
+	ErrorPercent  float64    `json:"error_percent"`
+}
+
+// WallStat follows the shots whose ball hit a wall, or left the court, after
+// it bounced: how many won the point outright and how often the reply was an
+// error.
+type WallStat struct {
+	Label   string `json:"label"`
+	Shots   int    `json:"shots"`
+	Winners int    `json:"winners"`
+	// Returns counts the shots that were played back
+	Returns int `json:"returns"`
+	// ReturnErrors counts the returns that were errors
+	ReturnErrors       int     `json:"return_errors"`
+	ReturnErrorPercent float64 `json:"return_error_percent"`
+}
+
+// Report summarises the completed rallies of a match. Rallies still being
+// recorded (no result on the last play) are left out of every figure.
+type Report struct {
+	Rallies       int             `json:"rallies"`
+	AverageLength float64         `json:"average_length"`
+	LongestRally  int             `json:"longest_rally"`
+	Lengths       []LengthCount   `json:"lengths"`
+	Buckets       []BucketStat    `json:"buckets"`
+	Players       []PlayerStat    `json:"players"`
+	Areas         []AreaStat      `json:"areas"`
+	Walls         []WallStat      `json:"walls"`
+	WinnerPairs   []SequenceCount `json:"winner_pairs"`
+	WinnerTriples []SequenceCount `json:"winner_triples"`
+	ErrorPairs    []SequenceCount `json:"error_pairs"`
+	ErrorTriples  []SequenceCount `json:"error_triples"`
+}
+
+// Build computes the rally report for match from its rallies, naming shots
+// from catalogue.
+func Build(match *matchmodel.MatchWithPlayers, rallies []*analyticsmodel.Rally, catalogue *shotmodel.Catalogue) Report {
+	var complete []*analyticsmodel.Rally
+	for _, rally := range rallies {
+		if rally.Complete() {
+			complete = append(complete, rally)
+		}
+	}
+
+	report := Report{Rallies: len(complete)}
+	if len(complete) == 0 {
+		return report
+	}
+
+	totalShots := 0
+	lengthCounts := map[int]int{}
+	for _, rally := range complete {
+		totalShots += rally.Length()
+		lengthCounts[rally.Length()]++
+		if rally.Length() > report.LongestRally {
+			report.LongestRally = rally.Length()
+		}
+	}
+	report.AverageLength = float64(totalShots) / float64(len(complete))
+
+	for length := 1; length <= report.LongestRally; length++ {
+		report.Lengths = append(report.Lengths, LengthCount{
+			Length:  length,
+			Count:   lengthCounts[length],
+			Percent: percent(lengthCounts[length], len(complete)),
+		})
+	}
+
+	report.Buckets = bucketStats(match, complete)
+	report.Players = playerStats(match, complete)
+	report.Areas = areaStats(complete)
+	report.Walls = wallStats(complete)
+
+	var winners, errors []*analyticsmodel.Rally
+	for _, rally := range complete {
+		if rally.EndedByError() {
+			errors = append(errors, rally)
+		} else {
+			winners = append(winners, rally)
+		}
+	}
+	report.WinnerPairs = endingSequences(winners, 2, catalogue)
+	report.WinnerTriples = endingSequences(winners, 3, catalogue)
+	report.ErrorPairs = endingSequences(errors, 2, catalogue)
+	report.ErrorTriples = endingSequences(errors, 3, catalogue)
+
+	return report
+}
+
+func bucketStats(match *matchmodel.MatchWithPlayers, rallies []*analyticsmodel.Rally) []BucketStat {
+	stats := make([]BucketStat, len(Buckets))
+	decided := make([]int, len(Buckets))
+	for i, bucket := range Buckets {
+		stats[i].Label = bucket.Label
+	}
+
+	for _, rally := range rallies {
+		i := bucketIndex(rally.Length())
+		stats[i].Rallies++
+		switch rally.Winner(match) {
+		case analyticsmodel.Team1:
+			stats[i].Team1Wins++
+			decided[i]++
+		case analyticsmodel.Team2:
+			stats[i].Team2Wins++
+			decided[i]++
+		}
+	}
+
+	for i := range stats {
+		stats[i].Team1WinPercent = percent(stats[i].Team1Wins, decided[i])
+		stats[i].Team2WinPercent = percent(stats[i].Team2Wins, decided[i])
+	}
+	return stats
+}
+
+func playerStats(match *matchmodel.MatchWithPlayers, rallies []*analyticsmodel.Rally) []PlayerStat {
+	players := []playermodel.Player{match.Team1Player1, match.Team1Player2, match.Team2Player1, match.Team2Player2}
+	stats := make([]PlayerStat, 0, len(players))
+
+	for _, player := range players {
+		stat := PlayerStat{
+			Player:  player,
+			Team:    analyticsmodel.TeamOf(match, int64(player.ID)),
+			Buckets: make([]PlayerBucketStat, len(Buckets)),
+		}
+		for i, bucket := range Buckets {
+			stat.Buckets[i].Label = bucket.Label
+		}
+		decided := make([]int, len(Buckets))
+
+		totalShots := 0
+		for _, rally := range rallies {
+			if !hitBy(rally, player.ID) {
+				continue
+			}
+			stat.Rallies++
+			totalShots += rally.Length()
+			if rally.Length() > stat.LongestRally {
+				stat.LongestRally = rally.Length()
+			}
+
+			i := bucketIndex(rally.Length())
+			stat.Buckets[i].Rallies++
+			winner := rally.Winner(match)
+			if winner != analyticsmodel.TeamUnknown {
+				decided[i]++
+			}
+			if winner == stat.Team {
+				stat.Buckets[i].Won++
+			}
+		}
+
+		if stat.Rallies > 0 {
+			stat.AverageLength = float64(totalShots) / float64(stat.Rallies)
+		}
+		for i := range stat.Buckets {
+			stat.Buckets[i].WinPercent = percent(stat.Buckets[i].Won, decided[i])
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// areaStats counts shots by the area they were hit from. Ends are merged
+// since teams change ends during a match.
+func areaStats(rallies []*analyticsmodel.Rally) []AreaStat {
+	areas := court.Areas()
+	stats := make([]AreaStat, len(areas))
+	index := map[court.Area]int{}
+	for i, area := range areas {
+		stats[i] = AreaStat{Area: area, Label: area.Name()}
+		index[area] = i
+	}
+
+	for _, rally := range rallies {
+		for _, play := range rally.Plays {
+			stats[index[play.Zone().Area()]].Shots++
+		}
+		stat := &stats[index[rally.Last().Zone().Area()]]
+		if rally.EndedByError() {
+			stat.Errors++
+		} else {
+			stat.Winners++
+		}
+	}
+
+	for i := range stats {
+		stats[i].WinnerPercent = percent(stats[i].Winners, stats[i].Shots)
+		stats[i].ErrorPercent = percent(stats[i].Errors, stats[i].Shots)
+	}
+	return stats
+}
+
+// wallLabels name the rows of the wall report: each wall, then each way out
+// of the court
+var wallLabels = map[string]string{
+	string(court.WallBackGlass):    "Back glass",
+	string(court.WallSideGlass):    "Side glass",
+	string(court.WallFence):        "Fence",
+	string(playmodel.ExitDoor):     "Out through the door",
+	string(playmodel.ExitOverWall): "Out over the wall",
+}
+
+func wallStats(rallies []*analyticsmodel.Rally) []WallStat {
+	var keys []string
+	for _, wall := range court.Walls {
+		keys = append(keys, string(wall))
+	}
+	for _, exit := range playmodel.ExitCourts {
+		keys = append(keys, string(exit))
+	}
+	stats := make([]WallStat, len(keys))
+	index := map[string]int{}
+	for i, key := range keys {
+		stats[i].Label = wallLabels[key]
+		index[key] = i
+	}
+
+	for _, rally := range rallies {
+		for i, play := range rally.Plays {
+			// A ball that comes off the same wall twice is still one shot
+			var hit []string
+			for _, wall := range court.Walls {
+				if play.HitWall(wall) {
+					hit = append(hit, string(wall))
+				}
+			}
+			if play.ExitCourt.Valid {
+				hit = append(hit, play.ExitCourt.String)
+			}
+
+			for _, key := range hit {
+				stat := &stats[index[key]]
+				stat.Shots++
+				if i == rally.Length()-1 {
+					if !rally.EndedByError() {
+						stat.Winners++
+					}
+					continue
+				}
+				stat.Returns++
+				if i+1 == rally.Length()-1 && rally.EndedByError() {
+					stat.ReturnErrors++
+				}
+			}
+		}
+	}
+
+	for i := range stats {
+		stats[i].ReturnErrorPercent = percent(stats[i].ReturnErrors, stats[i].Returns)
+	}
+	return stats
+}
+
+// endingSequences ranks the last n shots of each rally, most common first.
+// Rallies shorter than n are skipped.
+func endingSequences(rallies []*analyticsmodel.Rally, n int, catalogue *shotmodel.Catalogue) []SequenceCount {
+	counts := map[string]*SequenceCount{}
+	total := 0
+	for _, rally := range rallies {
+		if rally.Length() < n {
+			continue
+		}
+		total++
+
+		shots := make([]string, 0, n)
+		for _, play := range rally.Plays[rally.Length()-n:] {
+			shots = append(shots, analyticsmodel.ShotLabel(play, catalogue))
+		}
+		key := strings.Join(shots, "|")
+		if counts[key] == nil {
+			counts[key] = &SequenceCount{Shots: shots}
+		}
+		counts[key].Count++
+	}
+
+	sequences := make([]SequenceCount, 0, len(counts))
+	for _, sequence := range counts {
+		sequence.Percent = percent(sequence.Count, total)
+		sequences = append(sequences, *sequence)
+	}
+	sort.Slice(sequences, func(i, j int) bool {
+		if sequences[i].Count != sequences[j].Count {
+			return sequences[i].Count > sequences[j].Count
+		}
+		return sequences[i].Label() < sequences[j].Label()
+	})
+
+	if len(sequences) > TopSequences {
+		sequences = sequences[:TopSequences]
+	}
+	return sequences
+}
+
+func bucketIndex(length int) int {
+	for i, bucket := range Buckets {
+		if bucket.contains(length) {
+			return i
+		}
+	}
+	return len(Buckets) - 1
+}
+
+func hitBy(rally *analyticsmodel.Rally, playerID int) bool {
+	for _, play := range rally.Plays {
+		if play.PlayerID.Valid && int(play.PlayerID.Int64) == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+func percent(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(whole)
+}
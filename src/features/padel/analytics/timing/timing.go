@@ -0,0 +1,131 @@
+// Package timing reports how long a match, its points and its rallies took
+// from the start and end times recorded live. Points entered after the match
+// have no timing and are left out rather than guessed.
+package timing
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"fmt"
+	"time"
+)
+
+// Report summarises the timing of a match. Durations are zero when nothing
+// they depend on was recorded.
+type Report struct {
+	Points      int `json:"points"`
+	TimedPoints int `json:"timed_points"`
+
+	// Duration runs from the first timed moment of the match to the last
+	Duration time.Duration `json:"duration"`
+
+	// BetweenPoints averages the gap from the end of a point to the start
+	// of the next one in the same set
+	BetweenPoints time.Duration `json:"between_points"`
+	Gaps          int           `json:"gaps"`
+
+	// Rally averages the length of points with both a start and an end
+	Rally        time.Duration `json:"rally"`
+	LongestRally time.Duration `json:"longest_rally"`
+	Rallies      int           `json:"rallies"`
+}
+
+// Recorded reports whether any timing was captured for the match.
+func (r Report) Recorded() bool {
+	return r.TimedPoints > 0
+}
+
+// Span returns when the rally started and ended. A point without an
+// explicit start or end falls back to its first or last live play, and
+// either side is invalid when neither was recorded.
+func Span(rally *analyticsmodel.Rally) (start, end time.Time, hasStart, hasEnd bool) {
+	var first, last time.Time
+	for _, play := range rally.Plays {
+		if !play.HitAt.Valid {
+			continue
+		}
+		if first.IsZero() {
+			first = play.HitAt.Time
+		}
+		last = play.HitAt.Time
+	}
+
+	switch {
+	case rally.StartedAt.Valid:
+		start, hasStart = rally.StartedAt.Time, true
+	case !first.IsZero():
+		start, hasStart = first, true
+	}
+	switch {
+	case rally.EndedAt.Valid:
+		end, hasEnd = rally.EndedAt.Time, true
+	case !last.IsZero() && last.After(start):
+		end, hasEnd = last, true
+	}
+	return start, end, hasStart, hasEnd
+}
+
+// Build computes the timing report for rallies in match order.
+func Build(rallies []*analyticsmodel.Rally) Report {
+	report := Report{Points: len(rallies)}
+
+	var first, last time.Time
+	var between, rallyTotal time.Duration
+	var previous *analyticsmodel.Rally
+	var previousEnd time.Time
+	var previousHasEnd bool
+
+	for _, rally := range rallies {
+		start, end, hasStart, hasEnd := Span(rally)
+		if hasStart || hasEnd {
+			report.TimedPoints++
+		}
+
+		for _, moment := range []struct {
+			at    time.Time
+			valid bool
+		}{{start, hasStart}, {end, hasEnd}} {
+			if !moment.valid {
+				continue
+			}
+			if first.IsZero() || moment.at.Before(first) {
+				first = moment.at
+			}
+			if moment.at.After(last) {
+				last = moment.at
+			}
+		}
+
+		if hasStart && hasEnd && !end.Before(start) {
+			length := end.Sub(start)
+			rallyTotal += length
+			report.Rallies++
+			if length > report.LongestRally {
+				report.LongestRally = length
+			}
+		}
+
+		if previous != nil && previous.SetNumber == rally.SetNumber && previousHasEnd && hasStart && !start.Before(previousEnd) {
+			between += start.Sub(previousEnd)
+			report.Gaps++
+		}
+		previous, previousEnd, previousHasEnd = rally, end, hasEnd
+	}
+
+	report.Duration = last.Sub(first)
+	if report.Rallies > 0 {
+		report.Rally = rallyTotal / time.Duration(report.Rallies)
+	}
+	if report.Gaps > 0 {
+		report.BetweenPoints = between / time.Duration(report.Gaps)
+	}
+	return report
+}
+
+// Format writes a duration as h:mm:ss or m:ss.
+func Format(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
@@ -0,0 +1,278 @@
+// Package winprob estimates each team's chance of winning a match from the
+// score and how often each team has won its service points so far. Points
+// are treated as independent, each team winning its own service points at
+// its observed rate, and the rest of the match is solved exactly under the
+// scoring package's rules.
+package winprob
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/scoring"
+	"fmt"
+	"strings"
+)
+
+// priorServeWin is the share of service points a server is assumed to win
+// before anything is recorded
+const priorServeWin = 0.6
+
+// priorWeight is how many service points at priorServeWin are blended into
+// each team's record, about what a team serves in a two-set match. Small
+// differences in serve rates decide whole matches, so a lighter prior lets
+// the first few points swing the estimate wildly
+const priorWeight = 60
+
+// Step is the state of the match after a point.
+type Step struct {
+	// PointID is the point just played; zero for the start of the match
+	PointID     int `json:"point_id,omitempty"`
+	SetNumber   int `json:"set_number,omitempty"`
+	GameNumber  int `json:"game_number,omitempty"`
+	PointNumber int `json:"point_number,omitempty"`
+	// Winner won the point just played
+	Winner analyticsmodel.Team `json:"winner,omitempty"`
+	// Score is the score after the point, e.g. "6-4 2-1 30-15"
+	Score string `json:"score"`
+	// Server is the team serving the current game, or opening the tiebreak
+	Server analyticsmodel.Team `json:"server"`
+	// ServeWin is each team's estimated chance of winning a point on its
+	// own serve, indexed by team
+	ServeWin [3]float64 `json:"serve_win"`
+	// Team1 is team 1's chance of winning the match; team 2's is 1 - Team1
+	Team1 float64 `json:"team1"`
+}
+
+// Team2 is team 2's chance of winning the match.
+func (s Step) Team2() float64 {
+	return 1 - s.Team1
+}
+
+// Build replays the completed rallies of a match and returns the win
+// probability at the start and after every point. Rallies whose winner is
+// unknown are skipped. The team serving first is taken from the first
+// recorded serve.
+func Build(match *matchmodel.MatchWithPlayers, rules scoring.Rules, rallies []*analyticsmodel.Rally) []Step {
+	score := scoring.New(rules)
+	first := firstServer(match, rallies)
+
+	var won, played [3]int
+	step := func() Step {
+		s := Step{Score: format(score), Server: serving(score, first)}
+		for _, team := range []analyticsmodel.Team{analyticsmodel.Team1, analyticsmodel.Team2} {
+			s.ServeWin[team] = (float64(won[team]) + priorServeWin*priorWeight) / float64(played[team]+priorWeight)
+		}
+		s.Team1 = Estimate(score, s.Server, s.ServeWin)
+		return s
+	}
+
+	steps := []Step{step()}
+	for _, rally := range rallies {
+		winner := rally.Winner(match)
+		if winner == analyticsmodel.TeamUnknown || score.Winner != analyticsmodel.TeamUnknown {
+			continue
+		}
+
+		server := pointServer(score, first)
+		played[server]++
+		if winner == server {
+			won[server]++
+		}
+		score.Point(winner)
+
+		s := step()
+		s.PointID = rally.PointID
+		s.SetNumber = rally.SetNumber
+		s.GameNumber = rally.GameNumber
+		s.PointNumber = rally.PointNumber
+		s.Winner = winner
+		steps = append(steps, s)
+	}
+	return steps
+}
+
+// Estimate returns team 1's chance of winning from score, with server
+// serving the current game and serveWin each team's chance of winning a
+// point on its own serve, indexed by team.
+func Estimate(score *scoring.Score, server analyticsmodel.Team, serveWin [3]float64) float64 {
+	switch score.Winner {
+	case analyticsmodel.Team1:
+		return 1
+	case analyticsmodel.Team2:
+		return 0
+	}
+
+	m := model{rules: score.Rules, serveWin: serveWin, memo: map[state]float64{}}
+	games := score.Sets[len(score.Sets)-1]
+	sets := score.SetsWon
+	next := server.Opponent()
+
+	// Finish the game in progress, then hand over to the game-by-game model
+	var game float64
+	if score.Tiebreak() {
+		game = m.tiebreak(score.Points[analyticsmodel.Team1], score.Points[analyticsmodel.Team2], server)
+	} else {
+		game = m.game(score.Points[analyticsmodel.Team1], score.Points[analyticsmodel.Team2], server)
+	}
+	return game*m.afterGame(sets, games, analyticsmodel.Team1, next) +
+		(1-game)*m.afterGame(sets, games, analyticsmodel.Team2, next)
+}
+
+// state is a match between games: sets won, games in the current set and
+// the team serving the next game.
+type state struct {
+	sets   [3]int
+	games  [3]int
+	server analyticsmodel.Team
+}
+
+type model struct {
+	rules    scoring.Rules
+	serveWin [3]float64
+	memo     map[state]float64
+}
+
+// point is team 1's chance of winning a point served by server.
+func (m *model) point(server analyticsmodel.Team) float64 {
+	if server == analyticsmodel.Team1 {
+		return m.serveWin[analyticsmodel.Team1]
+	}
+	return 1 - m.serveWin[analyticsmodel.Team2]
+}
+
+// match is team 1's chance of winning from the start of a game.
+func (m *model) match(s state) float64 {
+	switch {
+	case s.sets[analyticsmodel.Team1] == m.rules.SetsToWin:
+		return 1
+	case s.sets[analyticsmodel.Team2] == m.rules.SetsToWin:
+		return 0
+	}
+	if p, ok := m.memo[s]; ok {
+		return p
+	}
+
+	var game float64
+	if s.games[analyticsmodel.Team1] == m.rules.GamesToWin && s.games[analyticsmodel.Team2] == m.rules.GamesToWin {
+		game = m.tiebreak(0, 0, s.server)
+	} else {
+		game = m.game(0, 0, s.server)
+	}
+	next := s.server.Opponent()
+	p := game*m.afterGame(s.sets, s.games, analyticsmodel.Team1, next) +
+		(1-game)*m.afterGame(s.sets, s.games, analyticsmodel.Team2, next)
+	m.memo[s] = p
+	return p
+}
+
+// afterGame is team 1's chance of winning once winner takes the current game.
+func (m *model) afterGame(sets, games [3]int, winner, next analyticsmodel.Team) float64 {
+	games[winner]++
+	won, lost := games[winner], games[winner.Opponent()]
+	if won >= m.rules.GamesToWin && (won-lost >= 2 || won == m.rules.GamesToWin+1) {
+		sets[winner]++
+		games = [3]int{}
+	}
+	return m.match(state{sets: sets, games: games, server: next})
+}
+
+// game is team 1's chance of winning a standard game from a points score
+// of a to b.
+func (m *model) game(a, b int, server analyticsmodel.Team) float64 {
+	p := m.point(server)
+	switch {
+	case a >= 4 && (m.rules.GoldenPoint || a-b >= 2):
+		return 1
+	case b >= 4 && (m.rules.GoldenPoint || b-a >= 2):
+		return 0
+	case !m.rules.GoldenPoint && a >= 3 && a == b:
+		return deuce(p, p)
+	}
+	return p*m.game(a+1, b, server) + (1-p)*m.game(a, b+1, server)
+}
+
+// tiebreak is team 1's chance of winning a tiebreak from a to b. first
+// serves the first point, then serve changes after every two points.
+func (m *model) tiebreak(a, b int, first analyticsmodel.Team) float64 {
+	target := m.rules.TiebreakPoints
+	switch {
+	case a >= target && a-b >= 2:
+		return 1
+	case b >= target && b-a >= 2:
+		return 0
+	case a >= target-1 && a == b:
+		// Level at an even number of points, the next two are served one
+		// by each team
+		return deuce(m.point(analyticsmodel.Team1), m.point(analyticsmodel.Team2))
+	}
+
+	server := first
+	if scoring.TiebreakTurns(a+b)%2 == 1 {
+		server = first.Opponent()
+	}
+	p := m.point(server)
+	return p*m.tiebreak(a+1, b, first) + (1-p)*m.tiebreak(a, b+1, first)
+}
+
+// deuce is the chance of going two points clear from level when the next
+// two points are won with chances p and q, repeating until someone does.
+func deuce(p, q float64) float64 {
+	both := p * q
+	neither := (1 - p) * (1 - q)
+	if both+neither == 0 {
+		return 0.5
+	}
+	return both / (both + neither)
+}
+
+// firstServer returns the team that served the match's first recorded
+// point, team 1 when no serve was recorded.
+func firstServer(match *matchmodel.MatchWithPlayers, rallies []*analyticsmodel.Rally) analyticsmodel.Team {
+	for _, rally := range rallies {
+		if len(rally.Plays) == 0 {
+			continue
+		}
+		serve := rally.Plays[0]
+		if !serve.PlayerID.Valid || !serve.ContactType.Valid || serve.ContactType.String != string(playmodel.ContactServe) {
+			continue
+		}
+		if team := analyticsmodel.TeamOf(match, serve.PlayerID.Int64); team != analyticsmodel.TeamUnknown {
+			return team
+		}
+	}
+	return analyticsmodel.Team1
+}
+
+// serving returns the team serving the current game; serve alternates
+// between the teams every game, a tiebreak counting as one.
+func serving(score *scoring.Score, first analyticsmodel.Team) analyticsmodel.Team {
+	if score.Games%2 == 0 {
+		return first
+	}
+	return first.Opponent()
+}
+
+// pointServer returns the team serving the next point: the team serving the
+// game, or in a tiebreak whichever team's turn it is.
+func pointServer(score *scoring.Score, first analyticsmodel.Team) analyticsmodel.Team {
+	server := serving(score, first)
+	if score.Tiebreak() && scoring.TiebreakTurns(score.Points[analyticsmodel.Team1]+score.Points[analyticsmodel.Team2])%2 == 1 {
+		return server.Opponent()
+	}
+	return server
+}
+
+// format writes the score from team 1's side: the games of each set, then
+// the points of the game in progress.
+func format(score *scoring.Score) string {
+	var parts []string
+	for _, games := range score.Sets {
+		parts = append(parts, fmt.Sprintf("%d-%d", games[analyticsmodel.Team1], games[analyticsmodel.Team2]))
+	}
+	if score.Winner != analyticsmodel.TeamUnknown || score.Points[analyticsmodel.Team1]+score.Points[analyticsmodel.Team2] == 0 {
+		return strings.Join(parts, " ")
+	}
+	parts = append(parts, score.GamePoints())
+	return strings.Join(parts, " ")
+}
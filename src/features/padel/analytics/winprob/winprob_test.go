@@ -0,0 +1,167 @@
+package winprob
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/scoring"
+	"database/sql"
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+func TestEstimateWonMatch(t *testing.T) {
+	serveWin := [3]float64{0, 0.4, 0.8}
+	for _, winner := range []analyticsmodel.Team{analyticsmodel.Team1, analyticsmodel.Team2} {
+		score := scoring.New(scoring.DefaultRules)
+		for score.Winner == analyticsmodel.TeamUnknown {
+			score.Point(winner)
+		}
+
+		want := 1.0
+		if winner == analyticsmodel.Team2 {
+			want = 0
+		}
+		if got := Estimate(score, analyticsmodel.Team1, serveWin); got != want {
+			t.Errorf("won by team %d: Estimate() = %v, want %v", winner, got, want)
+		}
+	}
+}
+
+func TestEstimateEvenAtStart(t *testing.T) {
+	for _, rate := range []float64{0.5, 0.6, 0.75} {
+		for _, server := range []analyticsmodel.Team{analyticsmodel.Team1, analyticsmodel.Team2} {
+			got := Estimate(scoring.New(scoring.DefaultRules), server, [3]float64{0, rate, rate})
+			if math.Abs(got-0.5) > tolerance {
+				t.Errorf("serve rate %v, team %d serving: Estimate() = %v, want 0.5", rate, server, got)
+			}
+		}
+	}
+}
+
+func TestEstimateFavoursBetterServer(t *testing.T) {
+	got := Estimate(scoring.New(scoring.DefaultRules), analyticsmodel.Team1, [3]float64{0, 0.65, 0.55})
+	if got <= 0.5 || got >= 1 {
+		t.Errorf("Estimate() = %v, want between 0.5 and 1", got)
+	}
+}
+
+func TestTiebreakDeuce(t *testing.T) {
+	m := model{rules: scoring.DefaultRules, serveWin: [3]float64{0, 0.7, 0.55}, memo: map[state]float64{}}
+	level := deuce(m.point(analyticsmodel.Team1), m.point(analyticsmodel.Team2))
+
+	// Level at six all or beyond, a tiebreak is decided like deuce, with
+	// one point served by each team
+	for _, points := range []int{6, 7, 10} {
+		for _, first := range []analyticsmodel.Team{analyticsmodel.Team1, analyticsmodel.Team2} {
+			if got := m.tiebreak(points, points, first); math.Abs(got-level) > tolerance {
+				t.Errorf("tiebreak(%d, %d, team %d) = %v, want %v", points, points, first, got, level)
+			}
+		}
+	}
+
+	// At 5-5 ten points have been played, so serve has passed on five
+	// times and team 2 serves; team 1 serves the point after
+	first := analyticsmodel.Team1
+	p2, p1 := m.point(analyticsmodel.Team2), m.point(analyticsmodel.Team1)
+	sixFive := p1 + (1-p1)*level
+	fiveSix := p1 * level
+	want := p2*sixFive + (1-p2)*fiveSix
+	if got := m.tiebreak(5, 5, first); math.Abs(got-want) > tolerance {
+		t.Errorf("tiebreak(5, 5) = %v, want %v", got, want)
+	}
+}
+
+func TestEstimateTiebreak(t *testing.T) {
+	score := scoring.New(scoring.DefaultRules)
+	for range 6 {
+		winGame(score, analyticsmodel.Team1)
+		winGame(score, analyticsmodel.Team2)
+	}
+	if !score.Tiebreak() {
+		t.Fatal("6-6 is not a tiebreak")
+	}
+	for range 6 {
+		score.Point(analyticsmodel.Team1)
+		score.Point(analyticsmodel.Team2)
+	}
+
+	// At 6-6 in the tiebreak, whoever takes it wins the first set
+	serveWin := [3]float64{0, 0.7, 0.55}
+	m := model{rules: score.Rules, serveWin: serveWin, memo: map[state]float64{}}
+	tiebreak := deuce(m.point(analyticsmodel.Team1), m.point(analyticsmodel.Team2))
+	afterSet := func(winner analyticsmodel.Team) float64 {
+		sets := [3]int{}
+		sets[winner] = 1
+		return m.match(state{sets: sets, server: analyticsmodel.Team2})
+	}
+	want := tiebreak*afterSet(analyticsmodel.Team1) + (1-tiebreak)*afterSet(analyticsmodel.Team2)
+
+	if got := Estimate(score, analyticsmodel.Team1, serveWin); math.Abs(got-want) > tolerance {
+		t.Errorf("Estimate() = %v, want %v", got, want)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	match := &matchmodel.MatchWithPlayers{Match: matchmodel.Match{
+		Team1Player1ID: 1, Team1Player2ID: 2, Team2Player1ID: 3, Team2Player2ID: 4,
+	}}
+	rallies := []*analyticsmodel.Rally{
+		rally(1, 1, playmodel.ResultNoReturnWinner),
+		rally(2, 3, playmodel.ResultUnforcedError),
+		// Still being recorded
+		rally(3, 3, ""),
+		rally(4, 4, playmodel.ResultNoReturnWinner),
+		// The last shot has no player, so nobody knows who won
+		rally(5, 0, playmodel.ResultError),
+		rally(6, 2, playmodel.ResultError),
+	}
+
+	steps := Build(match, scoring.DefaultRules, rallies)
+
+	want := []struct {
+		pointID int
+		winner  analyticsmodel.Team
+		score   string
+	}{
+		{0, analyticsmodel.TeamUnknown, "0-0"},
+		{1, analyticsmodel.Team1, "0-0 15-0"},
+		{2, analyticsmodel.Team1, "0-0 30-0"},
+		{4, analyticsmodel.Team2, "0-0 30-15"},
+		{6, analyticsmodel.Team2, "0-0 30-30"},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(steps), len(want))
+	}
+	for i, step := range steps {
+		if step.PointID != want[i].pointID || step.Winner != want[i].winner || step.Score != want[i].score {
+			t.Errorf("step %d = point %d won by %d at %q, want point %d won by %d at %q",
+				i, step.PointID, step.Winner, step.Score, want[i].pointID, want[i].winner, want[i].score)
+		}
+		if step.Team1 <= 0 || step.Team1 >= 1 {
+			t.Errorf("step %d: Team1 = %v, want a chance between 0 and 1", i, step.Team1)
+		}
+	}
+}
+
+// rally returns a one-shot rally whose shot is hit by playerID, none when
+// zero, with result.
+func rally(pointID int, playerID int64, result playmodel.ResultType) *analyticsmodel.Rally {
+	return &analyticsmodel.Rally{
+		PointID:     pointID,
+		SetNumber:   1,
+		GameNumber:  1,
+		PointNumber: pointID,
+		Plays: []*playmodel.Play{{
+			PlayerID:   sql.NullInt64{Int64: playerID, Valid: playerID != 0},
+			ResultType: sql.NullString{String: string(result), Valid: result != ""},
+		}},
+	}
+}
+
+func winGame(score *scoring.Score, team analyticsmodel.Team) {
+	for !score.Point(team).Game {
+	}
+}
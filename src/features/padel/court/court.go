@@ -0,0 +1,193 @@
+// Package court describes the padel court that ball and player positions
+// are recorded on: a 10 m by 20 m court in millimetres, x across from one
+// side wall to the other and y along from one back glass to the other, as
+// drawn by the play editor with y = 0 at the top. It names the zones of the
+// court so positions can be grouped and described.
+package court
+
+import (
+	"math"
+	"slices"
+)
+
+// Court dimensions and lines in millimetres
+const (
+	Width  = 10000
+	Length = 20000
+	// NetY is where the net crosses the court
+	NetY = 10000
+	// CentreX is the centre service line, running from each service line
+	// to the net
+	CentreX = 5000
+	// TopServiceLineY and BottomServiceLineY are 3 m in from the back glass
+	TopServiceLineY    = 3000
+	BottomServiceLineY = 17000
+	// SideGlassLength is how far the side glass runs along each side wall
+	// from the back glass; the rest of the side is fence
+	SideGlassLength = 4000
+	// WallReach is how far from a wall a ball that came off it is played
+	WallReach = 3000
+	// NetZoneDepth is the front half of the service box, where players
+	// volley from
+	NetZoneDepth = 3500
+)
+
+// End is the half of the court on one side of the net, as drawn.
+type End string
+
+const (
+	EndTop    End = "top"
+	EndBottom End = "bottom"
+)
+
+// Depth is how far from the net a position is within its end.
+type Depth string
+
+const (
+	// DepthNet is within NetZoneDepth of the net
+	DepthNet Depth = "net"
+	// DepthMid is between the net zone and the service line
+	DepthMid Depth = "midcourt"
+	// DepthBack is behind the service line
+	DepthBack Depth = "backcourt"
+)
+
+var Depths = []Depth{DepthNet, DepthMid, DepthBack}
+
+// Side is the half of an end to the left or right of the centre line, seen
+// by a player at that end facing the net.
+type Side string
+
+const (
+	SideLeft  Side = "left"
+	SideRight Side = "right"
+)
+
+var Sides = []Side{SideLeft, SideRight}
+
+// Wall is what encloses the court at a point on its edge.
+type Wall string
+
+const (
+	WallBackGlass Wall = "back_glass"
+	WallSideGlass Wall = "side_glass"
+	WallFence     Wall = "fence"
+)
+
+var Walls = []Wall{WallBackGlass, WallSideGlass, WallFence}
+
+func (w Wall) Valid() bool {
+	return slices.Contains(Walls, w)
+}
+
+// Zone is a named part of the court.
+type Zone struct {
+	End   End   `json:"end"`
+	Depth Depth `json:"depth"`
+	Side  Side  `json:"side"`
+}
+
+// Name describes the zone, e.g. "top net left".
+func (z Zone) Name() string {
+	return string(z.End) + " " + z.Area().Name()
+}
+
+// Area is the zone without its end. Teams change ends during a match, so
+// stats group positions by area.
+func (z Zone) Area() Area {
+	return Area{Depth: z.Depth, Side: z.Side}
+}
+
+// Area is a zone of either end.
+type Area struct {
+	Depth Depth `json:"depth"`
+	Side  Side  `json:"side"`
+}
+
+// Name describes the area, e.g. "backcourt right".
+func (a Area) Name() string {
+	return string(a.Depth) + " " + string(a.Side)
+}
+
+// Areas lists every area, net first and left before right.
+func Areas() []Area {
+	areas := make([]Area, 0, len(Depths)*len(Sides))
+	for _, depth := range Depths {
+		for _, side := range Sides {
+			areas = append(areas, Area{Depth: depth, Side: side})
+		}
+	}
+	return areas
+}
+
+// Classify returns the zone containing (x, y). A position on the net counts
+// for the bottom end and one on the centre line for the right side.
+func Classify(x, y int) Zone {
+	zone := Zone{End: EndOf(y), Depth: DepthOf(y)}
+
+	// A player at the top end faces down the court, so their left is the
+	// high x side; at the bottom end it is the low x side
+	left := x < CentreX
+	if zone.End == EndTop {
+		left = x > CentreX
+	}
+	zone.Side = SideRight
+	if left {
+		zone.Side = SideLeft
+	}
+	return zone
+}
+
+// EndOf returns the end containing y.
+func EndOf(y int) End {
+	if y < NetY {
+		return EndTop
+	}
+	return EndBottom
+}
+
+// DepthOf returns how far from the net y is within its end.
+func DepthOf(y int) Depth {
+	switch {
+	case FromNet(y) < NetZoneDepth:
+		return DepthNet
+	case BehindServiceLine(y):
+		return DepthBack
+	}
+	return DepthMid
+}
+
+// FromNet is the distance from the net to y.
+func FromNet(y int) int {
+	if y < NetY {
+		return NetY - y
+	}
+	return y - NetY
+}
+
+// BehindServiceLine reports whether y is between its end's service line and
+// back glass. The line itself is in the service box.
+func BehindServiceLine(y int) bool {
+	return y < TopServiceLineY || y > BottomServiceLineY
+}
+
+// WallDistance returns the distance from (x, y) to the nearest stretch of
+// wall: the back glass across each end, the side glass for SideGlassLength
+// along each side from there, and fence in between.
+func WallDistance(x, y int, wall Wall) int {
+	toSide := min(x, Width-x)
+	toBack := min(y, Length-y)
+	switch wall {
+	case WallBackGlass:
+		return toBack
+	case WallSideGlass:
+		return distance(toSide, max(0, toBack-SideGlassLength))
+	case WallFence:
+		return distance(toSide, max(0, SideGlassLength-toBack))
+	}
+	return 0
+}
+
+func distance(dx, dy int) int {
+	return int(math.Round(math.Hypot(float64(dx), float64(dy))))
+}
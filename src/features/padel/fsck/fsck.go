@@ -0,0 +1,221 @@
+// Package fsck finds and repairs inconsistencies in recorded matches that
+// the schema does not rule out: rows that lost their parent, numbering that
+// is not 1..n, plays after the play that ended the point and plays by
+// players who are not in the match.
+package fsck
+
+import (
+	"ct-padel-s/src/infrastructure/database"
+	"fmt"
+)
+
+type Kind string
+
+const (
+	KindOrphan        Kind = "orphan"
+	KindForeignPlayer Kind = "foreign_player"
+	KindAfterResult   Kind = "after_result"
+	KindNumbering     Kind = "numbering"
+)
+
+// Problem is one inconsistent row.
+type Problem struct {
+	Kind   Kind
+	Table  string
+	ID     int
+	Detail string
+	// Repair describes what Repair does about the problem
+	Repair string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s %s %d: %s (repair: %s)", p.Kind, p.Table, p.ID, p.Detail, p.Repair)
+}
+
+// check finds one kind of problem and fixes every instance of it in one
+// statement. query returns the id and a description of each bad row.
+type check struct {
+	kind   Kind
+	table  string
+	query  string
+	repair string
+	action string
+}
+
+// checks run in order, so later checks see the rows earlier repairs left:
+// orphans and stray plays are removed before the numbering is checked.
+var checks = []check{
+	orphans("sets", "match_id"),
+	orphans("games", "set_id"),
+	orphans("points", "game_id"),
+	orphans("plays", "point_id"),
+	orphans("player_positions", "play_id"),
+	{
+		kind:  KindForeignPlayer,
+		table: "plays",
+		query: `SELECT pl.id, 'player ' || pl.player_id || ' is not in match ' || m.id ` + playsOfMatches + `
+				WHERE pl.player_id IS NOT NULL
+				  AND pl.player_id NOT IN (m.team1_player1_id, m.team1_player2_id, m.team2_player1_id, m.team2_player2_id)
+				ORDER BY pl.id`,
+		repair: `UPDATE plays SET player_id = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+				 WHERE id IN (
+					 SELECT pl.id ` + playsOfMatches + `
+					 WHERE pl.player_id IS NOT NULL
+					   AND pl.player_id NOT IN (m.team1_player1_id, m.team1_player2_id, m.team2_player1_id, m.team2_player2_id)
+				 )`,
+		action: "clear the player",
+	},
+	{
+		kind:  KindForeignPlayer,
+		table: "player_positions",
+		query: `SELECT pp.id, 'player ' || pp.player_id || ' is not in match ' || m.id
+				FROM player_positions pp
+				JOIN plays pl ON pl.id = pp.play_id
+				JOIN points p ON p.id = pl.point_id
+				JOIN games g ON g.id = p.game_id
+				JOIN sets s ON s.id = g.set_id
+				JOIN matches m ON m.id = s.match_id
+				WHERE pp.player_id NOT IN (m.team1_player1_id, m.team1_player2_id, m.team2_player1_id, m.team2_player2_id)
+				ORDER BY pp.id`,
+		repair: `DELETE FROM player_positions WHERE id IN (
+					 SELECT pp.id
+					 FROM player_positions pp
+					 JOIN plays pl ON pl.id = pp.play_id
+					 JOIN points p ON p.id = pl.point_id
+					 JOIN games g ON g.id = p.game_id
+					 JOIN sets s ON s.id = g.set_id
+					 JOIN matches m ON m.id = s.match_id
+					 WHERE pp.player_id NOT IN (m.team1_player1_id, m.team1_player2_id, m.team2_player1_id, m.team2_player2_id)
+				 )`,
+		action: "delete the position",
+	},
+	{
+		kind:  KindAfterResult,
+		table: "plays",
+		query: `SELECT pl.id, 'play ' || pl.play_number || ' of point ' || pl.point_id || ' follows play ' || MIN(t.play_number) || ', which ended the point'
+				FROM plays pl
+				JOIN plays t ON t.point_id = pl.point_id AND t.play_number < pl.play_number AND t.result_type IS NOT NULL
+				GROUP BY pl.id, pl.play_number, pl.point_id
+				ORDER BY pl.id`,
+		repair: `DELETE FROM plays pl WHERE EXISTS (
+					 SELECT 1 FROM plays t
+					 WHERE t.point_id = pl.point_id AND t.play_number < pl.play_number AND t.result_type IS NOT NULL
+				 )`,
+		action: "delete the play",
+	},
+	numbering("sets", "match_id", "set_number", false),
+	numbering("games", "set_id", "game_number", true),
+	numbering("points", "game_id", "point_number", true),
+	numbering("plays", "point_id", "play_number", true),
+}
+
+// playsOfMatches joins each play to its match.
+const playsOfMatches = `FROM plays pl
+				JOIN points p ON p.id = pl.point_id
+				JOIN games g ON g.id = p.game_id
+				JOIN sets s ON s.id = g.set_id
+				JOIN matches m ON m.id = s.match_id`
+
+// orphans finds rows of table whose parent column is NULL. Foreign keys
+// keep a set parent pointing at a real row, but the columns are nullable.
+func orphans(table, parent string) check {
+	return check{
+		kind:   KindOrphan,
+		table:  table,
+		query:  fmt.Sprintf(`SELECT id, '%[2]s is NULL' FROM %[1]s WHERE %[2]s IS NULL ORDER BY id`, table, parent),
+		repair: fmt.Sprintf(`DELETE FROM %[1]s WHERE %[2]s IS NULL`, table, parent),
+		action: "delete the row",
+	}
+}
+
+// numbering finds rows of table whose number is not its position among
+// its siblings, which covers both gaps and duplicates. Ties keep creation
+// order. Renumbering bumps the version of versioned tables so open editors
+// notice.
+func numbering(table, parent, column string, versioned bool) check {
+	expected := fmt.Sprintf(`SELECT id, %[2]s AS parent_id, %[3]s AS number,
+								ROW_NUMBER() OVER (PARTITION BY %[2]s ORDER BY %[3]s, id) AS expected
+							 FROM %[1]s WHERE %[2]s IS NOT NULL`, table, parent, column)
+	bump := ""
+	if versioned {
+		bump = "version = version + 1, "
+	}
+	return check{
+		kind:  KindNumbering,
+		table: table,
+		query: fmt.Sprintf(`SELECT id, '%[2]s is ' || number || ' but should be ' || expected || ' in %[1]s ' || parent_id
+						   FROM (%[3]s) numbered WHERE number <> expected ORDER BY parent_id, expected`, parent, column, expected),
+		repair: fmt.Sprintf(`UPDATE %[1]s t SET %[2]s = numbered.expected, %[4]supdated_at = CURRENT_TIMESTAMP
+							FROM (%[3]s) numbered
+							WHERE t.id = numbered.id AND numbered.number <> numbered.expected`, table, column, expected, bump),
+		action: "renumber",
+	}
+}
+
+// Check returns every problem in the database without changing anything.
+func Check(db database.Querier) ([]Problem, error) {
+	var problems []Problem
+	for _, c := range checks {
+		found, err := c.find(db)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, found...)
+	}
+	return problems, nil
+}
+
+// Repair fixes every problem in one transaction and returns what it fixed.
+// Each check runs after the repairs before it, so a play removed for
+// following the end of its point is not reported as misnumbered as well.
+func Repair(db *database.DB) ([]Problem, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Renumbering shifts siblings onto each other's numbers, so only check
+	// uniqueness once the whole transaction is done
+	if _, err := tx.Exec(`SET CONSTRAINTS ALL DEFERRED`); err != nil {
+		return nil, err
+	}
+
+	var repaired []Problem
+	for _, c := range checks {
+		found, err := c.find(tx)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			continue
+		}
+		if _, err := tx.Exec(c.repair); err != nil {
+			return nil, fmt.Errorf("repair %s %s: %w", c.kind, c.table, err)
+		}
+		repaired = append(repaired, found...)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return repaired, nil
+}
+
+func (c check) find(db database.Querier) ([]Problem, error) {
+	rows, err := db.Query(c.query)
+	if err != nil {
+		return nil, fmt.Errorf("check %s %s: %w", c.kind, c.table, err)
+	}
+	defer rows.Close()
+
+	var problems []Problem
+	for rows.Next() {
+		problem := Problem{Kind: c.kind, Table: c.table, Repair: c.action}
+		if err := rows.Scan(&problem.ID, &problem.Detail); err != nil {
+			return nil, err
+		}
+		problems = append(problems, problem)
+	}
+	return problems, rows.Err()
+}
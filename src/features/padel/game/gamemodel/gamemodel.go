@@ -6,6 +6,7 @@ type Game struct {
 	ID         int       `json:"id" db:"id"`
 	SetID      int       `json:"set_id" db:"set_id"`
 	GameNumber int       `json:"game_number" db:"game_number"`
+	Version    int       `json:"version" db:"version"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
 }
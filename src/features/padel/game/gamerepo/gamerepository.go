@@ -3,16 +3,35 @@ package gamerepo
 import (
 	"ct-padel-s/src/features/padel/game/gamemodel"
 	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
 )
 
+// CreateGame inserts game as the next game of its set, assigning game.GameNumber.
+// The set row is locked for the duration so concurrent creates are numbered
+// one after the other instead of colliding on UNIQUE(set_id, game_number).
 func CreateGame(db *database.DB, game *gamemodel.Game) error {
-	query := `INSERT INTO games (set_id, game_number) VALUES ($1, $2) RETURNING id, created_at, updated_at`
-	err := db.QueryRow(query, game.SetID, game.GameNumber).Scan(&game.ID, &game.CreatedAt, &game.UpdatedAt)
-	return err
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM sets WHERE id = $1 FOR UPDATE`, game.SetID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO games (set_id, game_number)
+			  SELECT $1, COALESCE(MAX(game_number), 0) + 1 FROM games WHERE set_id = $1
+			  RETURNING id, game_number, version, created_at, updated_at`
+	if err := tx.QueryRow(query, game.SetID).Scan(&game.ID, &game.GameNumber, &game.Version, &game.CreatedAt, &game.UpdatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func GetGamesBySet(db *database.DB, setID int) ([]*gamemodel.Game, error) {
-	query := `SELECT id, set_id, game_number, created_at, updated_at FROM games WHERE set_id = $1 ORDER BY game_number`
+	query := `SELECT id, set_id, game_number, version, created_at, updated_at FROM games WHERE set_id = $1 ORDER BY game_number`
 	rows, err := db.Query(query, setID)
 	if err != nil {
 		return nil, err
@@ -22,7 +41,7 @@ func GetGamesBySet(db *database.DB, setID int) ([]*gamemodel.Game, error) {
 	var games []*gamemodel.Game
 	for rows.Next() {
 		var game gamemodel.Game
-		err := rows.Scan(&game.ID, &game.SetID, &game.GameNumber, &game.CreatedAt, &game.UpdatedAt)
+		err := rows.Scan(&game.ID, &game.SetID, &game.GameNumber, &game.Version, &game.CreatedAt, &game.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -32,35 +51,55 @@ func GetGamesBySet(db *database.DB, setID int) ([]*gamemodel.Game, error) {
 }
 
 func GetGame(db *database.DB, gameID int) (*gamemodel.Game, error) {
-	query := `SELECT id, set_id, game_number, created_at, updated_at FROM games WHERE id = $1`
+	query := `SELECT id, set_id, game_number, version, created_at, updated_at FROM games WHERE id = $1`
 	var game gamemodel.Game
-	err := db.QueryRow(query, gameID).Scan(&game.ID, &game.SetID, &game.GameNumber, &game.CreatedAt, &game.UpdatedAt)
+	err := db.QueryRow(query, gameID).Scan(&game.ID, &game.SetID, &game.GameNumber, &game.Version, &game.CreatedAt, &game.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	return &game, err
 }
 
-func DeleteGame(db *database.DB, gameID int) error {
-	// First get the game to know its set_id and game_number
-	game, err := GetGame(db, gameID)
+// DeleteGame deletes the game if it is still at expectedVersion and renumbers the
+// remaining games. It returns database.ErrConflict when the game was changed in
+// the meantime.
+func DeleteGame(db *database.DB, gameID int, expectedVersion int) error {
+	// Begin transaction
+	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// Begin transaction
-	tx, err := db.Begin()
+	// Lock the set so deletes and creates of siblings do not interleave
+	var setID int
+	err = tx.QueryRow(`SELECT set_id FROM games WHERE id = $1`, gameID).Scan(&setID)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	if _, err := tx.Exec(`SELECT id FROM sets WHERE id = $1 FOR UPDATE`, setID); err != nil {
+		return err
+	}
+
+	// Renumbering shifts siblings onto each other's numbers, so only check
+	// uniqueness once the whole transaction is done
+	if _, err := tx.Exec(`SET CONSTRAINTS ALL DEFERRED`); err != nil {
+		return err
+	}
 
 	// Delete the game
-	_, err = tx.Exec(`DELETE FROM games WHERE id = $1`, gameID)
+	var gameNumber int
+	err = tx.QueryRow(`DELETE FROM games WHERE id = $1 AND version = $2 RETURNING game_number`, gameID, expectedVersion).Scan(&gameNumber)
+	if err == sql.ErrNoRows {
+		return database.ErrConflict
+	}
 	if err != nil {
 		return err
 	}
 
 	// Update game numbers for games with higher numbers in the same set
-	_, err = tx.Exec(`UPDATE games SET game_number = game_number - 1 WHERE set_id = $1 AND game_number > $2`,
-		game.SetID, game.GameNumber)
+	_, err = tx.Exec(`UPDATE games SET game_number = game_number - 1, version = version + 1 WHERE set_id = $1 AND game_number > $2`,
+		setID, gameNumber)
 	if err != nil {
 		return err
 	}
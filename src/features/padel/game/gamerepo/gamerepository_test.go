@@ -0,0 +1,31 @@
+package gamerepo
+
+import (
+	"ct-padel-s/src/features/padel/game/gamemodel"
+	"ct-padel-s/src/infrastructure/database/databasetest"
+	"testing"
+)
+
+const concurrentCreates = 20
+
+func TestCreateGameConcurrently(t *testing.T) {
+	db := databasetest.Open(t)
+	setID := databasetest.Set(t, db)
+
+	databasetest.Concurrently(t, concurrentCreates, func() error {
+		return CreateGame(db, &gamemodel.Game{SetID: setID})
+	})
+
+	games, err := GetGamesBySet(db, setID)
+	if err != nil {
+		t.Fatalf("GetGamesBySet: %v", err)
+	}
+	if len(games) != concurrentCreates {
+		t.Fatalf("got %d games, want %d", len(games), concurrentCreates)
+	}
+	numbers := make([]int, len(games))
+	for i, game := range games {
+		numbers[i] = game.GameNumber
+	}
+	databasetest.Numbered(t, numbers)
+}
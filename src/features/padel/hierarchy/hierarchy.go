@@ -0,0 +1,162 @@
+package hierarchy
+
+import (
+	"context"
+	"ct-padel-s/src/features/padel/game/gamemodel"
+	"ct-padel-s/src/features/padel/game/gamerepo"
+	"ct-padel-s/src/features/padel/game/gameshared"
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/match/matchshared"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/play/playrepo"
+	"ct-padel-s/src/features/padel/play/playshared"
+	"ct-padel-s/src/features/padel/point/pointmodel"
+	"ct-padel-s/src/features/padel/point/pointrepo"
+	"ct-padel-s/src/features/padel/point/pointshared"
+	"ct-padel-s/src/features/padel/set/setmodel"
+	"ct-padel-s/src/features/padel/set/setrepo"
+	"ct-padel-s/src/features/padel/set/setshared"
+	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/shared/httperror"
+	"net/http"
+)
+
+// Path holds the entities addressed by a /matches/{matchID}/sets/{setID}/...
+// URL. Entities deeper than the route reaches are left nil.
+type Path struct {
+	Match *matchmodel.MatchWithPlayers
+	Set   *setmodel.Set
+	Game  *gamemodel.Game
+	Point *pointmodel.Point
+	Play  *playmodel.Play
+}
+
+type contextKey struct{}
+
+// Load resolves every ID in the route pattern, checks that each entity
+// belongs to its parent and stores the result in the request context. Unknown
+// IDs and IDs that belong to another parent both answer 404, so a URL can
+// never reach data outside the match it names.
+func Load(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromRequest(r)
+		db := database.GetDB()
+		path := &Path{}
+
+		matchID := matchshared.GetMatchID(w, r)
+		if matchID == 0 {
+			httperror.Write(w, r, http.StatusBadRequest, "Invalid match ID")
+			return
+		}
+
+		match, err := matchrepo.GetMatchWithPlayers(db, matchID)
+		if err != nil {
+			logger.Error("Failed to get match", "error", err, "matchID", matchID)
+			httperror.Write(w, r, http.StatusInternalServerError, "Failed to get match")
+			return
+		}
+		if match == nil {
+			httperror.NotFound(w, r, "Match not found")
+			return
+		}
+		path.Match = match
+
+		if r.PathValue("setID") != "" {
+			setID := setshared.GetSetID(w, r)
+			if setID == 0 {
+				httperror.Write(w, r, http.StatusBadRequest, "Invalid set ID")
+				return
+			}
+
+			set, err := setrepo.GetSet(db, setID)
+			if err != nil {
+				logger.Error("Failed to get set", "error", err, "setID", setID)
+				httperror.Write(w, r, http.StatusInternalServerError, "Failed to get set")
+				return
+			}
+			if set == nil || set.MatchID != match.ID {
+				logger.Warn("Set not found in match", "setID", setID, "matchID", match.ID)
+				httperror.NotFound(w, r, "Set not found")
+				return
+			}
+			path.Set = set
+		}
+
+		if path.Set != nil && r.PathValue("gameID") != "" {
+			gameID := gameshared.GetGameID(w, r)
+			if gameID == 0 {
+				httperror.Write(w, r, http.StatusBadRequest, "Invalid game ID")
+				return
+			}
+
+			game, err := gamerepo.GetGame(db, gameID)
+			if err != nil {
+				logger.Error("Failed to get game", "error", err, "gameID", gameID)
+				httperror.Write(w, r, http.StatusInternalServerError, "Failed to get game")
+				return
+			}
+			if game == nil || game.SetID != path.Set.ID {
+				logger.Warn("Game not found in set", "gameID", gameID, "setID", path.Set.ID)
+				httperror.NotFound(w, r, "Game not found")
+				return
+			}
+			path.Game = game
+		}
+
+		if path.Game != nil && r.PathValue("pointID") != "" {
+			pointID := pointshared.GetPointID(w, r)
+			if pointID == 0 {
+				httperror.Write(w, r, http.StatusBadRequest, "Invalid point ID")
+				return
+			}
+
+			point, err := pointrepo.GetPoint(db, pointID)
+			if err != nil {
+				logger.Error("Failed to get point", "error", err, "pointID", pointID)
+				httperror.Write(w, r, http.StatusInternalServerError, "Failed to get point")
+				return
+			}
+			if point == nil || point.GameID != path.Game.ID {
+				logger.Warn("Point not found in game", "pointID", pointID, "gameID", path.Game.ID)
+				httperror.NotFound(w, r, "Point not found")
+				return
+			}
+			path.Point = point
+		}
+
+		if path.Point != nil && r.PathValue("playID") != "" {
+			playID := playshared.GetPlayID(w, r)
+			if playID == 0 {
+				httperror.Write(w, r, http.StatusBadRequest, "Invalid play ID")
+				return
+			}
+
+			play, err := playrepo.GetPlay(db, playID)
+			if err != nil {
+				logger.Error("Failed to get play", "error", err, "playID", playID)
+				httperror.Write(w, r, http.StatusInternalServerError, "Failed to get play")
+				return
+			}
+			if play == nil || play.PointID != path.Point.ID {
+				logger.Warn("Play not found in point", "playID", playID, "pointID", path.Point.ID)
+				httperror.NotFound(w, r, "Play not found")
+				return
+			}
+			path.Play = play
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, path)))
+	}
+}
+
+// FromRequest returns the entities resolved by Load for r. It panics if the
+// route was not wrapped with Load, which is a programming error.
+func FromRequest(r *http.Request) *Path {
+	path, ok := r.Context().Value(contextKey{}).(*Path)
+	if !ok {
+		panic("hierarchy: route not wrapped with hierarchy.Load")
+	}
+	return path
+}
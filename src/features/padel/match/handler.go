@@ -1,56 +1,67 @@
 package match
 
 import (
+	"ct-padel-s/src/features/padel/analytics/analyticsrepo"
+	"ct-padel-s/src/features/padel/analytics/winprob"
+	"ct-padel-s/src/features/padel/hierarchy"
 	"ct-padel-s/src/features/padel/match/matchmodel"
 	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/match/matchservice"
 	"ct-padel-s/src/features/padel/match/matchviews"
 	"ct-padel-s/src/features/padel/player/playermodel"
 	"ct-padel-s/src/features/padel/player/playerrepo"
+	"ct-padel-s/src/features/padel/rating/ratingservice"
+	"ct-padel-s/src/features/padel/scoring"
+	"ct-padel-s/src/features/padel/set/setmodel"
 	"ct-padel-s/src/features/padel/set/setrepo"
 	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/infrastructure/metrics"
 	"ct-padel-s/src/shared/components/footer"
 	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
 	"ct-padel-s/src/shared/templates"
+	"encoding/json"
 	"io"
-	"log/slog"
 	"net/http"
 	"strconv"
 )
 
 func GetAll(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
 
 	matches, err := matchrepo.GetAllMatches(db)
 	if err != nil {
-		slog.Error("Failed to get matches", "error", err)
-		http.Error(w, "Failed to get matches", http.StatusInternalServerError)
+		logger.Error("Failed to get matches", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get matches")
 		return
 	}
 
 	breadcrumb, err := matchviews.RenderGetAllBreadcrumb()
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
 	// Load shared components
 	headerHTML, err := header.Render(header.Data{Title: "Matches - Padel Tracker", Breadcrumb: breadcrumb})
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
 	footerHTML, err := footer.Render(footer.Data{})
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
 	// Load feature content and render with data
 	contentHTML, err := matchviews.RenderGetAll(matches)
 	if err != nil {
-		http.Error(w, "Template error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
 		return
 	}
 
@@ -63,15 +74,16 @@ func GetAll(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 	io.WriteString(w, string(page))
 }
 
 func Create(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
 	db := database.GetDB()
 	p1 := playermodel.Player{Name: "P1"}
 	p2 := playermodel.Player{Name: "P2"}
@@ -79,23 +91,23 @@ func Create(w http.ResponseWriter, r *http.Request) {
 	p4 := playermodel.Player{Name: "P4"}
 
 	if err := playerrepo.CreatePlayer(db, &p1); err != nil {
-		slog.Error("Failed to create player", "error", err)
-		http.Error(w, "Failed to create player", http.StatusInternalServerError)
+		logger.Error("Failed to create player", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create player")
 		return
 	}
 	if err := playerrepo.CreatePlayer(db, &p2); err != nil {
-		slog.Error("Failed to create player", "error", err)
-		http.Error(w, "Failed to create player", http.StatusInternalServerError)
+		logger.Error("Failed to create player", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create player")
 		return
 	}
 	if err := playerrepo.CreatePlayer(db, &p3); err != nil {
-		slog.Error("Failed to create player", "error", err)
-		http.Error(w, "Failed to create player", http.StatusInternalServerError)
+		logger.Error("Failed to create player", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create player")
 		return
 	}
 	if err := playerrepo.CreatePlayer(db, &p4); err != nil {
-		slog.Error("Failed to create player", "error", err)
-		http.Error(w, "Failed to create player", http.StatusInternalServerError)
+		logger.Error("Failed to create player", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create player")
 		return
 	}
 
@@ -108,10 +120,11 @@ func Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := matchrepo.CreateMatch(db, &match); err != nil {
-		slog.Error("Failed to create match", "error", err)
-		http.Error(w, "Failed to create match", http.StatusInternalServerError)
+		logger.Error("Failed to create match", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create match")
 		return
 	}
+	metrics.MatchesCreated.Inc()
 
 	// Set HTMX redirect header and return created status
 	w.Header().Set("HX-Redirect", "/matches/"+strconv.Itoa(match.ID))
@@ -119,34 +132,35 @@ func Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func Get(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
 
-	matchID := r.PathValue("matchID")
-	id, err := strconv.Atoi(matchID)
-	if err != nil {
-		slog.Error("Invalid match ID", "error", err)
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
-	}
+	match := hierarchy.FromRequest(r).Match
 
-	match, err := matchrepo.GetMatchWithPlayers(db, id)
+	sets, err := setrepo.GetSetsByMatch(db, match.ID)
 	if err != nil {
-		slog.Error("Failed to get match", "error", err, "id", id)
-		http.Error(w, "Failed to get match", http.StatusInternalServerError)
+		logger.Error("Failed to get sets", "error", err, "matchID", match.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get sets")
 		return
 	}
 
-	if match == nil {
-		slog.Error("Match not found", "id", id)
-		http.Error(w, "Match not found", http.StatusNotFound)
+	rallies, err := analyticsrepo.GetRalliesByMatch(db, match.ID)
+	if err != nil {
+		logger.Error("Failed to get rallies", "error", err, "matchID", match.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get rallies")
 		return
 	}
-
-	sets, err := setrepo.GetSetsByMatch(db, match.ID)
-	if err != nil {
-		slog.Error("Failed to get sets", "error", err, "matchID", match.ID)
-		http.Error(w, "Failed to get sets", http.StatusInternalServerError)
+	steps := winprob.Build(match, scoring.DefaultRules, rallies)
+
+	if httperror.WantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			*matchmodel.MatchWithPlayers
+			Sets           []*setmodel.Set `json:"sets"`
+			WinProbability []winprob.Step  `json:"win_probability"`
+		}{match, sets, steps})
+		logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 		return
 	}
 
@@ -155,26 +169,26 @@ func Get(w http.ResponseWriter, r *http.Request) {
 
 	breadcrumb, err := matchviews.RenderGetBreadcrumb(match)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
 	headerHTML, err := header.Render(header.Data{Title: title + " - Padel Tracker", Breadcrumb: breadcrumb})
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
 	footerHTML, err := footer.Render(footer.Data{})
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
 	// Load feature content and render with data
-	contentHTML, err := matchviews.RenderGet(match, sets)
+	contentHTML, err := matchviews.RenderGet(match, sets, steps)
 	if err != nil {
-		http.Error(w, "Template error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
 		return
 	}
 
@@ -187,48 +201,88 @@ func Get(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 	io.WriteString(w, string(page))
 }
 
 func Delete(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
 
-	matchID := r.PathValue("matchID")
-	id, err := strconv.Atoi(matchID)
+	match := hierarchy.FromRequest(r).Match
+	id := match.ID
 
-	if err != nil {
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+	// Delete the match
+	if err := matchrepo.DeleteMatch(db, id); err != nil {
+		logger.Error("Failed to delete match", "error", err, "id", id)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to delete match")
 		return
 	}
 
-	// Check if match exists
-	match, err := matchrepo.GetMatch(db, id)
-	if err != nil {
-		slog.Error("Failed to get match", "error", err, "id", id)
-		http.Error(w, "Failed to get match", http.StatusInternalServerError)
+	// A rated match shaped every later rating, so replay the rest
+	if match.CompletedAt.Valid {
+		if _, err := ratingservice.Recompute(db); err != nil {
+			logger.Error("Failed to recompute ratings", "error", err, "id", id)
+			httperror.Write(w, r, http.StatusInternalServerError, "Match deleted but ratings could not be recomputed")
+			return
+		}
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/matches")
+	w.WriteHeader(http.StatusOK)
+}
+
+// Complete marks the match as finished, rates its players and records the
+// result of its tournament fixture.
+func Complete(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	id := hierarchy.FromRequest(r).Match.ID
+
+	if err := matchservice.Complete(db, id); err != nil {
+		logger.Error("Failed to complete match", "error", err, "id", id)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to complete match")
 		return
 	}
 
-	if match == nil {
-		http.Error(w, "Match not found", http.StatusNotFound)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/matches/"+strconv.Itoa(id))
+	w.WriteHeader(http.StatusOK)
+}
+
+// SetRallyChecks sets whether edits that break the rally rules are saved
+// with a warning or refused.
+func SetRallyChecks(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	id := hierarchy.FromRequest(r).Match.ID
+
+	mode := matchmodel.RallyChecks(r.FormValue("rally_checks"))
+	if !mode.Valid() {
+		httperror.Write(w, r, http.StatusBadRequest, "Rally checks must be warn or enforce")
 		return
 	}
 
-	// Delete the match
-	if err := matchrepo.DeleteMatch(db, id); err != nil {
-		slog.Error("Failed to delete match", "error", err, "id", id)
-		http.Error(w, "Failed to delete match", http.StatusInternalServerError)
+	if err := matchrepo.SetRallyChecks(db, id, mode); err != nil {
+		logger.Error("Failed to set rally checks", "error", err, "id", id)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to save rally checks")
 		return
 	}
 
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 
-	w.Header().Set("HX-Redirect", "/matches")
+	w.Header().Set("HX-Redirect", "/matches/"+strconv.Itoa(id))
 	w.WriteHeader(http.StatusOK)
 }
@@ -2,19 +2,37 @@ package matchmodel
 
 import (
 	"ct-padel-s/src/features/padel/player/playermodel"
+	"database/sql"
 	"fmt"
 	"time"
 )
 
 type Match struct {
-	ID             int       `json:"id" db:"id"`
-	Team1Player1ID int       `json:"team1_player1_id" db:"team1_player1_id"`
-	Team1Player2ID int       `json:"team1_player2_id" db:"team1_player2_id"`
-	Team2Player1ID int       `json:"team2_player1_id" db:"team2_player1_id"`
-	Team2Player2ID int       `json:"team2_player2_id" db:"team2_player2_id"`
-	MatchDate      time.Time `json:"match_date" db:"match_date"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ID             int            `json:"id" db:"id"`
+	Team1Player1ID int            `json:"team1_player1_id" db:"team1_player1_id"`
+	Team1Player2ID int            `json:"team1_player2_id" db:"team1_player2_id"`
+	Team2Player1ID int            `json:"team2_player1_id" db:"team2_player1_id"`
+	Team2Player2ID int            `json:"team2_player2_id" db:"team2_player2_id"`
+	MatchDate      time.Time      `json:"match_date" db:"match_date"`
+	CompletedAt    sql.NullTime   `json:"completed_at" db:"completed_at"`
+	VideoURL       sql.NullString `json:"video_url" db:"video_url"`
+	RallyChecks    RallyChecks    `json:"rally_checks" db:"rally_checks"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// RallyChecks is how a match treats edits that break the rally rules.
+type RallyChecks string
+
+const (
+	// RallyChecksWarn saves the edit and shows what is wrong with the rally
+	RallyChecksWarn RallyChecks = "warn"
+	// RallyChecksEnforce refuses the edit
+	RallyChecksEnforce RallyChecks = "enforce"
+)
+
+func (v RallyChecks) Valid() bool {
+	return v == RallyChecksWarn || v == RallyChecksEnforce
 }
 
 type MatchWithPlayers struct {
@@ -4,23 +4,26 @@ import (
 	"ct-padel-s/src/features/padel/match/matchmodel"
 	"ct-padel-s/src/infrastructure/database"
 	"database/sql"
+	"time"
 )
 
-func CreateMatch(db *database.DB, match *matchmodel.Match) error {
-	query := `INSERT INTO matches (team1_player1_id, team1_player2_id, team2_player1_id, team2_player2_id)
-			  VALUES ($1, $2, $3, $4)
+// CreateMatch inserts the match. A zero MatchDate means the match starts now.
+func CreateMatch(db database.Querier, match *matchmodel.Match) error {
+	query := `INSERT INTO matches (team1_player1_id, team1_player2_id, team2_player1_id, team2_player2_id, match_date)
+			  VALUES ($1, $2, $3, $4, COALESCE($5::timestamp, LOCALTIMESTAMP))
 			  RETURNING id, match_date, created_at, updated_at`
 	err := db.QueryRow(query,
 		match.Team1Player1ID,
 		match.Team1Player2ID,
 		match.Team2Player1ID,
-		match.Team2Player2ID).Scan(&match.ID, &match.MatchDate, &match.CreatedAt, &match.UpdatedAt)
+		match.Team2Player2ID,
+		sql.NullTime{Time: match.MatchDate, Valid: !match.MatchDate.IsZero()}).Scan(&match.ID, &match.MatchDate, &match.CreatedAt, &match.UpdatedAt)
 	return err
 }
 
-func GetMatch(db *database.DB, id int) (*matchmodel.Match, error) {
+func GetMatch(db database.Querier, id int) (*matchmodel.Match, error) {
 	match := &matchmodel.Match{}
-	query := `SELECT id, team1_player1_id, team1_player2_id, team2_player1_id, team2_player2_id, match_date, created_at, updated_at
+	query := `SELECT id, team1_player1_id, team1_player2_id, team2_player1_id, team2_player2_id, match_date, completed_at, video_url, rally_checks, created_at, updated_at
 			  FROM matches WHERE id = $1`
 	err := db.QueryRow(query, id).Scan(
 		&match.ID,
@@ -29,6 +32,9 @@ func GetMatch(db *database.DB, id int) (*matchmodel.Match, error) {
 		&match.Team2Player1ID,
 		&match.Team2Player2ID,
 		&match.MatchDate,
+		&match.CompletedAt,
+		&match.VideoURL,
+		&match.RallyChecks,
 		&match.CreatedAt,
 		&match.UpdatedAt)
 	if err == sql.ErrNoRows {
@@ -37,18 +43,43 @@ func GetMatch(db *database.DB, id int) (*matchmodel.Match, error) {
 	return match, err
 }
 
-func GetAllMatches(db *database.DB) ([]matchmodel.MatchWithPlayers, error) {
-	query := `SELECT
-		m.id, m.team1_player1_id, m.team1_player2_id, m.team2_player1_id, m.team2_player2_id, m.match_date, m.created_at, m.updated_at,
+// WithPlayersColumns selects a match and its four players from
+// WithPlayersFrom, in the order ScanWithPlayers reads them.
+const WithPlayersColumns = `
+		m.id, m.team1_player1_id, m.team1_player2_id, m.team2_player1_id, m.team2_player2_id, m.match_date, m.completed_at, m.video_url, m.rally_checks, m.created_at, m.updated_at,
 		p1.id, p1.name, p1.created_at,
 		p2.id, p2.name, p2.created_at,
 		p3.id, p3.name, p3.created_at,
-		p4.id, p4.name, p4.created_at
+		p4.id, p4.name, p4.created_at`
+
+// WithPlayersFrom joins matches, as m, to their players, as p1 to p4.
+const WithPlayersFrom = `
 	FROM matches m
 	JOIN players p1 ON m.team1_player1_id = p1.id
 	JOIN players p2 ON m.team1_player2_id = p2.id
 	JOIN players p3 ON m.team2_player1_id = p3.id
-	JOIN players p4 ON m.team2_player2_id = p4.id
+	JOIN players p4 ON m.team2_player2_id = p4.id`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// ScanWithPlayers reads a row selected by WithPlayersColumns into match.
+// Any columns selected after them are read into extra.
+func ScanWithPlayers(row scanner, match *matchmodel.MatchWithPlayers, extra ...any) error {
+	dest := []any{
+		&match.ID, &match.Team1Player1ID, &match.Team1Player2ID,
+		&match.Team2Player1ID, &match.Team2Player2ID, &match.MatchDate, &match.CompletedAt, &match.VideoURL, &match.RallyChecks, &match.CreatedAt, &match.UpdatedAt,
+		&match.Team1Player1.ID, &match.Team1Player1.Name, &match.Team1Player1.CreatedAt,
+		&match.Team1Player2.ID, &match.Team1Player2.Name, &match.Team1Player2.CreatedAt,
+		&match.Team2Player1.ID, &match.Team2Player1.Name, &match.Team2Player1.CreatedAt,
+		&match.Team2Player2.ID, &match.Team2Player2.Name, &match.Team2Player2.CreatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+func GetAllMatches(db *database.DB) ([]matchmodel.MatchWithPlayers, error) {
+	query := `SELECT` + WithPlayersColumns + WithPlayersFrom + `
 	ORDER BY m.match_date DESC`
 	rows, err := db.Query(query)
 	if err != nil {
@@ -59,14 +90,7 @@ func GetAllMatches(db *database.DB) ([]matchmodel.MatchWithPlayers, error) {
 	var matches []matchmodel.MatchWithPlayers
 	for rows.Next() {
 		var match matchmodel.MatchWithPlayers
-		err := rows.Scan(
-			&match.ID, &match.Team1Player1ID, &match.Team1Player2ID,
-			&match.Team2Player1ID, &match.Team2Player2ID, &match.MatchDate, &match.CreatedAt, &match.UpdatedAt,
-			&match.Team1Player1.ID, &match.Team1Player1.Name, &match.Team1Player1.CreatedAt,
-			&match.Team1Player2.ID, &match.Team1Player2.Name, &match.Team1Player2.CreatedAt,
-			&match.Team2Player1.ID, &match.Team2Player1.Name, &match.Team2Player1.CreatedAt,
-			&match.Team2Player2.ID, &match.Team2Player2.Name, &match.Team2Player2.CreatedAt)
-		if err != nil {
+		if err := ScanWithPlayers(rows, &match); err != nil {
 			return nil, err
 		}
 		matches = append(matches, match)
@@ -76,33 +100,53 @@ func GetAllMatches(db *database.DB) ([]matchmodel.MatchWithPlayers, error) {
 
 func GetMatchWithPlayers(db *database.DB, id int) (*matchmodel.MatchWithPlayers, error) {
 	match := &matchmodel.MatchWithPlayers{}
-	query := `SELECT
-		m.id, m.team1_player1_id, m.team1_player2_id, m.team2_player1_id, m.team2_player2_id, m.match_date, m.created_at, m.updated_at,
-		p1.id, p1.name, p1.created_at,
-		p2.id, p2.name, p2.created_at,
-		p3.id, p3.name, p3.created_at,
-		p4.id, p4.name, p4.created_at
-	FROM matches m
-	JOIN players p1 ON m.team1_player1_id = p1.id
-	JOIN players p2 ON m.team1_player2_id = p2.id
-	JOIN players p3 ON m.team2_player1_id = p3.id
-	JOIN players p4 ON m.team2_player2_id = p4.id
+	query := `SELECT` + WithPlayersColumns + WithPlayersFrom + `
 	WHERE m.id = $1`
 
-	err := db.QueryRow(query, id).Scan(
-		&match.ID, &match.Team1Player1ID, &match.Team1Player2ID,
-		&match.Team2Player1ID, &match.Team2Player2ID, &match.MatchDate, &match.CreatedAt, &match.UpdatedAt,
-		&match.Team1Player1.ID, &match.Team1Player1.Name, &match.Team1Player1.CreatedAt,
-		&match.Team1Player2.ID, &match.Team1Player2.Name, &match.Team1Player2.CreatedAt,
-		&match.Team2Player1.ID, &match.Team2Player1.Name, &match.Team2Player1.CreatedAt,
-		&match.Team2Player2.ID, &match.Team2Player2.Name, &match.Team2Player2.CreatedAt)
-
+	err := ScanWithPlayers(db.QueryRow(query, id), match)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return match, err
 }
 
+// CompleteMatch marks the match as complete. It reports whether the match
+// was still open, so completing twice is harmless.
+func CompleteMatch(db database.Querier, id int) (bool, error) {
+	query := `UPDATE matches SET completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 AND completed_at IS NULL`
+	result, err := db.Exec(query, id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// SetMatchDate moves the match's planned start.
+func SetMatchDate(db database.Querier, id int, matchDate time.Time) error {
+	query := `UPDATE matches SET match_date = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	_, err := db.Exec(query, matchDate, id)
+	return err
+}
+
+// SetVideoURL sets the match video, or clears it when url is NULL.
+func SetVideoURL(db *database.DB, id int, url sql.NullString) error {
+	query := `UPDATE matches SET video_url = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	_, err := db.Exec(query, url, id)
+	return err
+}
+
+// SetRallyChecks sets how the match treats edits that break the rally rules.
+func SetRallyChecks(db *database.DB, id int, mode matchmodel.RallyChecks) error {
+	query := `UPDATE matches SET rally_checks = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	_, err := db.Exec(query, mode, id)
+	return err
+}
+
 func DeleteMatch(db *database.DB, id int) error {
 	query := `DELETE FROM matches WHERE id = $1`
 	_, err := db.Exec(query, id)
@@ -0,0 +1,80 @@
+package matchrepo
+
+import "fmt"
+
+// ResultsCTE returns common table expressions that decide every point, game,
+// set and match of the matches CTE named by source, which must expose the
+// columns of the matches table. A point goes to the hitter's team on a winner
+// and to their opponents on an error; games, sets and matches go to the team
+// that won more of the level below, and stay undecided (NULL) on a tie.
+//
+// It defines point_outcomes (match_id, set_id, game_id, winner_team) and
+// match_results (match_id, winner_team, team1_points, team2_points,
+// team1_games, team2_games, team1_sets, team2_sets).
+func ResultsCTE(source string) string {
+	return fmt.Sprintf(`point_outcomes AS (
+	SELECT src.id AS match_id, s.id AS set_id, g.id AS game_id,
+		CASE
+			WHEN lp.player_id IN (src.team1_player1_id, src.team1_player2_id) THEN CASE WHEN lp.result_type = 'no_return_winner' THEN 1 ELSE 2 END
+			WHEN lp.player_id IN (src.team2_player1_id, src.team2_player2_id) THEN CASE WHEN lp.result_type = 'no_return_winner' THEN 2 ELSE 1 END
+		END AS winner_team
+	FROM %s src
+	JOIN sets s ON s.match_id = src.id
+	JOIN games g ON g.set_id = s.id
+	JOIN points pt ON pt.game_id = g.id
+	JOIN LATERAL (
+		SELECT player_id, result_type FROM plays
+		WHERE point_id = pt.id
+		ORDER BY play_number DESC
+		LIMIT 1
+	) lp ON lp.result_type IS NOT NULL
+),
+game_results AS (
+	SELECT match_id, set_id, game_id,
+		CASE
+			WHEN COUNT(*) FILTER (WHERE winner_team = 1) > COUNT(*) FILTER (WHERE winner_team = 2) THEN 1
+			WHEN COUNT(*) FILTER (WHERE winner_team = 1) < COUNT(*) FILTER (WHERE winner_team = 2) THEN 2
+		END AS winner_team
+	FROM point_outcomes
+	GROUP BY match_id, set_id, game_id
+),
+set_results AS (
+	SELECT match_id,
+		CASE
+			WHEN COUNT(*) FILTER (WHERE winner_team = 1) > COUNT(*) FILTER (WHERE winner_team = 2) THEN 1
+			WHEN COUNT(*) FILTER (WHERE winner_team = 1) < COUNT(*) FILTER (WHERE winner_team = 2) THEN 2
+		END AS winner_team
+	FROM game_results
+	GROUP BY match_id, set_id
+),
+match_results AS (
+	SELECT src.id AS match_id,
+		CASE
+			WHEN COUNT(*) FILTER (WHERE sr.winner_team = 1) > COUNT(*) FILTER (WHERE sr.winner_team = 2) THEN 1
+			WHEN COUNT(*) FILTER (WHERE sr.winner_team = 1) < COUNT(*) FILTER (WHERE sr.winner_team = 2) THEN 2
+		END AS winner_team,
+		COALESCE(MAX(mp.team1_points), 0) AS team1_points,
+		COALESCE(MAX(mp.team2_points), 0) AS team2_points,
+		COALESCE(MAX(mg.team1_games), 0) AS team1_games,
+		COALESCE(MAX(mg.team2_games), 0) AS team2_games,
+		COUNT(*) FILTER (WHERE sr.winner_team = 1) AS team1_sets,
+		COUNT(*) FILTER (WHERE sr.winner_team = 2) AS team2_sets
+	FROM %s src
+	LEFT JOIN set_results sr ON sr.match_id = src.id
+	LEFT JOIN (
+		SELECT match_id,
+			COUNT(*) FILTER (WHERE winner_team = 1) AS team1_points,
+			COUNT(*) FILTER (WHERE winner_team = 2) AS team2_points
+		FROM point_outcomes
+		GROUP BY match_id
+	) mp ON mp.match_id = src.id
+	LEFT JOIN (
+		SELECT match_id,
+			COUNT(*) FILTER (WHERE winner_team = 1) AS team1_games,
+			COUNT(*) FILTER (WHERE winner_team = 2) AS team2_games
+		FROM game_results
+		GROUP BY match_id
+	) mg ON mg.match_id = src.id
+	GROUP BY src.id
+)`, source, source)
+}
@@ -0,0 +1,33 @@
+package matchservice
+
+import (
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/rating/ratingservice"
+	"ct-padel-s/src/features/padel/tournament/tournamentservice"
+	"ct-padel-s/src/infrastructure/database"
+)
+
+// Complete marks the match as finished, rates its players and records the
+// result of its tournament fixture in one transaction, so a failure leaves
+// the match open and completing it again retries every step. Completing a
+// finished match does nothing.
+func Complete(db *database.DB, matchID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	completed, err := matchrepo.CompleteMatch(tx, matchID)
+	if err != nil || !completed {
+		return err
+	}
+	if err := ratingservice.RateMatch(tx, matchID); err != nil {
+		return err
+	}
+	if err := tournamentservice.RecordResult(tx, matchID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
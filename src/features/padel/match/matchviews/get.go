@@ -1,6 +1,7 @@
 package matchviews
 
 import (
+	"ct-padel-s/src/features/padel/analytics/winprob"
 	"ct-padel-s/src/features/padel/match/matchmodel"
 	"ct-padel-s/src/features/padel/set/setviews"
 	"ct-padel-s/src/features/padel/set/setmodel"
@@ -13,15 +14,21 @@ import (
 var getHTML string
 var getComponent = utils.NewComponent("get.html", getHTML)
 
-func RenderGet(match *matchmodel.MatchWithPlayers, sets []*setmodel.Set) (template.HTML, error) {
+func RenderGet(match *matchmodel.MatchWithPlayers, sets []*setmodel.Set, steps []winprob.Step) (template.HTML, error) {
 	setsList, err := setviews.RenderSetList(match.ID, sets)
 
 	if err != nil {
 		return "", err
 	}
 
+	momentum, err := RenderMomentum(steps)
+	if err != nil {
+		return "", err
+	}
+
 	return getComponent.Render(map[string]any{
 		"Match":    match,
 		"SetsList": setsList,
+		"Momentum": momentum,
 	})
 }
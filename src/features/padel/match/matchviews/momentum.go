@@ -0,0 +1,65 @@
+package matchviews
+
+import (
+	"ct-padel-s/src/features/padel/analytics/winprob"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+//go:embed momentum.html
+var momentumHTML string
+var momentumComponent = utils.NewComponent("momentum.html", momentumHTML)
+
+// Chart dimensions in SVG user units
+const (
+	momentumWidth   = 600
+	momentumHeight  = 200
+	momentumPadding = 20
+)
+
+type momentumPoint struct {
+	X       float64
+	Y       float64
+	Step    winprob.Step
+	Percent float64
+}
+
+type momentumViewModel struct {
+	Width        int
+	Height       int
+	Middle       int
+	Points       []momentumPoint
+	Polyline     string
+	Current      winprob.Step
+	Team1Percent float64
+	Team2Percent float64
+}
+
+// RenderMomentum draws team 1's chance of winning after each point, from
+// certain defeat at the bottom to certain victory at the top.
+func RenderMomentum(steps []winprob.Step) (template.HTML, error) {
+	viewModel := momentumViewModel{Width: momentumWidth, Height: momentumHeight, Middle: momentumHeight / 2}
+	if len(steps) < 2 {
+		return momentumComponent.Render(viewModel)
+	}
+	viewModel.Current = steps[len(steps)-1]
+	viewModel.Team1Percent = viewModel.Current.Team1 * 100
+	viewModel.Team2Percent = viewModel.Current.Team2() * 100
+
+	step := float64(momentumWidth-2*momentumPadding) / float64(len(steps)-1)
+	coordinates := make([]string, len(steps))
+	for i, s := range steps {
+		x := momentumPadding + step*float64(i)
+		y := momentumHeight - momentumPadding - s.Team1*(momentumHeight-2*momentumPadding)
+		coordinates[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+		if i > 0 {
+			viewModel.Points = append(viewModel.Points, momentumPoint{X: x, Y: y, Step: s, Percent: s.Team1 * 100})
+		}
+	}
+	viewModel.Polyline = strings.Join(coordinates, " ")
+
+	return momentumComponent.Render(viewModel)
+}
@@ -0,0 +1,112 @@
+package pairing
+
+import (
+	"ct-padel-s/src/features/padel/pairing/pairingmodel"
+	"ct-padel-s/src/features/padel/pairing/pairingrepo"
+	"ct-padel-s/src/features/padel/pairing/pairingviews"
+	"ct-padel-s/src/features/padel/player/playerrepo"
+	"ct-padel-s/src/features/padel/player/playershared"
+	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/shared/components/footer"
+	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
+	"ct-padel-s/src/shared/templates"
+	"database/sql"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Get renders the partnerships and head-to-head report, optionally limited
+// to one player (player query parameter) and a date range (from and to,
+// inclusive, YYYY-MM-DD).
+func Get(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	dateRange, err := playershared.ParseDateRange(from, to)
+	if err != nil {
+		logger.Warn("Invalid date range", "error", err, "from", from, "to", to)
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid date range, expected YYYY-MM-DD")
+		return
+	}
+	filter := pairingmodel.Filter{DateRange: dateRange}
+
+	playerID := 0
+	if value := r.URL.Query().Get("player"); value != "" {
+		playerID, err = strconv.Atoi(value)
+		if err != nil {
+			logger.Warn("Invalid player filter", "error", err, "player", value)
+			httperror.Write(w, r, http.StatusBadRequest, "Invalid player ID")
+			return
+		}
+		filter.PlayerID = sql.NullInt64{Int64: int64(playerID), Valid: true}
+	}
+
+	players, err := playerrepo.GetAllPlayers(db)
+	if err != nil {
+		logger.Error("Failed to get players", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get players")
+		return
+	}
+
+	partnerships, err := pairingrepo.GetPartnerships(db, filter)
+	if err != nil {
+		logger.Error("Failed to get partnerships", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get partnerships")
+		return
+	}
+
+	headToHeads, err := pairingrepo.GetHeadToHeads(db, filter)
+	if err != nil {
+		logger.Error("Failed to get head-to-heads", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get head-to-heads")
+		return
+	}
+
+	breadcrumb, err := pairingviews.RenderBreadcrumb()
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load shared components
+	headerHTML, err := header.Render(header.Data{Title: "Pairings - Padel Tracker", Breadcrumb: breadcrumb})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	footerHTML, err := footer.Render(footer.Data{})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load feature content and render with data
+	contentHTML, err := pairingviews.RenderGet(players, playerID, from, to, partnerships, headToHeads)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Compose final page
+	page, err := templates.Render(templates.Data{
+		Title:       "Pairings - Padel Tracker",
+		HeaderHTML:  headerHTML,
+		ContentHTML: contentHTML,
+		FooterHTML:  footerHTML,
+	})
+
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	io.WriteString(w, string(page))
+}
@@ -0,0 +1,68 @@
+package pairingmodel
+
+import (
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"database/sql"
+)
+
+// Pair is a doubles team. Player1 always has the lower ID, so the same two
+// players form the same pair whichever side of the match they were entered on.
+type Pair struct {
+	Player1 playermodel.Player `json:"player1"`
+	Player2 playermodel.Player `json:"player2"`
+}
+
+func (p Pair) Name() string {
+	return p.Player1.Name + " & " + p.Player2.Name
+}
+
+// Stats aggregates a pair's matches. Errors count the errors and unforced
+// errors hit by either player of the pair.
+type Stats struct {
+	playermodel.Record
+	PointsPlayed   int `json:"points_played"`
+	PointsWon      int `json:"points_won"`
+	Errors         int `json:"errors"`
+	UnforcedErrors int `json:"unforced_errors"`
+}
+
+func (s Stats) PointsWonPercent() float64 {
+	return percent(s.PointsWon, s.PointsPlayed)
+}
+
+// ErrorRate is the share of points played that the pair lost to a forced error.
+func (s Stats) ErrorRate() float64 {
+	return percent(s.Errors, s.PointsPlayed)
+}
+
+// UnforcedErrorRate is the share of points played that the pair lost to an
+// unforced error.
+func (s Stats) UnforcedErrorRate() float64 {
+	return percent(s.UnforcedErrors, s.PointsPlayed)
+}
+
+type Partnership struct {
+	Pair Pair `json:"pair"`
+	Stats
+}
+
+// HeadToHead is the record of Pair against Opponents, from Pair's side.
+type HeadToHead struct {
+	Pair      Pair `json:"pair"`
+	Opponents Pair `json:"opponents"`
+	Stats
+}
+
+// Filter limits the pairings report to a date range and, optionally, to
+// pairs including one player.
+type Filter struct {
+	DateRange playermodel.DateRange
+	PlayerID  sql.NullInt64
+}
+
+func percent(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(whole)
+}
@@ -0,0 +1,159 @@
+package pairingrepo
+
+import (
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/pairing/pairingmodel"
+	"ct-padel-s/src/infrastructure/database"
+)
+
+// sideResultsCTE produces side_results: one row per team per match between
+// $1 and $2, with the team as an order-independent pair (player1_id <
+// player2_id), its opponents likewise, and the team's result, points and
+// errors in that match.
+var sideResultsCTE = `scoped_matches AS (
+	SELECT * FROM matches
+	WHERE ($1::timestamp IS NULL OR match_date >= $1::timestamp)
+	  AND ($2::timestamp IS NULL OR match_date < $2::timestamp)
+), ` + matchrepo.ResultsCTE("scoped_matches") + `,
+team_errors AS (
+	SELECT sm.id AS match_id,
+		CASE WHEN pl.player_id IN (sm.team1_player1_id, sm.team1_player2_id) THEN 1 ELSE 2 END AS team,
+		COUNT(*) FILTER (WHERE pl.result_type = 'error') AS errors,
+		COUNT(*) FILTER (WHERE pl.result_type = 'unforced_error') AS unforced_errors
+	FROM scoped_matches sm
+	JOIN sets s ON s.match_id = sm.id
+	JOIN games g ON g.set_id = s.id
+	JOIN points pt ON pt.game_id = g.id
+	JOIN plays pl ON pl.point_id = pt.id
+	WHERE pl.player_id IN (sm.team1_player1_id, sm.team1_player2_id, sm.team2_player1_id, sm.team2_player2_id)
+	GROUP BY 1, 2
+),
+sides AS (
+	SELECT sm.id AS match_id, 1 AS team,
+		LEAST(sm.team1_player1_id, sm.team1_player2_id) AS player1_id,
+		GREATEST(sm.team1_player1_id, sm.team1_player2_id) AS player2_id,
+		LEAST(sm.team2_player1_id, sm.team2_player2_id) AS opponent1_id,
+		GREATEST(sm.team2_player1_id, sm.team2_player2_id) AS opponent2_id
+	FROM scoped_matches sm
+	UNION ALL
+	SELECT sm.id, 2,
+		LEAST(sm.team2_player1_id, sm.team2_player2_id),
+		GREATEST(sm.team2_player1_id, sm.team2_player2_id),
+		LEAST(sm.team1_player1_id, sm.team1_player2_id),
+		GREATEST(sm.team1_player1_id, sm.team1_player2_id)
+	FROM scoped_matches sm
+),
+side_results AS (
+	SELECT sd.player1_id, sd.player2_id, sd.opponent1_id, sd.opponent2_id,
+		CASE WHEN mr.winner_team = sd.team THEN 1 ELSE 0 END AS won,
+		CASE WHEN mr.winner_team = 3 - sd.team THEN 1 ELSE 0 END AS lost,
+		mr.team1_points + mr.team2_points AS points_played,
+		CASE WHEN sd.team = 1 THEN mr.team1_points ELSE mr.team2_points END AS points_won,
+		COALESCE(te.errors, 0) AS errors,
+		COALESCE(te.unforced_errors, 0) AS unforced_errors
+	FROM sides sd
+	JOIN match_results mr ON mr.match_id = sd.match_id
+	LEFT JOIN team_errors te ON te.match_id = sd.match_id AND te.team = sd.team
+)`
+
+// GetPartnerships aggregates every pair that played together, most matches
+// first. With filter.PlayerID set only that player's partnerships are returned.
+func GetPartnerships(db *database.DB, filter pairingmodel.Filter) ([]pairingmodel.Partnership, error) {
+	query := `WITH ` + sideResultsCTE + `
+			  SELECT p1.id, p1.name, p1.created_at, p2.id, p2.name, p2.created_at,
+				  COUNT(*) AS matches, SUM(sr.won), SUM(sr.lost),
+				  SUM(sr.points_played), SUM(sr.points_won), SUM(sr.errors), SUM(sr.unforced_errors)
+			  FROM side_results sr
+			  JOIN players p1 ON p1.id = sr.player1_id
+			  JOIN players p2 ON p2.id = sr.player2_id
+			  WHERE $3::integer IS NULL OR $3::integer IN (sr.player1_id, sr.player2_id)
+			  GROUP BY p1.id, p1.name, p1.created_at, p2.id, p2.name, p2.created_at
+			  ORDER BY matches DESC, p1.name, p2.name`
+	rows, err := db.Query(query, filter.DateRange.From, filter.DateRange.To, filter.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partnerships []pairingmodel.Partnership
+	for rows.Next() {
+		var partnership pairingmodel.Partnership
+		err := rows.Scan(
+			&partnership.Pair.Player1.ID,
+			&partnership.Pair.Player1.Name,
+			&partnership.Pair.Player1.CreatedAt,
+			&partnership.Pair.Player2.ID,
+			&partnership.Pair.Player2.Name,
+			&partnership.Pair.Player2.CreatedAt,
+			&partnership.Matches,
+			&partnership.Won,
+			&partnership.Lost,
+			&partnership.PointsPlayed,
+			&partnership.PointsWon,
+			&partnership.Errors,
+			&partnership.UnforcedErrors)
+		if err != nil {
+			return nil, err
+		}
+		partnerships = append(partnerships, partnership)
+	}
+	return partnerships, rows.Err()
+}
+
+// GetHeadToHeads aggregates every pair-against-pair meeting, most matches
+// first. Each meeting is reported once, from the side of the pair with the
+// lower player IDs, or from the side of filter.PlayerID when it is set.
+func GetHeadToHeads(db *database.DB, filter pairingmodel.Filter) ([]pairingmodel.HeadToHead, error) {
+	query := `WITH ` + sideResultsCTE + `
+			  SELECT p1.id, p1.name, p1.created_at, p2.id, p2.name, p2.created_at,
+				  o1.id, o1.name, o1.created_at, o2.id, o2.name, o2.created_at,
+				  COUNT(*) AS matches, SUM(sr.won), SUM(sr.lost),
+				  SUM(sr.points_played), SUM(sr.points_won), SUM(sr.errors), SUM(sr.unforced_errors)
+			  FROM side_results sr
+			  JOIN players p1 ON p1.id = sr.player1_id
+			  JOIN players p2 ON p2.id = sr.player2_id
+			  JOIN players o1 ON o1.id = sr.opponent1_id
+			  JOIN players o2 ON o2.id = sr.opponent2_id
+			  WHERE CASE
+				  WHEN $3::integer IS NULL THEN (sr.player1_id, sr.player2_id) < (sr.opponent1_id, sr.opponent2_id)
+				  ELSE $3::integer IN (sr.player1_id, sr.player2_id)
+			  END
+			  GROUP BY p1.id, p1.name, p1.created_at, p2.id, p2.name, p2.created_at,
+				  o1.id, o1.name, o1.created_at, o2.id, o2.name, o2.created_at
+			  ORDER BY matches DESC, p1.name, p2.name, o1.name, o2.name`
+	rows, err := db.Query(query, filter.DateRange.From, filter.DateRange.To, filter.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var headToHeads []pairingmodel.HeadToHead
+	for rows.Next() {
+		var h2h pairingmodel.HeadToHead
+		err := rows.Scan(
+			&h2h.Pair.Player1.ID,
+			&h2h.Pair.Player1.Name,
+			&h2h.Pair.Player1.CreatedAt,
+			&h2h.Pair.Player2.ID,
+			&h2h.Pair.Player2.Name,
+			&h2h.Pair.Player2.CreatedAt,
+			&h2h.Opponents.Player1.ID,
+			&h2h.Opponents.Player1.Name,
+			&h2h.Opponents.Player1.CreatedAt,
+			&h2h.Opponents.Player2.ID,
+			&h2h.Opponents.Player2.Name,
+			&h2h.Opponents.Player2.CreatedAt,
+			&h2h.Matches,
+			&h2h.Won,
+			&h2h.Lost,
+			&h2h.PointsPlayed,
+			&h2h.PointsWon,
+			&h2h.Errors,
+			&h2h.UnforcedErrors)
+		if err != nil {
+			return nil, err
+		}
+		headToHeads = append(headToHeads, h2h)
+	}
+	return headToHeads, rows.Err()
+}
@@ -0,0 +1,43 @@
+package pairingviews
+
+import (
+	"ct-padel-s/src/features/padel/pairing/pairingmodel"
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+)
+
+//go:embed get.html
+var getHTML string
+var getComponent = utils.NewComponent("get.html", getHTML)
+
+//go:embed breadcrumb.html
+var breadcrumbHTML string
+var breadcrumbComponent = utils.NewComponent("breadcrumb.html", breadcrumbHTML)
+
+type getViewModel struct {
+	Players      []*playermodel.Player
+	PlayerID     int
+	From         string
+	To           string
+	Partnerships []pairingmodel.Partnership
+	HeadToHeads  []pairingmodel.HeadToHead
+}
+
+// RenderGet renders the pairings report. playerID, from and to are echoed
+// back into the filter form; playerID 0 means all players.
+func RenderGet(players []*playermodel.Player, playerID int, from, to string, partnerships []pairingmodel.Partnership, headToHeads []pairingmodel.HeadToHead) (template.HTML, error) {
+	return getComponent.Render(getViewModel{
+		Players:      players,
+		PlayerID:     playerID,
+		From:         from,
+		To:           to,
+		Partnerships: partnerships,
+		HeadToHeads:  headToHeads,
+	})
+}
+
+func RenderBreadcrumb() (template.HTML, error) {
+	return breadcrumbComponent.Render(nil)
+}
@@ -1,83 +1,45 @@
 package play
 
 import (
-	"ct-padel-s/src/features/padel/game/gamerepo"
-	"ct-padel-s/src/features/padel/game/gameshared"
-	"ct-padel-s/src/features/padel/match/matchrepo"
-	"ct-padel-s/src/features/padel/match/matchshared"
+	"ct-padel-s/src/features/padel/court"
+	"ct-padel-s/src/features/padel/hierarchy"
 	"ct-padel-s/src/features/padel/play/playmodel"
 	"ct-padel-s/src/features/padel/play/playrepo"
-	"ct-padel-s/src/features/padel/play/playshared"
+	"ct-padel-s/src/features/padel/play/playservice"
+	"ct-padel-s/src/features/padel/play/playvalidation"
 	"ct-padel-s/src/features/padel/play/playviews"
-	"ct-padel-s/src/features/padel/point/pointrepo"
-	"ct-padel-s/src/features/padel/point/pointshared"
-	"ct-padel-s/src/features/padel/set/setrepo"
-	"ct-padel-s/src/features/padel/set/setshared"
+	"ct-padel-s/src/features/padel/play/rallycheck"
+	"ct-padel-s/src/features/padel/shot/shotrepo"
 	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/infrastructure/metrics"
 	"ct-padel-s/src/shared/components/footer"
 	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
+	"ct-padel-s/src/shared/precondition"
 	"ct-padel-s/src/shared/templates"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 func Create(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
-
-	matchID := matchshared.GetMatchID(w, r)
-	if matchID == 0 {
-		slog.Error("Invalid match ID", "matchID", matchID)
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
-	}
-
-	setID := setshared.GetSetID(w, r)
-	if setID == 0 {
-		slog.Error("Invalid set ID", "setID", setID)
-		http.Error(w, "Invalid set ID", http.StatusBadRequest)
-		return
-	}
-
-	gameID := gameshared.GetGameID(w, r)
-	if gameID == 0 {
-		slog.Error("Invalid game ID", "gameID", gameID)
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
-		return
-	}
-
-	pointID := pointshared.GetPointID(w, r)
-	if pointID == 0 {
-		slog.Error("Invalid point ID", "pointID", pointID)
-		http.Error(w, "Invalid point ID", http.StatusBadRequest)
-		return
-	}
-
-	plays, err := playrepo.GetPlaysByPoint(db, pointID)
-	if err != nil {
-		slog.Error("Failed to get plays", "error", err)
-		http.Error(w, "Failed to get plays", http.StatusInternalServerError)
-		return
-	}
-
-	// Check if the point has already ended (last play has a result_type)
-	if len(plays) > 0 {
-		lastPlay := plays[len(plays)-1]
-		if lastPlay.ResultType.Valid && lastPlay.ResultType.String != "" {
-			slog.Error("Cannot create play after point has ended", "pointID", pointID, "lastPlayResult", lastPlay.ResultType.String)
-			http.Error(w, "Cannot create play after point has ended", http.StatusBadRequest)
-			return
-		}
-	}
+	path := hierarchy.FromRequest(r)
+	matchID := path.Match.ID
+	setID := path.Set.ID
+	gameID := path.Game.ID
+	pointID := path.Point.ID
 
 	play := playmodel.Play{
 		PointID:       pointID,
-		PlayNumber:    len(plays) + 1,
 		PlayerID:      sql.NullInt64{Valid: false},
 		BallPositionX: 0,
 		BallPositionY: 0,
@@ -87,13 +49,26 @@ func Create(w http.ResponseWriter, r *http.Request) {
 		ShotEffect:    sql.NullString{Valid: false},
 	}
 
-	if err := playrepo.CreatePlay(db, &play); err != nil {
-		slog.Error("Failed to create play", "error", err)
-		http.Error(w, "Failed to create play", http.StatusInternalServerError)
+	// Plays entered live are stamped with the time of the hit; plays entered
+	// after the match have no timing
+	if r.FormValue("live") == "true" {
+		play.HitAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	// The repository assigns the next play number atomically and refuses to
+	// add plays after one that ended the point
+	if err := playrepo.CreatePlay(db, &play); err == playrepo.ErrPointEnded {
+		logger.Warn("Cannot create play after point has ended", "pointID", pointID)
+		httperror.Write(w, r, http.StatusBadRequest, "Cannot create play after point has ended")
+		return
+	} else if err != nil {
+		logger.Error("Failed to create play", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create play")
 		return
 	}
+	metrics.PlaysRecorded.Inc()
 
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 
 	// Set HTMX redirect header and return created status
 	w.Header().Set("HX-Redirect", fmt.Sprintf("/matches/%d/sets/%d/games/%d/points/%d/plays/%d", matchID, setID, gameID, pointID, play.ID))
@@ -101,129 +76,55 @@ func Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func Get(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
-	db := database.GetDB()
-
-	matchID := matchshared.GetMatchID(w, r)
-	if matchID == 0 {
-		slog.Error("Invalid match ID", "matchID", matchID)
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
-	}
-
-	setID := setshared.GetSetID(w, r)
-	if setID == 0 {
-		slog.Error("Invalid set ID", "setID", setID)
-		http.Error(w, "Invalid set ID", http.StatusBadRequest)
-		return
-	}
-
-	gameID := gameshared.GetGameID(w, r)
-	if gameID == 0 {
-		slog.Error("Invalid game ID", "gameID", gameID)
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
-		return
-	}
-
-	pointID := pointshared.GetPointID(w, r)
-	if pointID == 0 {
-		slog.Error("Invalid point ID", "pointID", pointID)
-		http.Error(w, "Invalid point ID", http.StatusBadRequest)
-		return
-	}
-
-	playID := playshared.GetPlayID(w, r)
-	if playID == 0 {
-		slog.Error("Invalid play ID", "playID", playID)
-		http.Error(w, "Invalid play ID", http.StatusBadRequest)
-		return
-	}
-
-	match, err := matchrepo.GetMatchWithPlayers(db, matchID)
-	if err != nil {
-		slog.Error("Failed to get match", "error", err, "matchID", matchID)
-		http.Error(w, "Failed to get match", http.StatusInternalServerError)
-		return
-	}
-
-	if match == nil {
-		http.Error(w, "Match not found", http.StatusNotFound)
-		return
-	}
-
-	set, err := setrepo.GetSet(db, setID)
-	if err != nil {
-		slog.Error("Failed to get set", "error", err, "setID", setID)
-		http.Error(w, "Failed to get set", http.StatusInternalServerError)
-		return
-	}
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	path := hierarchy.FromRequest(r)
+	match := path.Match
+	set := path.Set
+	game := path.Game
+	point := path.Point
+	play := path.Play
 
-	if set == nil {
-		http.Error(w, "Set not found", http.StatusNotFound)
-		return
-	}
-
-	game, err := gamerepo.GetGame(db, gameID)
+	breadcrumb, err := playviews.RenderBreadcrumb(match, set, game, point, play)
 	if err != nil {
-		slog.Error("Failed to get game", "error", err, "gameID", gameID)
-		http.Error(w, "Failed to get game", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
 		return
 	}
 
-	if game == nil {
-		http.Error(w, "Game not found", http.StatusNotFound)
-		return
-	}
+	// Load shared components
+	title := "Match: " + match.Name() + " - Set: " + strconv.Itoa(set.SetNumber) + " - Game: " + strconv.Itoa(game.GameNumber) + " - Point: " + strconv.Itoa(point.PointNumber) + " - Play: " + strconv.Itoa(play.PlayNumber)
 
-	point, err := pointrepo.GetPoint(db, pointID)
+	headerHTML, err := header.Render(header.Data{Title: title + " - Padel Tracker", Breadcrumb: breadcrumb})
 	if err != nil {
-		slog.Error("Failed to get point", "error", err, "pointID", pointID)
-		http.Error(w, "Failed to get point", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	if point == nil {
-		http.Error(w, "Point not found", http.StatusNotFound)
-		return
-	}
-
-	play, err := playrepo.GetPlay(db, playID)
+	footerHTML, err := footer.Render(footer.Data{})
 	if err != nil {
-		slog.Error("Failed to get play", "error", err, "playID", playID)
-		http.Error(w, "Failed to get play", http.StatusInternalServerError)
-		return
-	}
-
-	if play == nil {
-		http.Error(w, "Play not found", http.StatusNotFound)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	breadcrumb, err := playviews.RenderBreadcrumb(match, set, game, point, play)
+	// Show which rally checks the play fails
+	issues, err := playservice.Issues(database.GetDB(), match, point.ID)
 	if err != nil {
-		http.Error(w, "Template error", http.StatusInternalServerError)
+		logger.Error("Failed to check rally", "error", err, "pointID", point.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to check rally")
 		return
 	}
 
-	// Load shared components
-	title := "Match: " + match.Name() + " - Set: " + strconv.Itoa(set.SetNumber) + " - Game: " + strconv.Itoa(game.GameNumber) + " - Point: " + strconv.Itoa(point.PointNumber) + " - Play: " + strconv.Itoa(play.PlayNumber)
-
-	headerHTML, err := header.Render(header.Data{Title: title + " - Padel Tracker", Breadcrumb: breadcrumb})
+	catalogue, err := shotrepo.GetCatalogue(database.GetDB())
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
-	footerHTML, err := footer.Render(footer.Data{})
-	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		logger.Error("Failed to get shot catalogue", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get shot catalogue")
 		return
 	}
 
 	// Load feature content and render with data
-	contentHTML, err := playviews.RenderGet(play, point, game, set, match)
+	contentHTML, err := playviews.RenderGet(play, point, game, set, match, catalogue, rallycheck.ForPlay(issues, play.PlayNumber))
 	if err != nil {
-		http.Error(w, "Template error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
 		return
 	}
 
@@ -236,402 +137,267 @@ func Get(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	precondition.SetETag(w, play.Version)
 	io.WriteString(w, string(page))
 }
 
 func Delete(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
-
-	matchID := matchshared.GetMatchID(w, r)
-	if matchID == 0 {
-		slog.Error("Invalid match ID", "matchID", matchID)
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
-	}
-
-	setID := setshared.GetSetID(w, r)
-	if setID == 0 {
-		slog.Error("Invalid set ID", "setID", setID)
-		http.Error(w, "Invalid set ID", http.StatusBadRequest)
-		return
-	}
-
-	gameID := gameshared.GetGameID(w, r)
-	if gameID == 0 {
-		slog.Error("Invalid game ID", "gameID", gameID)
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
-		return
-	}
-
-	pointID := pointshared.GetPointID(w, r)
-	if pointID == 0 {
-		slog.Error("Invalid point ID", "pointID", pointID)
-		http.Error(w, "Invalid point ID", http.StatusBadRequest)
-		return
-	}
-
-	playID := playshared.GetPlayID(w, r)
-	if playID == 0 {
-		slog.Error("Invalid play ID", "playID", playID)
-		http.Error(w, "Invalid play ID", http.StatusBadRequest)
-		return
-	}
-
-	// Check if play exists
-	play, err := playrepo.GetPlay(db, playID)
-	if err != nil {
-		slog.Error("Failed to get play", "error", err, "playID", playID)
-		http.Error(w, "Failed to get play", http.StatusInternalServerError)
-		return
-	}
-
-	if play == nil {
-		http.Error(w, "Play not found", http.StatusNotFound)
-		return
-	}
+	path := hierarchy.FromRequest(r)
+	matchID := path.Match.ID
+	setID := path.Set.ID
+	gameID := path.Game.ID
+	pointID := path.Point.ID
+	playID := path.Play.ID
 
 	// Delete the play (this will also reorder remaining play numbers)
 	if err := playrepo.DeletePlay(db, playID); err != nil {
-		slog.Error("Failed to delete play", "error", err, "playID", playID)
-		http.Error(w, "Failed to delete play", http.StatusInternalServerError)
+		logger.Error("Failed to delete play", "error", err, "playID", playID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to delete play")
 		return
 	}
 
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 
 	w.Header().Set("HX-Redirect", fmt.Sprintf("/matches/%d/sets/%d/games/%d/points/%d", matchID, setID, gameID, pointID))
 	w.WriteHeader(http.StatusOK)
 }
 
 func GetByPoint(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
+	path := hierarchy.FromRequest(r)
+	pointID := path.Point.ID
 
-	matchID := matchshared.GetMatchID(w, r)
-	if matchID == 0 {
-		slog.Error("Invalid match ID", "matchID", matchID)
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+	plays, err := playrepo.GetPlaysByPoint(db, pointID)
+	if err != nil {
+		logger.Error("Failed to get plays", "error", err, "pointID", pointID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get plays")
 		return
 	}
 
-	setID := setshared.GetSetID(w, r)
-	if setID == 0 {
-		slog.Error("Invalid set ID", "setID", setID)
-		http.Error(w, "Invalid set ID", http.StatusBadRequest)
-		return
+	// Each play carries the named zone of its ball position
+	type zonedPlay struct {
+		*playmodel.Play
+		Zone     court.Zone `json:"zone"`
+		ZoneName string     `json:"zone_name"`
 	}
-
-	gameID := gameshared.GetGameID(w, r)
-	if gameID == 0 {
-		slog.Error("Invalid game ID", "gameID", gameID)
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
-		return
-	}
-
-	pointID := pointshared.GetPointID(w, r)
-	if pointID == 0 {
-		slog.Error("Invalid point ID", "pointID", pointID)
-		http.Error(w, "Invalid point ID", http.StatusBadRequest)
-		return
-	}
-
-	plays, err := playrepo.GetPlaysByPoint(db, pointID)
-	if err != nil {
-		slog.Error("Failed to get plays", "error", err, "pointID", pointID)
-		http.Error(w, "Failed to get plays", http.StatusInternalServerError)
-		return
+	zoned := make([]zonedPlay, len(plays))
+	for i, play := range plays {
+		zoned[i] = zonedPlay{Play: play, Zone: play.Zone(), ZoneName: play.Zone().Name()}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(plays)
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	json.NewEncoder(w).Encode(zoned)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 }
 
 func Patch(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
+	path := hierarchy.FromRequest(r)
+	playID := path.Play.ID
 
-	playID := playshared.GetPlayID(w, r)
-	if playID == 0 {
-		slog.Error("Invalid play ID", "playID", playID)
-		w.WriteHeader(http.StatusBadRequest)
+	expectedVersion, ok := parseEdit(w, r)
+	if !ok {
 		return
 	}
 
-	// Get existing play
-	existingPlay, err := playrepo.GetPlay(db, playID)
+	// Save the edit; missing player and ball position keep their current
+	// values, and a result is refused on any play but the last
+	result, err := playservice.Save(db, path.Match, playID, expectedVersion, playvalidation.FromForm(r))
 	if err != nil {
-		slog.Error("Failed to get play", "error", err, "playID", playID)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	if existingPlay == nil {
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-
-	// Parse form data
-	if err := r.ParseForm(); err != nil {
-		slog.Error("Failed to parse form", "error", err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeEditError(w, r, err)
 		return
 	}
+	metrics.PlaysUpdated.Inc()
 
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	precondition.SetETag(w, result.Play.Version)
 
-	// Update the play with form values
-	updatedPlay := *existingPlay
-
-	// Parse player ID (optional)
-	if playerIDStr := r.FormValue("player_id"); playerIDStr != "" {
-		if playerID, err := strconv.ParseInt(playerIDStr, 10, 64); err == nil {
-			updatedPlay.PlayerID = sql.NullInt64{Int64: playerID, Valid: true}
-		}
-	}
-
-	// Parse ball positions (optional, use existing values as fallback)
-	if ballXStr := r.FormValue("ball_position_x"); ballXStr != "" {
-		if ballX, err := strconv.Atoi(ballXStr); err == nil && ballX >= 0 && ballX <= 10000 {
-			updatedPlay.BallPositionX = ballX
-		}
-	}
-	if ballYStr := r.FormValue("ball_position_y"); ballYStr != "" {
-		if ballY, err := strconv.Atoi(ballYStr); err == nil && ballY >= 0 && ballY <= 20000 {
-			updatedPlay.BallPositionY = ballY
+	// HTMX clients get the rally checks the saved play fails swapped into
+	// the editor out of band
+	if httperror.IsHTMX(r) {
+		warningsHTML, err := playviews.RenderWarnings(result.Warnings, true)
+		if err == nil {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			io.WriteString(w, string(warningsHTML))
+			return
 		}
 	}
-
-	// Handle optional fields
-	if resultType := r.FormValue("result_type"); resultType != "" {
-		updatedPlay.ResultType = sql.NullString{String: resultType, Valid: true}
-	} else {
-		updatedPlay.ResultType = sql.NullString{Valid: false}
-	}
-
-	if handSide := r.FormValue("hand_side"); handSide != "" {
-		updatedPlay.HandSide = sql.NullString{String: handSide, Valid: true}
-	} else {
-		updatedPlay.HandSide = sql.NullString{Valid: false}
-	}
-
-	if contactType := r.FormValue("contact_type"); contactType != "" {
-		updatedPlay.ContactType = sql.NullString{String: contactType, Valid: true}
-	} else {
-		updatedPlay.ContactType = sql.NullString{Valid: false}
-	}
-
-	if shotEffect := r.FormValue("shot_effect"); shotEffect != "" {
-		updatedPlay.ShotEffect = sql.NullString{String: shotEffect, Valid: true}
-	} else {
-		updatedPlay.ShotEffect = sql.NullString{Valid: false}
-	}
-
-	// Save to database
-	if err := playrepo.UpdatePlay(db, &updatedPlay); err != nil {
-		slog.Error("Failed to update play", "error", err, "playID", playID)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
 	w.WriteHeader(http.StatusOK)
 }
 
-func Update(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+// Commit saves the shot and advances the rally: a shot with a result ends
+// the point and returns to the game, any other moves on to the next play.
+func Commit(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
+	path := hierarchy.FromRequest(r)
+	matchID := path.Match.ID
+	setID := path.Set.ID
+	gameID := path.Game.ID
+	pointID := path.Point.ID
+	playID := path.Play.ID
 
-	matchID := matchshared.GetMatchID(w, r)
-	if matchID == 0 {
-		slog.Error("Invalid match ID", "matchID", matchID)
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+	expectedVersion, ok := parseEdit(w, r)
+	if !ok {
 		return
 	}
 
-	setID := setshared.GetSetID(w, r)
-	if setID == 0 {
-		slog.Error("Invalid set ID", "setID", setID)
-		http.Error(w, "Invalid set ID", http.StatusBadRequest)
+	// A committed shot must name the player and ball position
+	result, err := playservice.Commit(db, path.Match, playID, expectedVersion, playvalidation.FromForm(r))
+	if err != nil {
+		writeEditError(w, r, err)
 		return
 	}
+	metrics.PlaysUpdated.Inc()
+
+	if result.PointEnded {
+		logger.Info("Point ended", "pointID", pointID, "finalResult", result.Play.ResultType.String)
+		logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 
-	gameID := gameshared.GetGameID(w, r)
-	if gameID == 0 {
-		slog.Error("Invalid game ID", "gameID", gameID)
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		// Redirect back to the game (let user decide if game continues)
+		w.Header().Set("HX-Redirect", fmt.Sprintf("/matches/%d/sets/%d/games/%d", matchID, setID, gameID))
+		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	pointID := pointshared.GetPointID(w, r)
-	if pointID == 0 {
-		slog.Error("Invalid point ID", "pointID", pointID)
-		http.Error(w, "Invalid point ID", http.StatusBadRequest)
-		return
+	if result.Created {
+		metrics.PlaysRecorded.Inc()
+		logger.Info("Point continues, created next play", "pointID", pointID, "nextPlayID", result.NextPlayID)
 	}
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 
-	playID := playshared.GetPlayID(w, r)
-	if playID == 0 {
-		slog.Error("Invalid play ID", "playID", playID)
-		http.Error(w, "Invalid play ID", http.StatusBadRequest)
-		return
+	// Redirect to the next play of the rally
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/matches/%d/sets/%d/games/%d/points/%d/plays/%d", matchID, setID, gameID, pointID, result.NextPlayID))
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseEdit parses the edit form and returns the version of the play it was
+// made against: the client's precondition, or else the version the request
+// loaded. It writes the error response and reports false when either is
+// malformed.
+func parseEdit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	logger := logging.FromRequest(r)
+	existingPlay := hierarchy.FromRequest(r).Play
+
+	if err := r.ParseForm(); err != nil {
+		logger.Error("Failed to parse form", "error", err)
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid form data")
+		return 0, false
 	}
 
-	// Get existing play
-	existingPlay, err := playrepo.GetPlay(db, playID)
+	expectedVersion, hasPrecondition, err := precondition.ExpectedVersion(r)
 	if err != nil {
-		slog.Error("Failed to get play", "error", err, "playID", playID)
-		http.Error(w, "Failed to get play", http.StatusInternalServerError)
-		return
+		logger.Warn("Invalid precondition", "error", err, "playID", existingPlay.ID)
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid If-Match version")
+		return 0, false
 	}
-
-	if existingPlay == nil {
-		http.Error(w, "Play not found", http.StatusNotFound)
-		return
+	if !hasPrecondition {
+		expectedVersion = existingPlay.Version
 	}
+	return expectedVersion, true
+}
 
-	// Parse form data
-	if err := r.ParseForm(); err != nil {
-		slog.Error("Failed to parse form", "error", err)
-		http.Error(w, "Invalid form data", http.StatusBadRequest)
-		return
+// writeEditError answers an edit the play service refused.
+func writeEditError(w http.ResponseWriter, r *http.Request, err error) {
+	logger := logging.FromRequest(r)
+	db := database.GetDB()
+	path := hierarchy.FromRequest(r)
+	playID := path.Play.ID
+
+	var errs playvalidation.Errors
+	switch {
+	case errors.As(err, &errs):
+		writeInvalid(w, r, path.Play, errs)
+	case errors.Is(err, database.ErrConflict):
+		current, err := playrepo.GetPlay(db, playID)
+		if err != nil || current == nil {
+			logger.Error("Failed to reload play after conflict", "error", err, "playID", playID)
+			httperror.Write(w, r, http.StatusConflict, "This play was changed by someone else. Reload and try again.")
+			return
+		}
+		writeConflict(w, r, current)
+	case errors.Is(err, playservice.ErrPlayNotFound):
+		httperror.NotFound(w, r, "Play not found")
+	default:
+		logger.Error("Failed to save play", "error", err, "playID", playID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to save play")
 	}
+}
 
-	// Parse player ID
-	playerIDStr := r.FormValue("player_id")
-	if playerIDStr == "" {
-		http.Error(w, "Player ID is required", http.StatusBadRequest)
-		return
-	}
-	playerID, err := strconv.ParseInt(playerIDStr, 10, 64)
-	if err != nil {
-		slog.Error("Invalid player ID", "error", err, "playerID", playerIDStr)
-		http.Error(w, "Invalid player ID", http.StatusBadRequest)
+// writeInvalid answers an edit with invalid fields with 422 Unprocessable
+// Entity. HTMX clients get the editor re-rendered with the stored play and a
+// message against each invalid field; other clients get the usual error
+// response listing them.
+func writeInvalid(w http.ResponseWriter, r *http.Request, current *playmodel.Play, errs playvalidation.Errors) {
+	logger := logging.FromRequest(r)
+	logger.Warn("Invalid play edit", "playID", current.ID, "errors", errs.Error())
+
+	if !httperror.IsHTMX(r) {
+		httperror.Write(w, r, http.StatusUnprocessableEntity, errs.Error())
 		return
 	}
 
-	// Parse ball positions
-	ballXStr := r.FormValue("ball_position_x")
-	ballYStr := r.FormValue("ball_position_y")
-	ballX, err := strconv.Atoi(ballXStr)
-	if err != nil || ballX < 0 || ballX > 10000 {
-		slog.Error("Invalid ball position X", "error", err, "ballX", ballXStr)
-		http.Error(w, "Invalid ball position X", http.StatusBadRequest)
+	path := hierarchy.FromRequest(r)
+	catalogue, err := shotrepo.GetCatalogue(database.GetDB())
+	if err != nil {
+		httperror.Write(w, r, http.StatusUnprocessableEntity, errs.Error())
 		return
 	}
-	ballY, err := strconv.Atoi(ballYStr)
-	if err != nil || ballY < 0 || ballY > 20000 {
-		slog.Error("Invalid ball position Y", "error", err, "ballY", ballYStr)
-		http.Error(w, "Invalid ball position Y", http.StatusBadRequest)
+	contentHTML, err := playviews.RenderInvalid(current, path.Point, path.Game, path.Set, path.Match, catalogue, errs)
+	if err != nil {
+		httperror.Write(w, r, http.StatusUnprocessableEntity, errs.Error())
 		return
 	}
 
-	// Get form values for the optional fields
-	resultType := r.FormValue("result_type")
-	handSide := r.FormValue("hand_side")
-	contactType := r.FormValue("contact_type")
-	shotEffect := r.FormValue("shot_effect")
-
-	// Update the play
-	updatedPlay := *existingPlay
-	updatedPlay.PlayerID = sql.NullInt64{Int64: playerID, Valid: true}
-	updatedPlay.BallPositionX = ballX
-	updatedPlay.BallPositionY = ballY
-
-	if resultType != "" && resultType != "Return" {
-		updatedPlay.ResultType = sql.NullString{String: resultType, Valid: true}
-	} else {
-		updatedPlay.ResultType = sql.NullString{Valid: false}
-	}
-
-	if handSide != "" {
-		updatedPlay.HandSide = sql.NullString{String: handSide, Valid: true}
-	} else {
-		updatedPlay.HandSide = sql.NullString{Valid: false}
-	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("HX-Retarget", "#"+playviews.EditorID)
+	w.Header().Set("HX-Reswap", "outerHTML")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	io.WriteString(w, string(contentHTML))
+}
 
-	if contactType != "" {
-		updatedPlay.ContactType = sql.NullString{String: contactType, Valid: true}
-	} else {
-		updatedPlay.ContactType = sql.NullString{Valid: false}
-	}
+// writeConflict answers a stale edit with 409 Conflict. HTMX clients get the
+// editor re-rendered with the current state of the play swapped in place of
+// the stale one; other clients get the usual error response.
+func writeConflict(w http.ResponseWriter, r *http.Request, current *playmodel.Play) {
+	logger := logging.FromRequest(r)
+	logger.Warn("Play edit conflict", "playID", current.ID, "currentVersion", current.Version)
+	precondition.SetETag(w, current.Version)
 
-	if shotEffect != "" {
-		updatedPlay.ShotEffect = sql.NullString{String: shotEffect, Valid: true}
-	} else {
-		updatedPlay.ShotEffect = sql.NullString{Valid: false}
+	if !httperror.IsHTMX(r) {
+		httperror.Write(w, r, http.StatusConflict, "This play was changed by someone else. Reload and try again.")
+		return
 	}
 
-	// Save to database
-	if err := playrepo.UpdatePlay(db, &updatedPlay); err != nil {
-		slog.Error("Failed to update play", "error", err, "playID", playID)
-		http.Error(w, "Failed to update play", http.StatusInternalServerError)
+	path := hierarchy.FromRequest(r)
+	catalogue, err := shotrepo.GetCatalogue(database.GetDB())
+	if err != nil {
+		httperror.Write(w, r, http.StatusConflict, "This play was changed by someone else. Reload and try again.")
 		return
 	}
-
-	// Check if this play ends the point (result_type is not null and not "Return")
-	pointEnded := updatedPlay.ResultType.Valid && updatedPlay.ResultType.String != ""
-	pointWasEnded := existingPlay.ResultType.Valid && existingPlay.ResultType.String != ""
-
-	// Handle the case where point was previously ended but now continues
-	if pointWasEnded && !pointEnded {
-		slog.Warn("Point was previously ended but now continues", "pointID", pointID, "playID", playID,
-			"previousResult", existingPlay.ResultType.String)
-		// Note: This could potentially affect subsequent points that were auto-created
-		// For now, we allow this but log a warning. Future enhancement: validate consistency
+	contentHTML, err := playviews.RenderConflict(current, path.Point, path.Game, path.Set, path.Match, catalogue)
+	if err != nil {
+		httperror.Write(w, r, http.StatusConflict, "This play was changed by someone else. Reload and try again.")
+		return
 	}
 
-	if pointEnded {
-		// Delete any subsequent plays in this point
-		if err := playrepo.DeleteSubsequentPlays(db, pointID, updatedPlay.PlayNumber); err != nil {
-			slog.Error("Failed to delete subsequent plays", "error", err, "pointID", pointID, "playNumber", updatedPlay.PlayNumber)
-			http.Error(w, "Failed to cleanup plays", http.StatusInternalServerError)
-			return
-		}
-
-		slog.Info("Point ended", "pointID", pointID, "finalResult", updatedPlay.ResultType.String)
-		slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
-
-		// Redirect back to the game (let user decide if game continues)
-		w.Header().Set("HX-Redirect", fmt.Sprintf("/matches/%d/sets/%d/games/%d", matchID, setID, gameID))
-		w.WriteHeader(http.StatusOK)
-	} else {
-		// Point continues - create next play in the same point
-		allPlays, err := playrepo.GetPlaysByPoint(db, pointID)
-		if err != nil {
-			slog.Error("Failed to get plays for next play creation", "error", err, "pointID", pointID)
-			http.Error(w, "Failed to get plays", http.StatusInternalServerError)
-			return
-		}
-
-		nextPlay := playmodel.Play{
-			PointID:       pointID,
-			PlayNumber:    len(allPlays) + 1,
-			PlayerID:      sql.NullInt64{Valid: false},
-			BallPositionX: 0,
-			BallPositionY: 0,
-			ResultType:    sql.NullString{Valid: false},
-			HandSide:      sql.NullString{Valid: false},
-			ContactType:   sql.NullString{Valid: false},
-			ShotEffect:    sql.NullString{Valid: false},
-		}
-
-		if err := playrepo.CreatePlay(db, &nextPlay); err != nil {
-			slog.Error("Failed to create next play", "error", err, "pointID", pointID)
-			http.Error(w, "Failed to create next play", http.StatusInternalServerError)
-			return
-		}
-
-		slog.Info("Point continues, created next play", "pointID", pointID, "nextPlayID", nextPlay.ID, "playNumber", nextPlay.PlayNumber)
-		slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
-
-		// Redirect to the new play
-		w.Header().Set("HX-Redirect", fmt.Sprintf("/matches/%d/sets/%d/games/%d/points/%d/plays/%d", matchID, setID, gameID, pointID, nextPlay.ID))
-		w.WriteHeader(http.StatusOK)
-	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("HX-Retarget", "#"+playviews.EditorID)
+	w.Header().Set("HX-Reswap", "outerHTML")
+	w.WriteHeader(http.StatusConflict)
+	io.WriteString(w, string(contentHTML))
 }
@@ -0,0 +1,88 @@
+package playmodel
+
+import (
+	"ct-padel-s/src/features/padel/court"
+	"slices"
+)
+
+// The attribute types below mirror the CHECK constraints on the plays table.
+// An unset attribute is stored as NULL, never as an empty string.
+
+type ResultType string
+
+const (
+	ResultUnforcedError  ResultType = "unforced_error"
+	ResultError          ResultType = "error"
+	ResultNoReturnWinner ResultType = "no_return_winner"
+)
+
+var ResultTypes = []ResultType{ResultUnforcedError, ResultError, ResultNoReturnWinner}
+
+func (v ResultType) Valid() bool {
+	return slices.Contains(ResultTypes, v)
+}
+
+type HandSide string
+
+const (
+	HandForehand HandSide = "forehand"
+	HandBackhand HandSide = "backhand"
+)
+
+var HandSides = []HandSide{HandForehand, HandBackhand}
+
+func (v HandSide) Valid() bool {
+	return slices.Contains(HandSides, v)
+}
+
+// Contact types and shot effects are kept in the shot catalogue (see
+// shotmodel) rather than a CHECK constraint. The codes below are the ones the
+// catalogue starts with that rally checks, stats and the seed refer to.
+
+type ContactType string
+
+const (
+	ContactServe        ContactType = "serve"
+	ContactGroundstroke ContactType = "groundstroke"
+	ContactVolley       ContactType = "volley"
+	ContactOverhead     ContactType = "overhead"
+)
+
+type ShotEffect string
+
+const (
+	EffectFlat     ShotEffect = "flat"
+	EffectLob      ShotEffect = "lob"
+	EffectDown     ShotEffect = "down"
+	EffectDrop     ShotEffect = "drop"
+	EffectChiquita ShotEffect = "chiquita"
+	EffectSmash    ShotEffect = "smash"
+	EffectBandeja  ShotEffect = "bandeja"
+	EffectVibora   ShotEffect = "vibora"
+	EffectBajada   ShotEffect = "bajada"
+)
+
+type ExitCourt string
+
+const (
+	// ExitDoor is out through a door in the side fence
+	ExitDoor ExitCourt = "door"
+	// ExitOverWall is over the glass or the fence
+	ExitOverWall ExitCourt = "over_wall"
+)
+
+var ExitCourts = []ExitCourt{ExitDoor, ExitOverWall}
+
+func (v ExitCourt) Valid() bool {
+	return slices.Contains(ExitCourts, v)
+}
+
+// MaxWallBounces is how many walls a play may record the ball hitting,
+// matching the schema
+const MaxWallBounces = 3
+
+// Court bounds for ball and player positions, matching the schema
+const (
+	MaxPositionX = court.Width
+	MaxPositionY = court.Length
+)
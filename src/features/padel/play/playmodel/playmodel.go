@@ -1,7 +1,9 @@
 package playmodel
 
 import (
+	"ct-padel-s/src/features/padel/court"
 	"database/sql"
+	"slices"
 	"time"
 )
 
@@ -16,6 +18,29 @@ type Play struct {
 	HandSide      sql.NullString `json:"hand_side" db:"hand_side"`
 	ContactType   sql.NullString `json:"contact_type" db:"contact_type"`
 	ShotEffect    sql.NullString `json:"shot_effect" db:"shot_effect"`
+	// WallBounces are the walls the ball hit after the shot, in order
+	WallBounces []string `json:"wall_bounces" db:"wall_bounces"`
+	// ExitCourt is where the ball left the court after the shot, if it did
+	ExitCourt     sql.NullString `json:"exit_court" db:"exit_court"`
+	VideoOffsetMs sql.NullInt64  `json:"video_offset_ms" db:"video_offset_ms"`
+	HitAt         sql.NullTime   `json:"hit_at" db:"hit_at"`
+	Version       int            `json:"version" db:"version"`
 	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
-}
\ No newline at end of file
+}
+
+// Zone returns the part of the court the ball was hit from.
+func (p *Play) Zone() court.Zone {
+	return court.Classify(p.BallPositionX, p.BallPositionY)
+}
+
+// HitWall reports whether the ball hit wall after the shot.
+func (p *Play) HitWall(wall court.Wall) bool {
+	return slices.Contains(p.WallBounces, string(wall))
+}
+
+// EndsPoint reports whether the play carries a result, which makes it the
+// last play of its point.
+func (p *Play) EndsPoint() bool {
+	return p.ResultType.Valid && p.ResultType.String != ""
+}
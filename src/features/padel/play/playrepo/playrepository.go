@@ -2,16 +2,63 @@ package playrepo
 
 import (
 	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/scoring"
 	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
 )
 
+// ErrPointEnded is returned by CreatePlay when the last play of the point
+// already carries a result, so the rally is over.
+var ErrPointEnded = errors.New("point has already ended")
+
+// CreatePlay inserts play as the next play of its point, assigning
+// play.PlayNumber. The point row is locked for the duration so concurrent
+// creates are numbered one after the other instead of colliding on
+// UNIQUE(point_id, play_number).
 func CreatePlay(db *database.DB, play *playmodel.Play) error {
-	query := `INSERT INTO plays (point_id, play_number, player_id, ball_position_x, ball_position_y, result_type, hand_side, contact_type, shot_effect) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) 
-			  RETURNING id, created_at, updated_at`
-	err := db.QueryRow(query, 
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := InsertPlay(tx, play); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// LockPoint locks the point row until the end of the transaction, so plays
+// of the point are created, changed and deleted one request at a time.
+func LockPoint(q database.Querier, pointID int) error {
+	_, err := q.Exec(`SELECT id FROM points WHERE id = $1 FOR UPDATE`, pointID)
+	return err
+}
+
+// InsertPlay is CreatePlay for a caller that already holds a transaction.
+func InsertPlay(tx database.Querier, play *playmodel.Play) error {
+	if err := LockPoint(tx, play.PointID); err != nil {
+		return err
+	}
+
+	// A play with a result ends the point; nothing may follow it
+	var lastResult sql.NullString
+	err := tx.QueryRow(`SELECT result_type FROM plays WHERE point_id = $1 ORDER BY play_number DESC LIMIT 1`, play.PointID).Scan(&lastResult)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if lastResult.Valid && lastResult.String != "" {
+		return ErrPointEnded
+	}
+
+	query := `INSERT INTO plays (point_id, play_number, player_id, ball_position_x, ball_position_y, result_type, hand_side, contact_type, shot_effect, wall_bounces, exit_court, hit_at) 
+			  SELECT $1, COALESCE(MAX(play_number), 0) + 1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9::TEXT[], '{}'), $10, $11 FROM plays WHERE point_id = $1
+			  RETURNING id, play_number, version, created_at, updated_at`
+	err = tx.QueryRow(query, 
 		play.PointID, 
-		play.PlayNumber, 
 		play.PlayerID, 
 		play.BallPositionX, 
 		play.BallPositionY, 
@@ -19,12 +66,28 @@ func CreatePlay(db *database.DB, play *playmodel.Play) error {
 		play.HandSide, 
 		play.ContactType, 
 		play.ShotEffect,
-	).Scan(&play.ID, &play.CreatedAt, &play.UpdatedAt)
-	return err
+		pq.Array(play.WallBounces),
+		play.ExitCourt,
+		play.HitAt,
+	).Scan(&play.ID, &play.PlayNumber, &play.Version, &play.CreatedAt, &play.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	// A play recorded live starts the point's clock if nothing else has
+	if play.HitAt.Valid {
+		_, err = tx.Exec(`UPDATE points SET started_at = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP
+						  WHERE id = $2 AND started_at IS NULL AND (ended_at IS NULL OR ended_at >= $1)`, play.HitAt, play.PointID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func GetPlaysByPoint(db *database.DB, pointID int) ([]*playmodel.Play, error) {
-	query := `SELECT id, point_id, play_number, player_id, ball_position_x, ball_position_y, result_type, hand_side, contact_type, shot_effect, created_at, updated_at 
+func GetPlaysByPoint(db database.Querier, pointID int) ([]*playmodel.Play, error) {
+	query := `SELECT id, point_id, play_number, player_id, ball_position_x, ball_position_y, result_type, hand_side, contact_type, shot_effect, wall_bounces, exit_court, video_offset_ms, hit_at, version, created_at, updated_at 
 			  FROM plays WHERE point_id = $1 ORDER BY play_number`
 	rows, err := db.Query(query, pointID)
 	if err != nil {
@@ -46,6 +109,11 @@ func GetPlaysByPoint(db *database.DB, pointID int) ([]*playmodel.Play, error) {
 			&play.HandSide, 
 			&play.ContactType, 
 			&play.ShotEffect, 
+			pq.Array(&play.WallBounces),
+			&play.ExitCourt,
+			&play.VideoOffsetMs,
+			&play.HitAt,
+			&play.Version, 
 			&play.CreatedAt, 
 			&play.UpdatedAt,
 		)
@@ -57,8 +125,8 @@ func GetPlaysByPoint(db *database.DB, pointID int) ([]*playmodel.Play, error) {
 	return plays, rows.Err()
 }
 
-func GetPlay(db *database.DB, playID int) (*playmodel.Play, error) {
-	query := `SELECT id, point_id, play_number, player_id, ball_position_x, ball_position_y, result_type, hand_side, contact_type, shot_effect, created_at, updated_at 
+func GetPlay(db database.Querier, playID int) (*playmodel.Play, error) {
+	query := `SELECT id, point_id, play_number, player_id, ball_position_x, ball_position_y, result_type, hand_side, contact_type, shot_effect, wall_bounces, exit_court, video_offset_ms, hit_at, version, created_at, updated_at 
 			  FROM plays WHERE id = $1`
 	var play playmodel.Play
 	err := db.QueryRow(query, playID).Scan(
@@ -72,35 +140,55 @@ func GetPlay(db *database.DB, playID int) (*playmodel.Play, error) {
 		&play.HandSide, 
 		&play.ContactType, 
 		&play.ShotEffect, 
+		pq.Array(&play.WallBounces),
+		&play.ExitCourt,
+		&play.VideoOffsetMs,
+		&play.HitAt,
+		&play.Version, 
 		&play.CreatedAt, 
 		&play.UpdatedAt,
 	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	return &play, err
 }
 
+// DeletePlay deletes the play and renumbers the remaining plays of its point.
 func DeletePlay(db *database.DB, playID int) error {
-	// First get the play to know its point_id and play_number
-	play, err := GetPlay(db, playID)
+	// Begin transaction
+	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// Begin transaction
-	tx, err := db.Begin()
+	// Lock the point so deletes and creates of sibling plays do not interleave
+	var pointID int
+	err = tx.QueryRow(`SELECT point_id FROM plays WHERE id = $1`, playID).Scan(&pointID)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	if err := LockPoint(tx, pointID); err != nil {
+		return err
+	}
+
+	// Renumbering shifts siblings onto each other's numbers, so only check
+	// uniqueness once the whole transaction is done
+	if _, err := tx.Exec(`SET CONSTRAINTS ALL DEFERRED`); err != nil {
+		return err
+	}
 
 	// Delete the play
-	_, err = tx.Exec(`DELETE FROM plays WHERE id = $1`, playID)
+	var playNumber int
+	err = tx.QueryRow(`DELETE FROM plays WHERE id = $1 RETURNING play_number`, playID).Scan(&playNumber)
 	if err != nil {
 		return err
 	}
 
 	// Update play numbers for plays with higher numbers in the same point
-	_, err = tx.Exec(`UPDATE plays SET play_number = play_number - 1 WHERE point_id = $1 AND play_number > $2`,
-		play.PointID, play.PlayNumber)
+	_, err = tx.Exec(`UPDATE plays SET play_number = play_number - 1, version = version + 1 WHERE point_id = $1 AND play_number > $2`,
+		pointID, playNumber)
 	if err != nil {
 		return err
 	}
@@ -108,7 +196,9 @@ func DeletePlay(db *database.DB, playID int) error {
 	return tx.Commit()
 }
 
-func UpdatePlay(db *database.DB, play *playmodel.Play) error {
+// UpdatePlay saves play if it is still at play.Version, bumping the version.
+// It returns database.ErrConflict when the play was changed in the meantime.
+func UpdatePlay(db database.Querier, play *playmodel.Play) error {
 	query := `UPDATE plays SET 
 				player_id = $1, 
 				ball_position_x = $2, 
@@ -117,9 +207,13 @@ func UpdatePlay(db *database.DB, play *playmodel.Play) error {
 				hand_side = $5, 
 				contact_type = $6, 
 				shot_effect = $7, 
+				wall_bounces = COALESCE($8::TEXT[], '{}'), 
+				exit_court = $9, 
+				version = version + 1, 
 				updated_at = CURRENT_TIMESTAMP
-			  WHERE id = $8`
-	_, err := db.Exec(query, 
+			  WHERE id = $10 AND version = $11
+			  RETURNING version, updated_at`
+	err := db.QueryRow(query, 
 		play.PlayerID, 
 		play.BallPositionX, 
 		play.BallPositionY, 
@@ -127,13 +221,62 @@ func UpdatePlay(db *database.DB, play *playmodel.Play) error {
 		play.HandSide, 
 		play.ContactType, 
 		play.ShotEffect, 
+		pq.Array(play.WallBounces),
+		play.ExitCourt,
 		play.ID,
-	)
+		play.Version,
+	).Scan(&play.Version, &play.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return database.ErrConflict
+	}
 	return err
 }
 
-func DeleteSubsequentPlays(db *database.DB, pointID int, playNumber int) error {
+// DeleteSubsequentPlays deletes the plays of the point that come after
+// playNumber.
+func DeleteSubsequentPlays(db database.Querier, pointID int, playNumber int) error {
 	query := `DELETE FROM plays WHERE point_id = $1 AND play_number > $2`
 	_, err := db.Exec(query, pointID, playNumber)
 	return err
-}
\ No newline at end of file
+}
+
+// GetLastPlayNumber returns the number of the last play of the point, or 0
+// when it has none.
+func GetLastPlayNumber(db database.Querier, pointID int) (int, error) {
+	var playNumber int
+	err := db.QueryRow(`SELECT COALESCE(MAX(play_number), 0) FROM plays WHERE point_id = $1`, pointID).Scan(&playNumber)
+	return playNumber, err
+}
+
+// GetPlayIDByNumber returns the id of the play with playNumber in the point,
+// or 0 when there is none.
+func GetPlayIDByNumber(db database.Querier, pointID int, playNumber int) (int, error) {
+	var playID int
+	err := db.QueryRow(`SELECT id FROM plays WHERE point_id = $1 AND play_number = $2`, pointID, playNumber).Scan(&playID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return playID, err
+}
+
+// GetGameServer returns the player who hit the first shot of the earliest
+// other point of the point's game, which is the game's server. It is NULL
+// when no other point of the game has a first shot with a player, and for a
+// tiebreak, where serve changes hands during the game.
+func GetGameServer(db database.Querier, pointID int) (sql.NullInt64, error) {
+	query := `SELECT pl.player_id
+			  FROM plays pl
+			  JOIN points p ON p.id = pl.point_id
+			  JOIN games g ON g.id = p.game_id
+			  WHERE p.game_id = (SELECT game_id FROM points WHERE id = $1)
+			    AND g.game_number <> $2
+			    AND p.id <> $1 AND pl.play_number = 1 AND pl.player_id IS NOT NULL
+			  ORDER BY p.point_number
+			  LIMIT 1`
+	var server sql.NullInt64
+	err := db.QueryRow(query, pointID, scoring.DefaultRules.TiebreakGame()).Scan(&server)
+	if err == sql.ErrNoRows {
+		return sql.NullInt64{}, nil
+	}
+	return server, err
+}
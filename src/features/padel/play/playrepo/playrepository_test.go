@@ -0,0 +1,64 @@
+package playrepo
+
+import (
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/infrastructure/database/databasetest"
+	"slices"
+	"testing"
+)
+
+const concurrentCreates = 20
+
+func TestCreatePlayConcurrently(t *testing.T) {
+	db := databasetest.Open(t)
+	pointID := databasetest.Point(t, db)
+
+	databasetest.Concurrently(t, concurrentCreates, func() error {
+		return CreatePlay(db, &playmodel.Play{PointID: pointID})
+	})
+
+	plays, err := GetPlaysByPoint(db, pointID)
+	if err != nil {
+		t.Fatalf("GetPlaysByPoint: %v", err)
+	}
+	if len(plays) != concurrentCreates {
+		t.Fatalf("got %d plays, want %d", len(plays), concurrentCreates)
+	}
+	numbers := make([]int, len(plays))
+	for i, play := range plays {
+		numbers[i] = play.PlayNumber
+	}
+	databasetest.Numbered(t, numbers)
+}
+
+func TestDeletePlayRenumbers(t *testing.T) {
+	db := databasetest.Open(t)
+	pointID := databasetest.Point(t, db)
+
+	var ids []int
+	for range 5 {
+		play := playmodel.Play{PointID: pointID}
+		if err := CreatePlay(db, &play); err != nil {
+			t.Fatalf("CreatePlay: %v", err)
+		}
+		ids = append(ids, play.ID)
+	}
+
+	if err := DeletePlay(db, ids[2]); err != nil {
+		t.Fatalf("DeletePlay: %v", err)
+	}
+
+	plays, err := GetPlaysByPoint(db, pointID)
+	if err != nil {
+		t.Fatalf("GetPlaysByPoint: %v", err)
+	}
+	want := slices.Delete(slices.Clone(ids), 2, 3)
+	if len(plays) != len(want) {
+		t.Fatalf("got %d plays, want %d", len(plays), len(want))
+	}
+	for i, play := range plays {
+		if play.ID != want[i] || play.PlayNumber != i+1 {
+			t.Errorf("play %d: id %d number %d, want id %d number %d", i, play.ID, play.PlayNumber, want[i], i+1)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package playservice is the one place plays are edited, so the editor's
+// autosave and committing a shot apply the same rally rules: only the last
+// play of a point may carry a result, a result ends the rally, and the
+// rally checks warn about or refuse edits as the match is configured.
+package playservice
+
+import (
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/play/playrepo"
+	"ct-padel-s/src/features/padel/play/playvalidation"
+	"ct-padel-s/src/features/padel/play/rallycheck"
+	"ct-padel-s/src/features/padel/shot/shotrepo"
+	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrPlayNotFound = errors.New("play not found")
+
+// Result describes a saved edit and what a commit did to the rally.
+type Result struct {
+	// Play is the play as saved
+	Play *playmodel.Play
+	// Warnings are the rally checks the saved play fails, by form field
+	Warnings map[string]string
+	// PointEnded is set when the play carries a result; any plays that
+	// followed it were removed
+	PointEnded bool
+	// NextPlayID is the play that follows when the rally continues
+	NextPlayID int
+	// Created is set when the next play was created by the commit
+	Created bool
+}
+
+// Save applies input to the play if it is still at expectedVersion. A
+// result may only be set on the last play of the point; to end the rally at
+// an earlier play, commit it instead. Invalid input, and in a match that
+// enforces the rally checks an edit that breaks a new one, is reported as
+// playvalidation.Errors; a stale version is database.ErrConflict.
+func Save(db *database.DB, match *matchmodel.MatchWithPlayers, playID, expectedVersion int, input playvalidation.Input) (*Result, error) {
+	return mutate(db, match, playID, expectedVersion, input, false)
+}
+
+// Commit applies input to the play like Save, requiring the player and ball
+// position, then advances the rally. A play with a result ends the point
+// and removes the plays after it; otherwise the rally moves on to the next
+// play, which is created when this was the last one.
+func Commit(db *database.DB, match *matchmodel.MatchWithPlayers, playID, expectedVersion int, input playvalidation.Input) (*Result, error) {
+	return mutate(db, match, playID, expectedVersion, input, true)
+}
+
+func mutate(db *database.DB, match *matchmodel.MatchWithPlayers, playID, expectedVersion int, input playvalidation.Input, commit bool) (*Result, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Read the play under the point's lock so the rally can't change
+	// between checking it and saving
+	play, err := playrepo.GetPlay(tx, playID)
+	if err != nil {
+		return nil, err
+	}
+	if play == nil {
+		return nil, ErrPlayNotFound
+	}
+	if err := playrepo.LockPoint(tx, play.PointID); err != nil {
+		return nil, err
+	}
+	play, err = playrepo.GetPlay(tx, playID)
+	if err != nil {
+		return nil, err
+	}
+	if play == nil {
+		return nil, ErrPlayNotFound
+	}
+	if play.Version != expectedVersion {
+		return nil, database.ErrConflict
+	}
+
+	catalogue, err := shotrepo.GetCatalogue(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *play
+	if errs := playvalidation.Apply(&updated, input, match, catalogue, commit); errs != nil {
+		return nil, errs
+	}
+
+	lastPlayNumber, err := playrepo.GetLastPlayNumber(tx, play.PointID)
+	if err != nil {
+		return nil, err
+	}
+	isLast := updated.PlayNumber == lastPlayNumber
+	if updated.EndsPoint() && !isLast && !commit {
+		return nil, playvalidation.Errors{"result_type": "Only the last play of a point can end it. Commit the shot to remove the plays after it."}
+	}
+
+	plays, err := playrepo.GetPlaysByPoint(tx, play.PointID)
+	if err != nil {
+		return nil, err
+	}
+	server, err := playrepo.GetGameServer(tx, play.PointID)
+	if err != nil {
+		return nil, err
+	}
+	// Check the rally as stored and with the edit in place of the play
+	edited := make([]*playmodel.Play, len(plays))
+	for i, p := range plays {
+		edited[i] = p
+		if p.ID == updated.ID {
+			edited[i] = &updated
+		}
+	}
+	before := rallycheck.ForPlay(rallycheck.Check(match, plays, server, catalogue), play.PlayNumber)
+	after := rallycheck.ForPlay(rallycheck.Check(match, edited, server, catalogue), updated.PlayNumber)
+	if match.RallyChecks == matchmodel.RallyChecksEnforce {
+		// Only refuse what the edit breaks, so a rally entered before the
+		// checks were enforced can still be fixed one field at a time
+		refused := playvalidation.Errors{}
+		for field, message := range after {
+			if before[field] != message {
+				refused[field] = message
+			}
+		}
+		if len(refused) > 0 {
+			return nil, refused
+		}
+	}
+
+	if err := playrepo.UpdatePlay(tx, &updated); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Play: &updated, Warnings: after}
+	if commit {
+		if updated.EndsPoint() {
+			result.PointEnded = true
+			if err := playrepo.DeleteSubsequentPlays(tx, updated.PointID, updated.PlayNumber); err != nil {
+				return nil, err
+			}
+		} else if isLast {
+			next := playmodel.Play{PointID: updated.PointID}
+			// A rally recorded live stays live: the next shot is hit now
+			if updated.HitAt.Valid {
+				next.HitAt = sql.NullTime{Time: time.Now(), Valid: true}
+			}
+			if err := playrepo.InsertPlay(tx, &next); err != nil {
+				return nil, err
+			}
+			result.NextPlayID = next.ID
+			result.Created = true
+		} else {
+			result.NextPlayID, err = playrepo.GetPlayIDByNumber(tx, updated.PointID, updated.PlayNumber+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Issues returns the rally checks failed by the plays of the point.
+func Issues(db database.Querier, match *matchmodel.MatchWithPlayers, pointID int) ([]rallycheck.Issue, error) {
+	plays, err := playrepo.GetPlaysByPoint(db, pointID)
+	if err != nil {
+		return nil, err
+	}
+	server, err := playrepo.GetGameServer(db, pointID)
+	if err != nil {
+		return nil, err
+	}
+	catalogue, err := shotrepo.GetCatalogue(db)
+	if err != nil {
+		return nil, err
+	}
+	return rallycheck.Check(match, plays, server, catalogue), nil
+}
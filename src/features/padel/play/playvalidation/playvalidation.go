@@ -0,0 +1,177 @@
+// Package playvalidation checks edits to a play before they reach the
+// database, so bad values are reported per field instead of surfacing as a
+// CHECK constraint failure.
+package playvalidation
+
+import (
+	"ct-padel-s/src/features/padel/court"
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/shot/shotmodel"
+	"database/sql"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Errors maps form field names to what is wrong with them.
+type Errors map[string]string
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field + ": " + e[field]
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Input holds the raw form values of a play's editable attributes.
+type Input struct {
+	PlayerID      string
+	BallPositionX string
+	BallPositionY string
+	ResultType    string
+	HandSide      string
+	ContactType   string
+	ShotEffect    string
+	// WallBounces is the walls the ball hit, comma separated in order
+	WallBounces string
+	ExitCourt   string
+}
+
+func FromForm(r *http.Request) Input {
+	return Input{
+		PlayerID:      r.FormValue("player_id"),
+		BallPositionX: r.FormValue("ball_position_x"),
+		BallPositionY: r.FormValue("ball_position_y"),
+		ResultType:    r.FormValue("result_type"),
+		HandSide:      r.FormValue("hand_side"),
+		ContactType:   r.FormValue("contact_type"),
+		ShotEffect:    r.FormValue("shot_effect"),
+		WallBounces:   r.FormValue("wall_bounces"),
+		ExitCourt:     r.FormValue("exit_court"),
+	}
+}
+
+// Apply validates input and, when it is valid, applies it to play. The
+// player must be one of the match's four players, and the contact type and
+// shot effect active entries of the catalogue; a retired entry the play
+// already has may be kept. With required set the player and ball position
+// must be given; otherwise missing ones keep their current values. Empty
+// shot attributes clear them. On failure play is left unchanged.
+func Apply(play *playmodel.Play, input Input, match *matchmodel.MatchWithPlayers, catalogue *shotmodel.Catalogue, required bool) Errors {
+	errs := Errors{}
+	updated := *play
+
+	if input.PlayerID == "" {
+		if required {
+			errs["player_id"] = "Choose the player who hit the shot"
+		}
+	} else if playerID, err := strconv.ParseInt(input.PlayerID, 10, 64); err != nil {
+		errs["player_id"] = "Player ID must be a number"
+	} else if !inMatch(match, playerID) {
+		errs["player_id"] = "Player is not playing in this match"
+	} else {
+		updated.PlayerID = sql.NullInt64{Int64: playerID, Valid: true}
+	}
+
+	position := func(field, value string, max int, target *int) {
+		if value == "" {
+			if required {
+				errs[field] = "Position is required"
+			}
+			return
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > max {
+			errs[field] = "Position must be between 0 and " + strconv.Itoa(max)
+			return
+		}
+		*target = n
+	}
+	position("ball_position_x", input.BallPositionX, playmodel.MaxPositionX, &updated.BallPositionX)
+	position("ball_position_y", input.BallPositionY, playmodel.MaxPositionY, &updated.BallPositionY)
+
+	// "Return" is how older forms said the rally continues
+	result := input.ResultType
+	if result == "Return" {
+		result = ""
+	}
+	updated.ResultType = attribute(errs, "result_type", result, playmodel.ResultType(result).Valid())
+	updated.HandSide = attribute(errs, "hand_side", input.HandSide, playmodel.HandSide(input.HandSide).Valid())
+	contact := catalogue.ContactType(input.ContactType)
+	updated.ContactType = catalogued(errs, "contact_type", input.ContactType, play.ContactType, contact != nil, contact != nil && contact.Active)
+	effect := catalogue.Effect(input.ShotEffect)
+	updated.ShotEffect = catalogued(errs, "shot_effect", input.ShotEffect, play.ShotEffect, effect != nil, effect != nil && effect.Active)
+	updated.ExitCourt = attribute(errs, "exit_court", input.ExitCourt, playmodel.ExitCourt(input.ExitCourt).Valid())
+	if walls, message := wallBounces(input.WallBounces); message != "" {
+		errs["wall_bounces"] = message
+	} else {
+		updated.WallBounces = walls
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	*play = updated
+	return nil
+}
+
+func attribute(errs Errors, field, value string, valid bool) sql.NullString {
+	if value == "" {
+		return sql.NullString{}
+	}
+	if !valid {
+		errs[field] = "Unknown value " + strconv.Quote(value)
+		return sql.NullString{}
+	}
+	return sql.NullString{String: value, Valid: true}
+}
+
+// catalogued checks a value against the shot catalogue: it must be listed,
+// and active unless the play already has it.
+func catalogued(errs Errors, field, value string, current sql.NullString, listed, active bool) sql.NullString {
+	if value != "" && listed && !active && value != current.String {
+		errs[field] = strconv.Quote(value) + " has been retired from the shot catalogue"
+		return sql.NullString{}
+	}
+	return attribute(errs, field, value, listed)
+}
+
+// wallBounces parses a comma separated wall sequence. An empty value is no
+// walls.
+func wallBounces(value string) ([]string, string) {
+	walls := []string{}
+	if value == "" {
+		return walls, ""
+	}
+	for i, wall := range strings.Split(value, ",") {
+		if !court.Wall(wall).Valid() {
+			return nil, "Unknown wall " + strconv.Quote(wall)
+		}
+		// The ball leaves a wall heading away from it
+		if i > 0 && walls[i-1] == wall {
+			return nil, "The ball cannot hit the same wall twice in a row"
+		}
+		walls = append(walls, wall)
+	}
+	if len(walls) > playmodel.MaxWallBounces {
+		return nil, "Record at most " + strconv.Itoa(playmodel.MaxWallBounces) + " walls"
+	}
+	return walls, ""
+}
+
+func inMatch(match *matchmodel.MatchWithPlayers, playerID int64) bool {
+	switch int(playerID) {
+	case match.Team1Player1ID, match.Team1Player2ID, match.Team2Player1ID, match.Team2Player2ID:
+		return true
+	}
+	return false
+}
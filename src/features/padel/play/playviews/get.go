@@ -6,6 +6,7 @@ import (
 	"ct-padel-s/src/features/padel/play/playmodel"
 	"ct-padel-s/src/features/padel/point/pointmodel"
 	"ct-padel-s/src/features/padel/set/setmodel"
+	"ct-padel-s/src/features/padel/shot/shotmodel"
 	"ct-padel-s/src/shared/utils"
 	_ "embed"
 	"html/template"
@@ -15,11 +16,54 @@ import (
 var getHTML string
 var getComponent = utils.NewComponent("get.html", getHTML)
 
+// EditorID is the id of the play editor element, used to swap in a fresh
+// copy when an edit conflicts.
+const EditorID = "play-editor"
+
+// RenderGet renders the editor for play along with the rally checks it
+// fails, keyed by form field. The shot choices come from catalogue.
 func RenderGet(play *playmodel.Play,
 	point *pointmodel.Point,
 	game *gamemodel.Game,
 	set *setmodel.Set,
 	match *matchmodel.MatchWithPlayers,
+	catalogue *shotmodel.Catalogue,
+	warnings map[string]string,
 ) (template.HTML, error) {
-	return getComponent.Render(map[string]any{"Play": play, "Point": point, "Game": game, "Set": set, "Match": match})
+	warningsHTML, err := RenderWarnings(warnings, false)
+	if err != nil {
+		return "", err
+	}
+	return getComponent.Render(map[string]any{"Play": play, "Point": point, "Game": game, "Set": set, "Match": match, "ContactTypes": catalogue.ContactTypeChoices(play.ContactType.String), "Effects": catalogue.EffectChoices(play.ShotEffect.String), "EditorID": EditorID, "WarningsID": WarningsID, "Warnings": warningsHTML})
+}
+
+// RenderConflict renders the editor for the current state of play along with
+// a notice that the user's last change was rejected.
+func RenderConflict(play *playmodel.Play,
+	point *pointmodel.Point,
+	game *gamemodel.Game,
+	set *setmodel.Set,
+	match *matchmodel.MatchWithPlayers,
+	catalogue *shotmodel.Catalogue,
+) (template.HTML, error) {
+	return getComponent.Render(map[string]any{"Play": play, "Point": point, "Game": game, "Set": set, "Match": match, "ContactTypes": catalogue.ContactTypeChoices(play.ContactType.String), "Effects": catalogue.EffectChoices(play.ShotEffect.String), "EditorID": EditorID, "WarningsID": WarningsID, "Conflict": true, "Warnings": emptyWarnings()})
+}
+
+// RenderInvalid renders the editor for the stored state of play with a
+// message against each field the rejected edit got wrong.
+func RenderInvalid(play *playmodel.Play,
+	point *pointmodel.Point,
+	game *gamemodel.Game,
+	set *setmodel.Set,
+	match *matchmodel.MatchWithPlayers,
+	catalogue *shotmodel.Catalogue,
+	errors map[string]string,
+) (template.HTML, error) {
+	return getComponent.Render(map[string]any{"Play": play, "Point": point, "Game": game, "Set": set, "Match": match, "ContactTypes": catalogue.ContactTypeChoices(play.ContactType.String), "Effects": catalogue.EffectChoices(play.ShotEffect.String), "EditorID": EditorID, "WarningsID": WarningsID, "Errors": errors, "Warnings": emptyWarnings()})
+}
+
+// emptyWarnings keeps the warnings element in an editor rendered without
+// them, so the next autosave has somewhere to swap its list.
+func emptyWarnings() template.HTML {
+	return warningsComponent.MustRender(map[string]any{"ID": WarningsID})
 }
@@ -15,6 +15,8 @@ import (
 var playlistHTML string
 var playlistComponent = utils.NewComponent("playlist.html", playlistHTML)
 
-func RenderPlayList(plays []*playmodel.Play, point *pointmodel.Point, game *gamemodel.Game, set *setmodel.Set, match *matchmodel.MatchWithPlayers) (template.HTML, error) {
-	return playlistComponent.Render(map[string]any{"Plays": plays, "Point": point, "Game": game, "Set": set, "Match": match})
+// RenderPlayList renders the plays of a point, each with the rally checks it
+// fails keyed by play number.
+func RenderPlayList(plays []*playmodel.Play, point *pointmodel.Point, game *gamemodel.Game, set *setmodel.Set, match *matchmodel.MatchWithPlayers, issues map[int][]string) (template.HTML, error) {
+	return playlistComponent.Render(map[string]any{"Plays": plays, "Point": point, "Game": game, "Set": set, "Match": match, "Issues": issues})
 }
\ No newline at end of file
@@ -0,0 +1,32 @@
+package playviews
+
+import (
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+	"sort"
+)
+
+//go:embed warnings.html
+var warningsHTML string
+var warningsComponent = utils.NewComponent("warnings.html", warningsHTML)
+
+// WarningsID is the id of the element listing the rally checks the play
+// fails, so an autosave can swap in a fresh list.
+const WarningsID = "rally-warnings"
+
+// RenderWarnings renders the rally checks the play fails, keyed by form
+// field. With oob set the list is marked for an htmx out-of-band swap.
+func RenderWarnings(warnings map[string]string, oob bool) (template.HTML, error) {
+	fields := make([]string, 0, len(warnings))
+	for field := range warnings {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = warnings[field]
+	}
+	return warningsComponent.Render(map[string]any{"ID": WarningsID, "OOB": oob, "Warnings": messages})
+}
@@ -0,0 +1,146 @@
+// Package rallycheck checks that the plays of a point make a legal rally:
+// the game's server serves first from behind the service line, nobody else
+// serves, a serve off the fence is called a fault, the teams take turns, a
+// ball that came off a wall is played near it and shot effects tied to a
+// contact type in the shot catalogue, like a smash to an overhead, are
+// played with it. Attributes that are not recorded yet are not checked.
+package rallycheck
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"ct-padel-s/src/features/padel/court"
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/shot/shotmodel"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Issue is a rally rule broken by a play.
+type Issue struct {
+	PlayNumber int
+	// Field is the form field of the play the issue is about
+	Field   string
+	Message string
+}
+
+// Check returns the rule breaks in plays, the plays of one point in
+// play_number order, sorted by play. server is the player serving the game,
+// if known.
+func Check(match *matchmodel.MatchWithPlayers, plays []*playmodel.Play, server sql.NullInt64, catalogue *shotmodel.Catalogue) []Issue {
+	var issues []Issue
+	for i, play := range plays {
+		contact := playmodel.ContactType(play.ContactType.String)
+		if i == 0 {
+			if play.ContactType.Valid && contact != playmodel.ContactServe {
+				issues = append(issues, Issue{play.PlayNumber, "contact_type", "A rally starts with a serve."})
+			}
+			if server.Valid && play.PlayerID.Valid && play.PlayerID.Int64 != server.Int64 {
+				issues = append(issues, Issue{play.PlayNumber, "player_id", fmt.Sprintf("%s serves this game.", playerName(match, server.Int64))})
+			}
+			if contact == playmodel.ContactServe && !court.BehindServiceLine(play.BallPositionY) {
+				issues = append(issues, Issue{play.PlayNumber, "ball_position_y", "A serve is hit from behind the service line."})
+			}
+			if contact == playmodel.ContactServe && len(play.WallBounces) > 0 && court.Wall(play.WallBounces[0]) == court.WallFence && !isError(play) {
+				issues = append(issues, Issue{play.PlayNumber, "result_type", "A serve that hits the fence is a fault."})
+			}
+		} else if contact == playmodel.ContactServe {
+			issues = append(issues, Issue{play.PlayNumber, "contact_type", "Only the first shot of a rally is a serve."})
+		}
+
+		if effect := catalogue.Effect(play.ShotEffect.String); effect != nil && effect.ContactType.Valid && play.ContactType.Valid && play.ContactType.String != effect.ContactType.String {
+			message := fmt.Sprintf("A %s is played as %s.", strings.ToLower(effect.Label), article(strings.ToLower(catalogue.ContactLabel(effect.ContactType.String))))
+			issues = append(issues, Issue{play.PlayNumber, "shot_effect", message})
+		}
+
+		if i > 0 {
+			previous := plays[i-1]
+			if previous.PlayerID.Valid && play.PlayerID.Valid {
+				team := analyticsmodel.TeamOf(match, play.PlayerID.Int64)
+				if team != analyticsmodel.TeamUnknown && team == analyticsmodel.TeamOf(match, previous.PlayerID.Int64) {
+					issues = append(issues,
+						Issue{previous.PlayNumber, "player_id", "The next shot is by the same team; teams take turns."},
+						Issue{play.PlayNumber, "player_id", "The previous shot is by the same team; teams take turns."},
+					)
+				}
+			}
+			if len(previous.WallBounces) > 0 && positioned(play) {
+				wall := court.Wall(previous.WallBounces[len(previous.WallBounces)-1])
+				if court.WallDistance(play.BallPositionX, play.BallPositionY, wall) > court.WallReach {
+					name := strings.ReplaceAll(string(wall), "_", " ")
+					issues = append(issues,
+						Issue{previous.PlayNumber, "wall_bounces", fmt.Sprintf("The next shot is played more than %d m from the %s.", court.WallReach/1000, name)},
+						Issue{play.PlayNumber, "ball_position_y", fmt.Sprintf("The previous shot came off the %s, more than %d m away.", name, court.WallReach/1000)},
+					)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].PlayNumber < issues[j].PlayNumber })
+	return issues
+}
+
+// positioned reports whether the ball position has been recorded. A new
+// play sits at the corner (0, 0) until it is.
+func positioned(play *playmodel.Play) bool {
+	return play.BallPositionX != 0 || play.BallPositionY != 0
+}
+
+func isError(play *playmodel.Play) bool {
+	result := playmodel.ResultType(play.ResultType.String)
+	return result == playmodel.ResultError || result == playmodel.ResultUnforcedError
+}
+
+// ForPlay returns the issues about the play with playNumber as one message
+// per field.
+func ForPlay(issues []Issue, playNumber int) map[string]string {
+	fields := map[string]string{}
+	for _, issue := range issues {
+		if issue.PlayNumber != playNumber {
+			continue
+		}
+		if message, ok := fields[issue.Field]; ok {
+			fields[issue.Field] = message + " " + issue.Message
+		} else {
+			fields[issue.Field] = issue.Message
+		}
+	}
+	return fields
+}
+
+// ByPlay groups the issue messages by play number.
+func ByPlay(issues []Issue) map[int][]string {
+	messages := map[int][]string{}
+	for _, issue := range issues {
+		messages[issue.PlayNumber] = append(messages[issue.PlayNumber], issue.Message)
+	}
+	return messages
+}
+
+func playerName(match *matchmodel.MatchWithPlayers, playerID int64) string {
+	for _, player := range []struct {
+		id   int
+		name string
+	}{
+		{match.Team1Player1ID, match.Team1Player1.Name},
+		{match.Team1Player2ID, match.Team1Player2.Name},
+		{match.Team2Player1ID, match.Team2Player1.Name},
+		{match.Team2Player2ID, match.Team2Player2.Name},
+	} {
+		if int64(player.id) == playerID {
+			return player.name
+		}
+	}
+	return "Another player"
+}
+
+// article puts "a" or "an" before word, going by its first letter.
+func article(word string) string {
+	if word != "" && strings.ContainsRune("aeiou", rune(word[0])) {
+		return "an " + word
+	}
+	return "a " + word
+}
@@ -0,0 +1,192 @@
+package player
+
+import (
+	"ct-padel-s/src/features/padel/player/playerrepo"
+	"ct-padel-s/src/features/padel/player/playershared"
+	"ct-padel-s/src/features/padel/player/playerviews"
+	"ct-padel-s/src/features/padel/rating/ratingrepo"
+	"ct-padel-s/src/features/padel/rating/ratingviews"
+	"ct-padel-s/src/features/padel/shot/shotrepo"
+	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/shared/components/footer"
+	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
+	"ct-padel-s/src/shared/templates"
+	"io"
+	"net/http"
+)
+
+func GetAll(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	players, err := playerrepo.GetAllPlayers(db)
+	if err != nil {
+		logger.Error("Failed to get players", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get players")
+		return
+	}
+
+	breadcrumb, err := playerviews.RenderGetAllBreadcrumb()
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load shared components
+	headerHTML, err := header.Render(header.Data{Title: "Players - Padel Tracker", Breadcrumb: breadcrumb})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	footerHTML, err := footer.Render(footer.Data{})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load feature content and render with data
+	contentHTML, err := playerviews.RenderGetAll(players)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Compose final page
+	page, err := templates.Render(templates.Data{
+		Title:       "Players - Padel Tracker",
+		HeaderHTML:  headerHTML,
+		ContentHTML: contentHTML,
+		FooterHTML:  footerHTML,
+	})
+
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	io.WriteString(w, string(page))
+}
+
+// Get renders a player's career profile, optionally limited to matches
+// between the from and to query parameters (inclusive, YYYY-MM-DD).
+func Get(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	playerID := playershared.GetPlayerID(w, r)
+	if playerID == 0 {
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid player ID")
+		return
+	}
+
+	player, err := playerrepo.GetPlayer(db, playerID)
+	if err != nil {
+		logger.Error("Failed to get player", "error", err, "playerID", playerID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get player")
+		return
+	}
+	if player == nil {
+		httperror.NotFound(w, r, "Player not found")
+		return
+	}
+
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	dateRange, err := playershared.ParseDateRange(from, to)
+	if err != nil {
+		logger.Warn("Invalid date range", "error", err, "from", from, "to", to)
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid date range, expected YYYY-MM-DD")
+		return
+	}
+
+	stats, err := playerrepo.GetCareerStats(db, playerID, dateRange)
+	if err != nil {
+		logger.Error("Failed to get career stats", "error", err, "playerID", playerID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get career stats")
+		return
+	}
+
+	mix, err := playerrepo.GetShotMix(db, playerID, dateRange)
+	if err != nil {
+		logger.Error("Failed to get shot mix", "error", err, "playerID", playerID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get shot mix")
+		return
+	}
+
+	partners, err := playerrepo.GetPartnerSplits(db, playerID, dateRange)
+	if err != nil {
+		logger.Error("Failed to get partner splits", "error", err, "playerID", playerID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get partner splits")
+		return
+	}
+
+	history, err := ratingrepo.GetPlayerHistory(db, playerID)
+	if err != nil {
+		logger.Error("Failed to get rating history", "error", err, "playerID", playerID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get rating history")
+		return
+	}
+
+	catalogue, err := shotrepo.GetCatalogue(db)
+	if err != nil {
+		logger.Error("Failed to get shot catalogue", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get shot catalogue")
+		return
+	}
+
+	ratingChartHTML, err := ratingviews.RenderChart(history)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Load shared components
+	title := "Player: " + player.Name
+
+	breadcrumb, err := playerviews.RenderGetBreadcrumb(player)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	headerHTML, err := header.Render(header.Data{Title: title + " - Padel Tracker", Breadcrumb: breadcrumb})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	footerHTML, err := footer.Render(footer.Data{})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load feature content and render with data
+	contentHTML, err := playerviews.RenderGet(player, from, to, stats, mix, partners, ratingChartHTML, catalogue)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Compose final page
+	page, err := templates.Render(templates.Data{
+		Title:       title + " - Padel Tracker",
+		HeaderHTML:  headerHTML,
+		ContentHTML: contentHTML,
+		FooterHTML:  footerHTML,
+	})
+
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	io.WriteString(w, string(page))
+}
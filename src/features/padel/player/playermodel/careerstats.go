@@ -0,0 +1,81 @@
+package playermodel
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DateRange limits career statistics to matches played in [From, To).
+// Unset bounds are open.
+type DateRange struct {
+	From sql.NullTime
+	To   sql.NullTime
+}
+
+// Record is a win/loss count. Matches without a decided winner count as
+// played but neither won nor lost.
+type Record struct {
+	Matches int `json:"matches"`
+	Won     int `json:"won"`
+	Lost    int `json:"lost"`
+}
+
+// CareerStats aggregates a player's results across matches.
+type CareerStats struct {
+	Record
+	PointsPlayed   int `json:"points_played"`
+	PointsWon      int `json:"points_won"`
+	SetsPlayed     int `json:"sets_played"`
+	Winners        int `json:"winners"`
+	Errors         int `json:"errors"`
+	UnforcedErrors int `json:"unforced_errors"`
+}
+
+func (s CareerStats) PointsWonPercent() float64 {
+	return percent(s.PointsWon, s.PointsPlayed)
+}
+
+func (s CareerStats) WinnersPerSet() float64 {
+	return perSet(s.Winners, s.SetsPlayed)
+}
+
+func (s CareerStats) ErrorsPerSet() float64 {
+	return perSet(s.Errors, s.SetsPlayed)
+}
+
+func (s CareerStats) UnforcedErrorsPerSet() float64 {
+	return perSet(s.UnforcedErrors, s.SetsPlayed)
+}
+
+// ShotMix counts a player's shots of one contact type in one month.
+type ShotMix struct {
+	Month       time.Time `json:"month"`
+	ContactType string    `json:"contact_type"`
+	Count       int       `json:"count"`
+}
+
+// PartnerSplit is a player's record alongside one partner.
+type PartnerSplit struct {
+	Partner Player `json:"partner"`
+	Record
+	PointsPlayed int `json:"points_played"`
+	PointsWon    int `json:"points_won"`
+}
+
+func (s PartnerSplit) PointsWonPercent() float64 {
+	return percent(s.PointsWon, s.PointsPlayed)
+}
+
+func percent(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(whole)
+}
+
+func perSet(count, sets int) float64 {
+	if sets == 0 {
+		return 0
+	}
+	return float64(count) / float64(sets)
+}
@@ -0,0 +1,142 @@
+package playerrepo
+
+import (
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"ct-padel-s/src/infrastructure/database"
+)
+
+// playerMatchesCTE selects the matches player $1 took part in between $2 and
+// $3, with the player's team (1 or 2) and partner.
+const playerMatchesCTE = `player_matches AS (
+	SELECT m.id, m.match_date, m.team1_player1_id, m.team1_player2_id, m.team2_player1_id, m.team2_player2_id,
+		CASE WHEN $1 IN (m.team1_player1_id, m.team1_player2_id) THEN 1 ELSE 2 END AS team,
+		CASE $1
+			WHEN m.team1_player1_id THEN m.team1_player2_id
+			WHEN m.team1_player2_id THEN m.team1_player1_id
+			WHEN m.team2_player1_id THEN m.team2_player2_id
+			ELSE m.team2_player1_id
+		END AS partner_id
+	FROM matches m
+	WHERE $1 IN (m.team1_player1_id, m.team1_player2_id, m.team2_player1_id, m.team2_player2_id)
+	  AND ($2::timestamp IS NULL OR m.match_date >= $2::timestamp)
+	  AND ($3::timestamp IS NULL OR m.match_date < $3::timestamp)
+)`
+
+// GetCareerStats aggregates the player's record, points won, sets played and
+// shot results over the matches in dateRange.
+func GetCareerStats(db *database.DB, playerID int, dateRange playermodel.DateRange) (*playermodel.CareerStats, error) {
+	stats := &playermodel.CareerStats{}
+	query := `WITH ` + playerMatchesCTE + `, ` + matchrepo.ResultsCTE("player_matches") + `
+			  SELECT mr.matches, mr.won, mr.lost, mr.points_played, mr.points_won, st.sets, sh.winners, sh.errors, sh.unforced_errors
+			  FROM (
+				  SELECT COUNT(*) AS matches,
+					  COUNT(*) FILTER (WHERE mr.winner_team = pm.team) AS won,
+					  COUNT(*) FILTER (WHERE mr.winner_team = 3 - pm.team) AS lost,
+					  COALESCE(SUM(mr.team1_points + mr.team2_points), 0) AS points_played,
+					  COALESCE(SUM(CASE WHEN pm.team = 1 THEN mr.team1_points ELSE mr.team2_points END), 0) AS points_won
+				  FROM match_results mr
+				  JOIN player_matches pm ON pm.id = mr.match_id
+			  ) mr,
+			  (
+				  SELECT COUNT(*) AS sets
+				  FROM sets s
+				  JOIN player_matches pm ON pm.id = s.match_id
+			  ) st,
+			  (
+				  SELECT COUNT(*) FILTER (WHERE pl.result_type = 'no_return_winner') AS winners,
+					  COUNT(*) FILTER (WHERE pl.result_type = 'error') AS errors,
+					  COUNT(*) FILTER (WHERE pl.result_type = 'unforced_error') AS unforced_errors
+				  FROM player_matches pm
+				  JOIN sets s ON s.match_id = pm.id
+				  JOIN games g ON g.set_id = s.id
+				  JOIN points pt ON pt.game_id = g.id
+				  JOIN plays pl ON pl.point_id = pt.id
+				  WHERE pl.player_id = $1
+			  ) sh`
+	err := db.QueryRow(query, playerID, dateRange.From, dateRange.To).Scan(
+		&stats.Matches,
+		&stats.Won,
+		&stats.Lost,
+		&stats.PointsPlayed,
+		&stats.PointsWon,
+		&stats.SetsPlayed,
+		&stats.Winners,
+		&stats.Errors,
+		&stats.UnforcedErrors)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetShotMix counts the player's shots by contact type per calendar month of
+// match date. Shots without a contact type are reported as "unknown".
+func GetShotMix(db *database.DB, playerID int, dateRange playermodel.DateRange) ([]playermodel.ShotMix, error) {
+	query := `WITH ` + playerMatchesCTE + `
+			  SELECT date_trunc('month', pm.match_date) AS month, COALESCE(pl.contact_type, 'unknown') AS contact_type, COUNT(*)
+			  FROM player_matches pm
+			  JOIN sets s ON s.match_id = pm.id
+			  JOIN games g ON g.set_id = s.id
+			  JOIN points pt ON pt.game_id = g.id
+			  JOIN plays pl ON pl.point_id = pt.id
+			  WHERE pl.player_id = $1
+			  GROUP BY 1, 2
+			  ORDER BY 1, 2`
+	rows, err := db.Query(query, playerID, dateRange.From, dateRange.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mix []playermodel.ShotMix
+	for rows.Next() {
+		var entry playermodel.ShotMix
+		if err := rows.Scan(&entry.Month, &entry.ContactType, &entry.Count); err != nil {
+			return nil, err
+		}
+		mix = append(mix, entry)
+	}
+	return mix, rows.Err()
+}
+
+// GetPartnerSplits returns the player's record and points won with each
+// partner, most frequent partner first.
+func GetPartnerSplits(db *database.DB, playerID int, dateRange playermodel.DateRange) ([]playermodel.PartnerSplit, error) {
+	query := `WITH ` + playerMatchesCTE + `, ` + matchrepo.ResultsCTE("player_matches") + `
+			  SELECT p.id, p.name, p.created_at,
+				  COUNT(*) AS matches,
+				  COUNT(*) FILTER (WHERE mr.winner_team = pm.team) AS won,
+				  COUNT(*) FILTER (WHERE mr.winner_team = 3 - pm.team) AS lost,
+				  SUM(mr.team1_points + mr.team2_points) AS points_played,
+				  SUM(CASE WHEN pm.team = 1 THEN mr.team1_points ELSE mr.team2_points END) AS points_won
+			  FROM match_results mr
+			  JOIN player_matches pm ON pm.id = mr.match_id
+			  JOIN players p ON p.id = pm.partner_id
+			  GROUP BY p.id, p.name, p.created_at
+			  ORDER BY matches DESC, p.name`
+	rows, err := db.Query(query, playerID, dateRange.From, dateRange.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var splits []playermodel.PartnerSplit
+	for rows.Next() {
+		var split playermodel.PartnerSplit
+		err := rows.Scan(
+			&split.Partner.ID,
+			&split.Partner.Name,
+			&split.Partner.CreatedAt,
+			&split.Matches,
+			&split.Won,
+			&split.Lost,
+			&split.PointsPlayed,
+			&split.PointsWon)
+		if err != nil {
+			return nil, err
+		}
+		splits = append(splits, split)
+	}
+	return splits, rows.Err()
+}
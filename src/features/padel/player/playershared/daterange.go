@@ -0,0 +1,31 @@
+package playershared
+
+import (
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"database/sql"
+	"time"
+)
+
+// DateLayout is the format of the from and to query parameters
+const DateLayout = "2006-01-02"
+
+// ParseDateRange converts inclusive from/to dates into a half-open range.
+// Empty values leave that bound open.
+func ParseDateRange(from, to string) (playermodel.DateRange, error) {
+	var dateRange playermodel.DateRange
+	if from != "" {
+		t, err := time.Parse(DateLayout, from)
+		if err != nil {
+			return dateRange, err
+		}
+		dateRange.From = sql.NullTime{Time: t, Valid: true}
+	}
+	if to != "" {
+		t, err := time.Parse(DateLayout, to)
+		if err != nil {
+			return dateRange, err
+		}
+		dateRange.To = sql.NullTime{Time: t.AddDate(0, 0, 1), Valid: true}
+	}
+	return dateRange, nil
+}
@@ -0,0 +1,17 @@
+package playershared
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func GetPlayerID(w http.ResponseWriter, r *http.Request) int {
+	playerID := r.PathValue("playerID")
+	id, err := strconv.Atoi(playerID)
+	if err != nil {
+		slog.Error("Invalid player ID", "error", err)
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,91 @@
+package playerviews
+
+import (
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"ct-padel-s/src/features/padel/shot/shotmodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+	"time"
+)
+
+//go:embed get.html
+var getHTML string
+var getComponent = utils.NewComponent("get.html", getHTML)
+
+//go:embed getbreadcrumb.html
+var getBreadcrumbHTML string
+var getBreadcrumbComponent = utils.NewComponent("getbreadcrumb.html", getBreadcrumbHTML)
+
+// shotMixColumn is a contact type in the shot mix table
+type shotMixColumn struct {
+	Code  string
+	Label string
+}
+
+type shotMixRow struct {
+	Month  time.Time
+	Counts []int
+	Total  int
+}
+
+type getViewModel struct {
+	Player       *playermodel.Player
+	From         string
+	To           string
+	Stats        *playermodel.CareerStats
+	ContactTypes []shotMixColumn
+	ShotMix      []shotMixRow
+	Partners     []playermodel.PartnerSplit
+	RatingChart  template.HTML
+}
+
+// RenderGet renders the player profile. from and to are echoed back into the
+// date range form as entered; the rating chart always covers all time. The
+// shot mix has a column per contact type in catalogue.
+func RenderGet(player *playermodel.Player, from, to string, stats *playermodel.CareerStats, mix []playermodel.ShotMix, partners []playermodel.PartnerSplit, ratingChart template.HTML, catalogue *shotmodel.Catalogue) (template.HTML, error) {
+	columns := shotMixColumns(catalogue)
+	return getComponent.Render(getViewModel{
+		Player:       player,
+		From:         from,
+		To:           to,
+		Stats:        stats,
+		ContactTypes: columns,
+		ShotMix:      pivotShotMix(mix, columns),
+		Partners:     partners,
+		RatingChart:  ratingChart,
+	})
+}
+
+func RenderGetBreadcrumb(player *playermodel.Player) (template.HTML, error) {
+	return getBreadcrumbComponent.Render(map[string]any{"Player": player})
+}
+
+// shotMixColumns lists every contact type in the catalogue, retired ones
+// too so past shots are still counted, then shots without one.
+func shotMixColumns(catalogue *shotmodel.Catalogue) []shotMixColumn {
+	columns := make([]shotMixColumn, 0, len(catalogue.ContactTypes)+1)
+	for _, contact := range catalogue.ContactTypes {
+		columns = append(columns, shotMixColumn{Code: contact.Code, Label: contact.Label})
+	}
+	return append(columns, shotMixColumn{Code: "unknown", Label: "Unknown"})
+}
+
+// pivotShotMix turns month/contact type counts, ordered by month, into one
+// row per month with a count for each column.
+func pivotShotMix(mix []playermodel.ShotMix, columns []shotMixColumn) []shotMixRow {
+	var rows []shotMixRow
+	for _, entry := range mix {
+		if len(rows) == 0 || !rows[len(rows)-1].Month.Equal(entry.Month) {
+			rows = append(rows, shotMixRow{Month: entry.Month, Counts: make([]int, len(columns))})
+		}
+		row := &rows[len(rows)-1]
+		for i, column := range columns {
+			if column.Code == entry.ContactType {
+				row.Counts[i] += entry.Count
+			}
+		}
+		row.Total += entry.Count
+	}
+	return rows
+}
@@ -0,0 +1,24 @@
+package playerviews
+
+import (
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+)
+
+//go:embed getall.html
+var getAllHTML string
+var getAllComponent = utils.NewComponent("getall.html", getAllHTML)
+
+//go:embed getallbreadcrumb.html
+var getAllBreadcrumbHTML string
+var getAllBreadcrumbComponent = utils.NewComponent("getallbreadcrumb.html", getAllBreadcrumbHTML)
+
+func RenderGetAll(players []*playermodel.Player) (template.HTML, error) {
+	return getAllComponent.Render(map[string]any{"Players": players})
+}
+
+func RenderGetAllBreadcrumb() (template.HTML, error) {
+	return getAllBreadcrumbComponent.Render(nil)
+}
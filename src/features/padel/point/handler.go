@@ -1,74 +1,50 @@
 package point
 
 import (
-	"ct-padel-s/src/features/padel/game/gamerepo"
-	"ct-padel-s/src/features/padel/game/gameshared"
-	"ct-padel-s/src/features/padel/match/matchrepo"
-	"ct-padel-s/src/features/padel/match/matchshared"
+	"ct-padel-s/src/features/padel/hierarchy"
 	"ct-padel-s/src/features/padel/play/playrepo"
 	"ct-padel-s/src/features/padel/play/playviews"
+	"ct-padel-s/src/features/padel/play/rallycheck"
 	"ct-padel-s/src/features/padel/point/pointmodel"
 	"ct-padel-s/src/features/padel/point/pointrepo"
-	"ct-padel-s/src/features/padel/point/pointshared"
 	"ct-padel-s/src/features/padel/point/pointviews"
-	"ct-padel-s/src/features/padel/set/setrepo"
-	"ct-padel-s/src/features/padel/set/setshared"
+	"ct-padel-s/src/features/padel/shot/shotrepo"
 	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/infrastructure/metrics"
 	"ct-padel-s/src/shared/components/footer"
 	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
+	"ct-padel-s/src/shared/precondition"
 	"ct-padel-s/src/shared/templates"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 func Create(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
+	path := hierarchy.FromRequest(r)
+	matchID := path.Match.ID
+	setID := path.Set.ID
+	gameID := path.Game.ID
 
-	matchID := matchshared.GetMatchID(w, r)
-	if matchID == 0 {
-		slog.Error("Invalid match ID", "matchID", matchID)
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
-	}
-
-	setID := setshared.GetSetID(w, r)
-	if setID == 0 {
-		slog.Error("Invalid set ID", "setID", setID)
-		http.Error(w, "Invalid set ID", http.StatusBadRequest)
-		return
-	}
-
-	gameID := gameshared.GetGameID(w, r)
-	if gameID == 0 {
-		slog.Error("Invalid game ID", "gameID", gameID)
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
-		return
-	}
-
-	points, err := pointrepo.GetPointsByGame(db, gameID)
-	if err != nil {
-		slog.Error("Failed to get points", "error", err)
-		http.Error(w, "Failed to get points", http.StatusInternalServerError)
-		return
-	}
-
-	point := pointmodel.Point{
-		GameID:      gameID,
-		PointNumber: len(points) + 1,
-	}
+	// The repository assigns the next point number atomically
+	point := pointmodel.Point{GameID: gameID}
 
 	if err := pointrepo.CreatePoint(db, &point); err != nil {
-		slog.Error("Failed to create point", "error", err)
-		http.Error(w, "Failed to create point", http.StatusInternalServerError)
+		logger.Error("Failed to create point", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create point")
 		return
 	}
+	metrics.PointsCreated.Inc()
 
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 
 	// Set HTMX redirect header and return created status
 	w.Header().Set("HX-Redirect", fmt.Sprintf("/matches/%d/sets/%d/games/%d/points/%d", matchID, setID, gameID, point.ID))
@@ -76,88 +52,18 @@ func Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func Get(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
-
-	matchID := matchshared.GetMatchID(w, r)
-	if matchID == 0 {
-		slog.Error("Invalid match ID", "matchID", matchID)
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
-	}
-
-	setID := setshared.GetSetID(w, r)
-	if setID == 0 {
-		slog.Error("Invalid set ID", "setID", setID)
-		http.Error(w, "Invalid set ID", http.StatusBadRequest)
-		return
-	}
-
-	gameID := gameshared.GetGameID(w, r)
-	if gameID == 0 {
-		slog.Error("Invalid game ID", "gameID", gameID)
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
-		return
-	}
-
-	pointID := pointshared.GetPointID(w, r)
-	if pointID == 0 {
-		slog.Error("Invalid point ID", "pointID", pointID)
-		http.Error(w, "Invalid point ID", http.StatusBadRequest)
-		return
-	}
-
-	match, err := matchrepo.GetMatchWithPlayers(db, matchID)
-	if err != nil {
-		slog.Error("Failed to get match", "error", err, "matchID", matchID)
-		http.Error(w, "Failed to get match", http.StatusInternalServerError)
-		return
-	}
-
-	if match == nil {
-		http.Error(w, "Match not found", http.StatusNotFound)
-		return
-	}
-
-	set, err := setrepo.GetSet(db, setID)
-	if err != nil {
-		slog.Error("Failed to get set", "error", err, "setID", setID)
-		http.Error(w, "Failed to get set", http.StatusInternalServerError)
-		return
-	}
-
-	if set == nil {
-		http.Error(w, "Set not found", http.StatusNotFound)
-		return
-	}
-
-	game, err := gamerepo.GetGame(db, gameID)
-	if err != nil {
-		slog.Error("Failed to get game", "error", err, "gameID", gameID)
-		http.Error(w, "Failed to get game", http.StatusInternalServerError)
-		return
-	}
-
-	if game == nil {
-		http.Error(w, "Game not found", http.StatusNotFound)
-		return
-	}
-
-	point, err := pointrepo.GetPoint(db, pointID)
-	if err != nil {
-		slog.Error("Failed to get point", "error", err, "pointID", pointID)
-		http.Error(w, "Failed to get point", http.StatusInternalServerError)
-		return
-	}
-
-	if point == nil {
-		http.Error(w, "Point not found", http.StatusNotFound)
-		return
-	}
+	path := hierarchy.FromRequest(r)
+	match := path.Match
+	set := path.Set
+	game := path.Game
+	point := path.Point
 
 	breadcrumb, err := pointviews.RenderBreadcrumb(match, set, game, point)
 	if err != nil {
-		http.Error(w, "Template error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
 		return
 	}
 
@@ -166,35 +72,50 @@ func Get(w http.ResponseWriter, r *http.Request) {
 
 	headerHTML, err := header.Render(header.Data{Title: title + " - Padel Tracker", Breadcrumb: breadcrumb})
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
 	footerHTML, err := footer.Render(footer.Data{})
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
 	// Get plays for this point
 	plays, err := playrepo.GetPlaysByPoint(db, point.ID)
 	if err != nil {
-		slog.Error("Failed to get plays", "error", err, "pointID", point.ID)
-		http.Error(w, "Failed to get plays", http.StatusInternalServerError)
+		logger.Error("Failed to get plays", "error", err, "pointID", point.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get plays")
 		return
 	}
 
+	// Flag plays that break the rally rules
+	server, err := playrepo.GetGameServer(db, point.ID)
+	if err != nil {
+		logger.Error("Failed to get game server", "error", err, "pointID", point.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to check rally")
+		return
+	}
+	catalogue, err := shotrepo.GetCatalogue(db)
+	if err != nil {
+		logger.Error("Failed to get shot catalogue", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to check rally")
+		return
+	}
+	issues := rallycheck.ByPlay(rallycheck.Check(match, plays, server, catalogue))
+
 	// Render plays list
-	playsListHTML, err := playviews.RenderPlayList(plays, point, game, set, match)
+	playsListHTML, err := playviews.RenderPlayList(plays, point, game, set, match, issues)
 	if err != nil {
-		http.Error(w, "Template error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
 		return
 	}
 
 	// Load feature content and render with data
 	contentHTML, err := pointviews.RenderGet(point, game, set, match, playsListHTML)
 	if err != nil {
-		http.Error(w, "Template error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
 		return
 	}
 
@@ -207,105 +128,101 @@ func Get(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	precondition.SetETag(w, point.Version)
 	io.WriteString(w, string(page))
 }
 
 func Delete(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
+	path := hierarchy.FromRequest(r)
+	matchID := path.Match.ID
+	setID := path.Set.ID
+	gameID := path.Game.ID
+	pointID := path.Point.ID
 
-	matchID := matchshared.GetMatchID(w, r)
-	if matchID == 0 {
-		slog.Error("Invalid match ID", "matchID", matchID)
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
-	}
-
-	setID := setshared.GetSetID(w, r)
-	if setID == 0 {
-		slog.Error("Invalid set ID", "setID", setID)
-		http.Error(w, "Invalid set ID", http.StatusBadRequest)
-		return
-	}
-
-	gameID := gameshared.GetGameID(w, r)
-	if gameID == 0 {
-		slog.Error("Invalid game ID", "gameID", gameID)
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
-		return
-	}
-
-	pointID := pointshared.GetPointID(w, r)
-	if pointID == 0 {
-		slog.Error("Invalid point ID", "pointID", pointID)
-		http.Error(w, "Invalid point ID", http.StatusBadRequest)
-		return
-	}
-
-	// Check if point exists
-	point, err := pointrepo.GetPoint(db, pointID)
+	expectedVersion, hasPrecondition, err := precondition.ExpectedVersion(r)
 	if err != nil {
-		slog.Error("Failed to get point", "error", err, "pointID", pointID)
-		http.Error(w, "Failed to get point", http.StatusInternalServerError)
+		logger.Warn("Invalid precondition", "error", err, "pointID", pointID)
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid If-Match version")
 		return
 	}
-
-	if point == nil {
-		http.Error(w, "Point not found", http.StatusNotFound)
-		return
+	if !hasPrecondition {
+		expectedVersion = path.Point.Version
 	}
 
 	// Delete the point (this will also reorder remaining point numbers)
-	if err := pointrepo.DeletePoint(db, pointID); err != nil {
-		slog.Error("Failed to delete point", "error", err, "pointID", pointID)
-		http.Error(w, "Failed to delete point", http.StatusInternalServerError)
+	if err := pointrepo.DeletePoint(db, pointID, expectedVersion); err == database.ErrConflict {
+		logger.Warn("Point delete conflict", "pointID", pointID, "expectedVersion", expectedVersion)
+		httperror.Write(w, r, http.StatusConflict, "This point was changed by someone else. Reload and try again.")
+		return
+	} else if err != nil {
+		logger.Error("Failed to delete point", "error", err, "pointID", pointID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to delete point")
 		return
 	}
 
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 
 	w.Header().Set("HX-Redirect", fmt.Sprintf("/matches/%d/sets/%d/games/%d", matchID, setID, gameID))
 	w.WriteHeader(http.StatusOK)
 }
 
-func GetByGame(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+// Start stamps the point's start time with the current time.
+func Start(w http.ResponseWriter, r *http.Request) {
+	stamp(w, r, pointrepo.StartPoint, "Point cannot start after it ended")
+}
+
+// End stamps the point's end time with the current time.
+func End(w http.ResponseWriter, r *http.Request) {
+	stamp(w, r, pointrepo.EndPoint, "Point cannot end before it started")
+}
+
+func stamp(w http.ResponseWriter, r *http.Request, set func(*database.DB, int, time.Time) (bool, error), conflict string) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
 	db := database.GetDB()
+	path := hierarchy.FromRequest(r)
+	pointID := path.Point.ID
 
-	matchID := matchshared.GetMatchID(w, r)
-	if matchID == 0 {
-		slog.Error("Invalid match ID", "matchID", matchID)
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+	ok, err := set(db, pointID, time.Now())
+	if err != nil {
+		logger.Error("Failed to record point time", "error", err, "pointID", pointID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to record point time")
 		return
 	}
-
-	setID := setshared.GetSetID(w, r)
-	if setID == 0 {
-		slog.Error("Invalid set ID", "setID", setID)
-		http.Error(w, "Invalid set ID", http.StatusBadRequest)
+	if !ok {
+		httperror.Write(w, r, http.StatusConflict, conflict)
 		return
 	}
 
-	gameID := gameshared.GetGameID(w, r)
-	if gameID == 0 {
-		slog.Error("Invalid game ID", "gameID", gameID)
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
-		return
-	}
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/matches/%d/sets/%d/games/%d/points/%d", path.Match.ID, path.Set.ID, path.Game.ID, pointID))
+	w.WriteHeader(http.StatusOK)
+}
+
+func GetByGame(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+	path := hierarchy.FromRequest(r)
+	gameID := path.Game.ID
 
 	points, err := pointrepo.GetPointsByGame(db, gameID)
 	if err != nil {
-		slog.Error("Failed to get points", "error", err, "gameID", gameID)
-		http.Error(w, "Failed to get points", http.StatusInternalServerError)
+		logger.Error("Failed to get points", "error", err, "gameID", gameID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get points")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(points)
-	slog.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
 }
@@ -1,11 +1,18 @@
 package pointmodel
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 type Point struct {
-	ID          int       `json:"id" db:"id"`
-	GameID      int       `json:"game_id" db:"game_id"`
-	PointNumber int       `json:"point_number" db:"point_number"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
-}
\ No newline at end of file
+	ID            int           `json:"id" db:"id"`
+	GameID        int           `json:"game_id" db:"game_id"`
+	PointNumber   int           `json:"point_number" db:"point_number"`
+	VideoOffsetMs sql.NullInt64 `json:"video_offset_ms" db:"video_offset_ms"`
+	StartedAt     sql.NullTime  `json:"started_at" db:"started_at"`
+	EndedAt       sql.NullTime  `json:"ended_at" db:"ended_at"`
+	Version       int           `json:"version" db:"version"`
+	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at" db:"updated_at"`
+}
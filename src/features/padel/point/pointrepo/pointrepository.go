@@ -3,16 +3,36 @@ package pointrepo
 import (
 	"ct-padel-s/src/features/padel/point/pointmodel"
 	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
+	"time"
 )
 
+// CreatePoint inserts point as the next point of its game, assigning point.PointNumber.
+// The game row is locked for the duration so concurrent creates are numbered
+// one after the other instead of colliding on UNIQUE(game_id, point_number).
 func CreatePoint(db *database.DB, point *pointmodel.Point) error {
-	query := `INSERT INTO points (game_id, point_number) VALUES ($1, $2) RETURNING id, created_at, updated_at`
-	err := db.QueryRow(query, point.GameID, point.PointNumber).Scan(&point.ID, &point.CreatedAt, &point.UpdatedAt)
-	return err
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM games WHERE id = $1 FOR UPDATE`, point.GameID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO points (game_id, point_number)
+			  SELECT $1, COALESCE(MAX(point_number), 0) + 1 FROM points WHERE game_id = $1
+			  RETURNING id, point_number, version, created_at, updated_at`
+	if err := tx.QueryRow(query, point.GameID).Scan(&point.ID, &point.PointNumber, &point.Version, &point.CreatedAt, &point.UpdatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func GetPointsByGame(db *database.DB, gameID int) ([]*pointmodel.Point, error) {
-	query := `SELECT id, game_id, point_number, created_at, updated_at FROM points WHERE game_id = $1 ORDER BY point_number`
+	query := `SELECT id, game_id, point_number, video_offset_ms, started_at, ended_at, version, created_at, updated_at FROM points WHERE game_id = $1 ORDER BY point_number`
 	rows, err := db.Query(query, gameID)
 	if err != nil {
 		return nil, err
@@ -22,7 +42,7 @@ func GetPointsByGame(db *database.DB, gameID int) ([]*pointmodel.Point, error) {
 	var points []*pointmodel.Point
 	for rows.Next() {
 		var point pointmodel.Point
-		err := rows.Scan(&point.ID, &point.GameID, &point.PointNumber, &point.CreatedAt, &point.UpdatedAt)
+		err := rows.Scan(&point.ID, &point.GameID, &point.PointNumber, &point.VideoOffsetMs, &point.StartedAt, &point.EndedAt, &point.Version, &point.CreatedAt, &point.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -32,35 +52,55 @@ func GetPointsByGame(db *database.DB, gameID int) ([]*pointmodel.Point, error) {
 }
 
 func GetPoint(db *database.DB, pointID int) (*pointmodel.Point, error) {
-	query := `SELECT id, game_id, point_number, created_at, updated_at FROM points WHERE id = $1`
+	query := `SELECT id, game_id, point_number, video_offset_ms, started_at, ended_at, version, created_at, updated_at FROM points WHERE id = $1`
 	var point pointmodel.Point
-	err := db.QueryRow(query, pointID).Scan(&point.ID, &point.GameID, &point.PointNumber, &point.CreatedAt, &point.UpdatedAt)
+	err := db.QueryRow(query, pointID).Scan(&point.ID, &point.GameID, &point.PointNumber, &point.VideoOffsetMs, &point.StartedAt, &point.EndedAt, &point.Version, &point.CreatedAt, &point.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	return &point, err
 }
 
-func DeletePoint(db *database.DB, pointID int) error {
-	// First get the point to know its game_id and point_number
-	point, err := GetPoint(db, pointID)
+// DeletePoint deletes the point if it is still at expectedVersion and renumbers the
+// remaining points. It returns database.ErrConflict when the point was changed in
+// the meantime.
+func DeletePoint(db *database.DB, pointID int, expectedVersion int) error {
+	// Begin transaction
+	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// Begin transaction
-	tx, err := db.Begin()
+	// Lock the game so deletes and creates of siblings do not interleave
+	var gameID int
+	err = tx.QueryRow(`SELECT game_id FROM points WHERE id = $1`, pointID).Scan(&gameID)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	if _, err := tx.Exec(`SELECT id FROM games WHERE id = $1 FOR UPDATE`, gameID); err != nil {
+		return err
+	}
+
+	// Renumbering shifts siblings onto each other's numbers, so only check
+	// uniqueness once the whole transaction is done
+	if _, err := tx.Exec(`SET CONSTRAINTS ALL DEFERRED`); err != nil {
+		return err
+	}
 
 	// Delete the point
-	_, err = tx.Exec(`DELETE FROM points WHERE id = $1`, pointID)
+	var pointNumber int
+	err = tx.QueryRow(`DELETE FROM points WHERE id = $1 AND version = $2 RETURNING point_number`, pointID, expectedVersion).Scan(&pointNumber)
+	if err == sql.ErrNoRows {
+		return database.ErrConflict
+	}
 	if err != nil {
 		return err
 	}
 
 	// Update point numbers for points with higher numbers in the same game
-	_, err = tx.Exec(`UPDATE points SET point_number = point_number - 1 WHERE game_id = $1 AND point_number > $2`,
-		point.GameID, point.PointNumber)
+	_, err = tx.Exec(`UPDATE points SET point_number = point_number - 1, version = version + 1 WHERE game_id = $1 AND point_number > $2`,
+		gameID, pointNumber)
 	if err != nil {
 		return err
 	}
@@ -68,22 +108,35 @@ func DeletePoint(db *database.DB, pointID int) error {
 	return tx.Commit()
 }
 
-func CreateNextPoint(db *database.DB, gameID int) (*pointmodel.Point, error) {
-	// Get existing points for this game
-	points, err := GetPointsByGame(db, gameID)
-	if err != nil {
-		return nil, err
-	}
+// StartPoint records when the point started. It reports false, leaving the
+// point unchanged, when the point already ended before startedAt.
+func StartPoint(db *database.DB, pointID int, startedAt time.Time) (bool, error) {
+	query := `UPDATE points SET started_at = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $2 AND (ended_at IS NULL OR ended_at >= $1)`
+	return updated(db.Exec(query, startedAt, pointID))
+}
+
+// EndPoint records when the point ended. It reports false, leaving the
+// point unchanged, when the point started after endedAt.
+func EndPoint(db *database.DB, pointID int, endedAt time.Time) (bool, error) {
+	query := `UPDATE points SET ended_at = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $2 AND (started_at IS NULL OR started_at <= $1)`
+	return updated(db.Exec(query, endedAt, pointID))
+}
 
-	// Create new point with next number
-	point := &pointmodel.Point{
-		GameID:      gameID,
-		PointNumber: len(points) + 1,
+func updated(result sql.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
 	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
 
+func CreateNextPoint(db *database.DB, gameID int) (*pointmodel.Point, error) {
+	point := &pointmodel.Point{GameID: gameID}
 	if err := CreatePoint(db, point); err != nil {
 		return nil, err
 	}
 
 	return point, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,64 @@
+package pointrepo
+
+import (
+	"ct-padel-s/src/features/padel/point/pointmodel"
+	"ct-padel-s/src/infrastructure/database/databasetest"
+	"slices"
+	"testing"
+)
+
+const concurrentCreates = 20
+
+func TestCreatePointConcurrently(t *testing.T) {
+	db := databasetest.Open(t)
+	gameID := databasetest.Game(t, db)
+
+	databasetest.Concurrently(t, concurrentCreates, func() error {
+		return CreatePoint(db, &pointmodel.Point{GameID: gameID})
+	})
+
+	points, err := GetPointsByGame(db, gameID)
+	if err != nil {
+		t.Fatalf("GetPointsByGame: %v", err)
+	}
+	if len(points) != concurrentCreates {
+		t.Fatalf("got %d points, want %d", len(points), concurrentCreates)
+	}
+	numbers := make([]int, len(points))
+	for i, point := range points {
+		numbers[i] = point.PointNumber
+	}
+	databasetest.Numbered(t, numbers)
+}
+
+func TestDeletePointRenumbers(t *testing.T) {
+	db := databasetest.Open(t)
+	gameID := databasetest.Game(t, db)
+
+	var created []pointmodel.Point
+	for range 5 {
+		point := pointmodel.Point{GameID: gameID}
+		if err := CreatePoint(db, &point); err != nil {
+			t.Fatalf("CreatePoint: %v", err)
+		}
+		created = append(created, point)
+	}
+
+	if err := DeletePoint(db, created[2].ID, created[2].Version); err != nil {
+		t.Fatalf("DeletePoint: %v", err)
+	}
+
+	points, err := GetPointsByGame(db, gameID)
+	if err != nil {
+		t.Fatalf("GetPointsByGame: %v", err)
+	}
+	want := slices.Delete(slices.Clone(created), 2, 3)
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d", len(points), len(want))
+	}
+	for i, point := range points {
+		if point.ID != want[i].ID || point.PointNumber != i+1 {
+			t.Errorf("point %d: id %d number %d, want id %d number %d", i, point.ID, point.PointNumber, want[i].ID, i+1)
+		}
+	}
+}
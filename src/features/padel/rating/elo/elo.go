@@ -0,0 +1,37 @@
+package elo
+
+import "math"
+
+const (
+	// InitialRating is the rating of a player before their first rated match
+	InitialRating = 1500.0
+	// K scales how far a single match moves a rating
+	K = 32.0
+)
+
+// TeamRating is the strength of a pair: the mean of both partners' ratings.
+func TeamRating(player1, player2 float64) float64 {
+	return (player1 + player2) / 2
+}
+
+// Expected is the probability that a team rated team beats one rated opponent.
+func Expected(team, opponent float64) float64 {
+	return 1 / (1 + math.Pow(10, (opponent-team)/400))
+}
+
+// MarginMultiplier grows the update with the games margin, with diminishing
+// returns: 1 for a level game count, about 1.7 for three games, 2.3 for twelve.
+func MarginMultiplier(gameDiff int) float64 {
+	return 1 + math.Log1p(math.Abs(float64(gameDiff)))/2
+}
+
+// Delta is the rating change for each player of team 1. Both partners move
+// by the same amount and team 2's players move by the negation, so rating is
+// conserved.
+func Delta(team1, team2 float64, team1Won bool, team1Games, team2Games int) float64 {
+	actual := 0.0
+	if team1Won {
+		actual = 1
+	}
+	return K * MarginMultiplier(team1Games-team2Games) * (actual - Expected(team1, team2))
+}
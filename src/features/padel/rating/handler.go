@@ -0,0 +1,79 @@
+package rating
+
+import (
+	"ct-padel-s/src/features/padel/pairing/pairingmodel"
+	"ct-padel-s/src/features/padel/pairing/pairingrepo"
+	"ct-padel-s/src/features/padel/rating/ratingrepo"
+	"ct-padel-s/src/features/padel/rating/ratingservice"
+	"ct-padel-s/src/features/padel/rating/ratingviews"
+	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/shared/components/footer"
+	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
+	"ct-padel-s/src/shared/templates"
+	"io"
+	"net/http"
+)
+
+func Leaderboard(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	standings, err := ratingrepo.GetLeaderboard(db)
+	if err != nil {
+		logger.Error("Failed to get leaderboard", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get leaderboard")
+		return
+	}
+
+	partnerships, err := pairingrepo.GetPartnerships(db, pairingmodel.Filter{})
+	if err != nil {
+		logger.Error("Failed to get partnerships", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get partnerships")
+		return
+	}
+
+	breadcrumb, err := ratingviews.RenderBreadcrumb()
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load shared components
+	headerHTML, err := header.Render(header.Data{Title: "Ratings - Padel Tracker", Breadcrumb: breadcrumb})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	footerHTML, err := footer.Render(footer.Data{})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load feature content and render with data
+	contentHTML, err := ratingviews.RenderLeaderboard(standings, ratingservice.PairStandings(standings, partnerships))
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Compose final page
+	page, err := templates.Render(templates.Data{
+		Title:       "Ratings - Padel Tracker",
+		HeaderHTML:  headerHTML,
+		ContentHTML: contentHTML,
+		FooterHTML:  footerHTML,
+	})
+
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	io.WriteString(w, string(page))
+}
@@ -0,0 +1,49 @@
+package ratingmodel
+
+import (
+	"ct-padel-s/src/features/padel/pairing/pairingmodel"
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"time"
+)
+
+// Entry records how one match changed one player's rating.
+type Entry struct {
+	ID           int       `json:"id" db:"id"`
+	PlayerID     int       `json:"player_id" db:"player_id"`
+	MatchID      int       `json:"match_id" db:"match_id"`
+	RatingBefore float64   `json:"rating_before" db:"rating_before"`
+	RatingAfter  float64   `json:"rating_after" db:"rating_after"`
+	RatedAt      time.Time `json:"rated_at" db:"rated_at"`
+}
+
+func (e Entry) Change() float64 {
+	return e.RatingAfter - e.RatingBefore
+}
+
+// MatchResult is the outcome of a completed match as the rating engine
+// sees it.
+type MatchResult struct {
+	MatchID    int
+	MatchDate  time.Time
+	Team1      [2]int
+	Team2      [2]int
+	WinnerTeam int
+	Team1Games int
+	Team2Games int
+}
+
+// Standing is a player's current place on the leaderboard.
+type Standing struct {
+	Rank       int                `json:"rank"`
+	Player     playermodel.Player `json:"player"`
+	Rating     float64            `json:"rating"`
+	Matches    int                `json:"matches"`
+	LastChange float64            `json:"last_change"`
+}
+
+// PairStanding rates a partnership from its players' current ratings.
+type PairStanding struct {
+	Pair    pairingmodel.Pair `json:"pair"`
+	Rating  float64           `json:"rating"`
+	Matches int               `json:"matches"`
+}
@@ -0,0 +1,181 @@
+package ratingrepo
+
+import (
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/rating/ratingmodel"
+	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
+	"time"
+)
+
+// GetCompletedResults returns the completed matches with a decided winner in
+// rating order (match date, then ID). With matchID set only that match is
+// returned.
+func GetCompletedResults(q database.Querier, matchID sql.NullInt64) ([]ratingmodel.MatchResult, error) {
+	query := `WITH completed_matches AS (
+				  SELECT * FROM matches
+				  WHERE completed_at IS NOT NULL
+				    AND ($1::integer IS NULL OR id = $1::integer)
+			  ), ` + matchrepo.ResultsCTE("completed_matches") + `
+			  SELECT cm.id, cm.match_date,
+				  cm.team1_player1_id, cm.team1_player2_id, cm.team2_player1_id, cm.team2_player2_id,
+				  mr.winner_team, mr.team1_games, mr.team2_games
+			  FROM completed_matches cm
+			  JOIN match_results mr ON mr.match_id = cm.id
+			  WHERE mr.winner_team IS NOT NULL
+			  ORDER BY cm.match_date, cm.id`
+	rows, err := q.Query(query, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []ratingmodel.MatchResult
+	for rows.Next() {
+		var result ratingmodel.MatchResult
+		err := rows.Scan(
+			&result.MatchID,
+			&result.MatchDate,
+			&result.Team1[0],
+			&result.Team1[1],
+			&result.Team2[0],
+			&result.Team2[1],
+			&result.WinnerTeam,
+			&result.Team1Games,
+			&result.Team2Games)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+// GetCurrentRatings returns each rated player's latest rating.
+func GetCurrentRatings(q database.Querier) (map[int]float64, error) {
+	query := `SELECT DISTINCT ON (player_id) player_id, rating_after
+			  FROM rating_history
+			  ORDER BY player_id, rated_at DESC, match_id DESC`
+	rows, err := q.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := map[int]float64{}
+	for rows.Next() {
+		var playerID int
+		var rating float64
+		if err := rows.Scan(&playerID, &rating); err != nil {
+			return nil, err
+		}
+		ratings[playerID] = rating
+	}
+	return ratings, rows.Err()
+}
+
+// IsRatedAfter reports whether any match later than (matchDate, matchID) in
+// rating order has already been rated.
+func IsRatedAfter(q database.Querier, matchDate time.Time, matchID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (
+				  SELECT 1 FROM rating_history
+				  WHERE (rated_at, match_id) > ($1, $2)
+			  )`
+	err := q.QueryRow(query, matchDate, matchID).Scan(&exists)
+	return exists, err
+}
+
+func IsRated(q database.Querier, matchID int) (bool, error) {
+	var exists bool
+	err := q.QueryRow(`SELECT EXISTS (SELECT 1 FROM rating_history WHERE match_id = $1)`, matchID).Scan(&exists)
+	return exists, err
+}
+
+func DeleteAllEntries(q database.Querier) error {
+	_, err := q.Exec(`DELETE FROM rating_history`)
+	return err
+}
+
+func CreateEntry(q database.Querier, entry *ratingmodel.Entry) error {
+	query := `INSERT INTO rating_history (player_id, match_id, rating_before, rating_after, rated_at)
+			  VALUES ($1, $2, $3, $4, $5)
+			  RETURNING id`
+	return q.QueryRow(query,
+		entry.PlayerID,
+		entry.MatchID,
+		entry.RatingBefore,
+		entry.RatingAfter,
+		entry.RatedAt).Scan(&entry.ID)
+}
+
+// GetLeaderboard returns every rated player, highest rating first.
+func GetLeaderboard(db *database.DB) ([]ratingmodel.Standing, error) {
+	query := `SELECT ROW_NUMBER() OVER (ORDER BY latest.rating_after DESC, p.name),
+				  p.id, p.name, p.created_at, latest.rating_after, latest.rating_after - latest.rating_before, counts.matches
+			  FROM (
+				  SELECT DISTINCT ON (player_id) player_id, rating_before, rating_after
+				  FROM rating_history
+				  ORDER BY player_id, rated_at DESC, match_id DESC
+			  ) latest
+			  JOIN (
+				  SELECT player_id, COUNT(*) AS matches
+				  FROM rating_history
+				  GROUP BY player_id
+			  ) counts ON counts.player_id = latest.player_id
+			  JOIN players p ON p.id = latest.player_id
+			  ORDER BY latest.rating_after DESC, p.name`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var standings []ratingmodel.Standing
+	for rows.Next() {
+		var standing ratingmodel.Standing
+		err := rows.Scan(
+			&standing.Rank,
+			&standing.Player.ID,
+			&standing.Player.Name,
+			&standing.Player.CreatedAt,
+			&standing.Rating,
+			&standing.LastChange,
+			&standing.Matches)
+		if err != nil {
+			return nil, err
+		}
+		standings = append(standings, standing)
+	}
+	return standings, rows.Err()
+}
+
+// GetPlayerHistory returns the player's rating entries in rating order.
+func GetPlayerHistory(db *database.DB, playerID int) ([]ratingmodel.Entry, error) {
+	query := `SELECT id, player_id, match_id, rating_before, rating_after, rated_at
+			  FROM rating_history
+			  WHERE player_id = $1
+			  ORDER BY rated_at, match_id`
+	rows, err := db.Query(query, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []ratingmodel.Entry
+	for rows.Next() {
+		var entry ratingmodel.Entry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.PlayerID,
+			&entry.MatchID,
+			&entry.RatingBefore,
+			&entry.RatingAfter,
+			&entry.RatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,169 @@
+package ratingservice
+
+import (
+	"ct-padel-s/src/features/padel/pairing/pairingmodel"
+	"ct-padel-s/src/features/padel/rating/elo"
+	"ct-padel-s/src/features/padel/rating/ratingmodel"
+	"ct-padel-s/src/features/padel/rating/ratingrepo"
+	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
+	"log/slog"
+	"sort"
+)
+
+// lockKey is the advisory lock that serialises rating updates, so two
+// matches completed at once never start from the same ratings
+const lockKey = 3607
+
+// RateMatch applies a newly completed match to the ratings. If a later match
+// has already been rated the history is replayed from scratch instead, so
+// ratings always reflect matches in date order. Matches without a decided
+// winner are left unrated. It runs in the caller's transaction, which holds
+// the rating lock until it commits.
+func RateMatch(q database.Querier, matchID int) error {
+	if _, err := q.Exec(`SELECT pg_advisory_xact_lock($1)`, lockKey); err != nil {
+		return err
+	}
+
+	results, err := ratingrepo.GetCompletedResults(q, sql.NullInt64{Int64: int64(matchID), Valid: true})
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		slog.Info("Match has no decided winner, not rated", "matchID", matchID)
+		return nil
+	}
+	result := results[0]
+
+	rated, err := ratingrepo.IsRated(q, matchID)
+	if err != nil {
+		return err
+	}
+	ratedAfter, err := ratingrepo.IsRatedAfter(q, result.MatchDate, result.MatchID)
+	if err != nil {
+		return err
+	}
+	if rated || ratedAfter {
+		_, err := recompute(q)
+		return err
+	}
+
+	ratings, err := ratingrepo.GetCurrentRatings(q)
+	if err != nil {
+		return err
+	}
+	for _, entry := range Apply(ratings, result) {
+		if err := ratingrepo.CreateEntry(q, &entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Recompute discards the rating history and replays every completed match
+// in date order. It returns the number of matches rated.
+func Recompute(db *database.DB) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, lockKey); err != nil {
+		return 0, err
+	}
+
+	rated, err := recompute(tx)
+	if err != nil {
+		return 0, err
+	}
+	return rated, tx.Commit()
+}
+
+func recompute(q database.Querier) (int, error) {
+	if err := ratingrepo.DeleteAllEntries(q); err != nil {
+		return 0, err
+	}
+
+	results, err := ratingrepo.GetCompletedResults(q, sql.NullInt64{})
+	if err != nil {
+		return 0, err
+	}
+
+	ratings := map[int]float64{}
+	for _, result := range results {
+		for _, entry := range Apply(ratings, result) {
+			if err := ratingrepo.CreateEntry(q, &entry); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(results), nil
+}
+
+// Apply rates result against ratings, updating ratings in place, and
+// returns one history entry per player. Players missing from ratings start
+// at elo.InitialRating.
+func Apply(ratings map[int]float64, result ratingmodel.MatchResult) []ratingmodel.Entry {
+	rating := func(playerID int) float64 {
+		if r, ok := ratings[playerID]; ok {
+			return r
+		}
+		return elo.InitialRating
+	}
+
+	team1 := elo.TeamRating(rating(result.Team1[0]), rating(result.Team1[1]))
+	team2 := elo.TeamRating(rating(result.Team2[0]), rating(result.Team2[1]))
+	delta := elo.Delta(team1, team2, result.WinnerTeam == 1, result.Team1Games, result.Team2Games)
+
+	var entries []ratingmodel.Entry
+	record := func(playerID int, amount float64) {
+		entries = append(entries, ratingmodel.Entry{
+			PlayerID:     playerID,
+			MatchID:      result.MatchID,
+			RatingBefore: rating(playerID),
+			RatingAfter:  rating(playerID) + amount,
+			RatedAt:      result.MatchDate,
+		})
+	}
+	for _, playerID := range result.Team1 {
+		record(playerID, delta)
+	}
+	for _, playerID := range result.Team2 {
+		record(playerID, -delta)
+	}
+
+	// Update only after all entries are built, so both teams are rated from
+	// their ratings before this match
+	for _, entry := range entries {
+		ratings[entry.PlayerID] = entry.RatingAfter
+	}
+	return entries
+}
+
+// PairStandings rates each partnership from its players' current ratings,
+// highest first. Partnerships with an unrated player are left out.
+func PairStandings(players []ratingmodel.Standing, partnerships []pairingmodel.Partnership) []ratingmodel.PairStanding {
+	ratings := map[int]float64{}
+	for _, standing := range players {
+		ratings[standing.Player.ID] = standing.Rating
+	}
+
+	var pairs []ratingmodel.PairStanding
+	for _, partnership := range partnerships {
+		rating1, ok1 := ratings[partnership.Pair.Player1.ID]
+		rating2, ok2 := ratings[partnership.Pair.Player2.ID]
+		if !ok1 || !ok2 {
+			continue
+		}
+		pairs = append(pairs, ratingmodel.PairStanding{
+			Pair:    partnership.Pair,
+			Rating:  elo.TeamRating(rating1, rating2),
+			Matches: partnership.Matches,
+		})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Rating > pairs[j].Rating
+	})
+	return pairs
+}
@@ -0,0 +1,81 @@
+package ratingviews
+
+import (
+	"ct-padel-s/src/features/padel/rating/ratingmodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+//go:embed chart.html
+var chartHTML string
+var chartComponent = utils.NewComponent("chart.html", chartHTML)
+
+// Chart dimensions in SVG user units
+const (
+	chartWidth   = 600
+	chartHeight  = 200
+	chartPadding = 20
+)
+
+type chartPoint struct {
+	X     float64
+	Y     float64
+	Entry ratingmodel.Entry
+}
+
+type chartViewModel struct {
+	Width    int
+	Height   int
+	Points   []chartPoint
+	Polyline string
+	Min      float64
+	Max      float64
+	Current  float64
+}
+
+// RenderChart draws a player's rating after each rated match as a line
+// chart, starting from the rating before their first match.
+func RenderChart(history []ratingmodel.Entry) (template.HTML, error) {
+	viewModel := chartViewModel{Width: chartWidth, Height: chartHeight}
+	if len(history) == 0 {
+		return chartComponent.Render(viewModel)
+	}
+
+	ratings := []float64{history[0].RatingBefore}
+	for _, entry := range history {
+		ratings = append(ratings, entry.RatingAfter)
+	}
+
+	viewModel.Min, viewModel.Max = ratings[0], ratings[0]
+	for _, rating := range ratings {
+		viewModel.Min = min(viewModel.Min, rating)
+		viewModel.Max = max(viewModel.Max, rating)
+	}
+	viewModel.Current = ratings[len(ratings)-1]
+
+	// Keep a flat history from dividing by zero and centre it vertically
+	spread := viewModel.Max - viewModel.Min
+	if spread == 0 {
+		spread = 1
+	}
+
+	step := float64(chartWidth-2*chartPadding) / float64(len(ratings)-1)
+	coordinates := make([]string, len(ratings))
+	for i, rating := range ratings {
+		x := chartPadding + step*float64(i)
+		y := chartHeight - chartPadding - (rating-viewModel.Min)/spread*(chartHeight-2*chartPadding)
+		if viewModel.Max == viewModel.Min {
+			y = chartHeight / 2
+		}
+		coordinates[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+		if i > 0 {
+			viewModel.Points = append(viewModel.Points, chartPoint{X: x, Y: y, Entry: history[i-1]})
+		}
+	}
+	viewModel.Polyline = strings.Join(coordinates, " ")
+
+	return chartComponent.Render(viewModel)
+}
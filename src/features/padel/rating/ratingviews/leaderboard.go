@@ -0,0 +1,27 @@
+package ratingviews
+
+import (
+	"ct-padel-s/src/features/padel/rating/ratingmodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+)
+
+//go:embed leaderboard.html
+var leaderboardHTML string
+var leaderboardComponent = utils.NewComponent("leaderboard.html", leaderboardHTML)
+
+//go:embed breadcrumb.html
+var breadcrumbHTML string
+var breadcrumbComponent = utils.NewComponent("breadcrumb.html", breadcrumbHTML)
+
+func RenderLeaderboard(players []ratingmodel.Standing, pairs []ratingmodel.PairStanding) (template.HTML, error) {
+	return leaderboardComponent.Render(map[string]any{
+		"Players": players,
+		"Pairs":   pairs,
+	})
+}
+
+func RenderBreadcrumb() (template.HTML, error) {
+	return breadcrumbComponent.Render(nil)
+}
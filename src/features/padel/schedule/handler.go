@@ -0,0 +1,343 @@
+package schedule
+
+import (
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/player/playerrepo"
+	"ct-padel-s/src/features/padel/player/playershared"
+	"ct-padel-s/src/features/padel/schedule/ical"
+	"ct-padel-s/src/features/padel/schedule/schedulemodel"
+	"ct-padel-s/src/features/padel/schedule/schedulerepo"
+	"ct-padel-s/src/features/padel/schedule/scheduleservice"
+	"ct-padel-s/src/features/padel/schedule/scheduleshared"
+	"ct-padel-s/src/features/padel/schedule/scheduleviews"
+	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/shared/components/footer"
+	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
+	"ct-padel-s/src/shared/templates"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func Get(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	courts, err := schedulerepo.GetAllCourts(db)
+	if err != nil {
+		logger.Error("Failed to get courts", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get courts")
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	slots, err := schedulerepo.GetSlotsFrom(db, today)
+	if err != nil {
+		logger.Error("Failed to get slots", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get slots")
+		return
+	}
+
+	matches, err := matchrepo.GetAllMatches(db)
+	if err != nil {
+		logger.Error("Failed to get matches", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get matches")
+		return
+	}
+
+	breadcrumb, err := scheduleviews.RenderGetBreadcrumb()
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load shared components
+	headerHTML, err := header.Render(header.Data{Title: "Schedule - Padel Tracker", Breadcrumb: breadcrumb})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	footerHTML, err := footer.Render(footer.Data{})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load feature content and render with data
+	contentHTML, err := scheduleviews.RenderGet(courts, slots, matches)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Compose final page
+	page, err := templates.Render(templates.Data{
+		Title:       "Schedule - Padel Tracker",
+		HeaderHTML:  headerHTML,
+		ContentHTML: contentHTML,
+		FooterHTML:  footerHTML,
+	})
+
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	io.WriteString(w, string(page))
+}
+
+func CreateCourt(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	court := schedulemodel.Court{Name: strings.TrimSpace(r.FormValue("name"))}
+	if court.Name == "" {
+		httperror.Write(w, r, http.StatusBadRequest, "Court name is required")
+		return
+	}
+
+	if err := schedulerepo.CreateCourt(db, &court); err != nil {
+		logger.Error("Failed to create court", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create court")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/schedule")
+	w.WriteHeader(http.StatusCreated)
+}
+
+func CreateSlot(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	courtID, err := strconv.Atoi(r.FormValue("court_id"))
+	if err != nil {
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid court ID")
+		return
+	}
+	court, err := schedulerepo.GetCourt(db, courtID)
+	if err != nil {
+		logger.Error("Failed to get court", "error", err, "courtID", courtID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get court")
+		return
+	}
+	if court == nil {
+		httperror.NotFound(w, r, "Court not found")
+		return
+	}
+
+	startsAt, err := time.Parse(scheduleshared.DateTimeLayout, r.FormValue("starts_at"))
+	if err != nil {
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid start time")
+		return
+	}
+	minutes := schedulemodel.DefaultSlotMinutes
+	if value := r.FormValue("minutes"); value != "" {
+		minutes, err = strconv.Atoi(value)
+		if err != nil || minutes < 1 {
+			httperror.Write(w, r, http.StatusBadRequest, "Minutes must be a positive number")
+			return
+		}
+	}
+
+	slot := schedulemodel.Slot{
+		CourtID:  court.ID,
+		StartsAt: startsAt,
+		EndsAt:   startsAt.Add(time.Duration(minutes) * time.Minute),
+	}
+
+	var conflict *scheduleservice.ConflictError
+	if err := scheduleservice.CreateSlot(db, &slot); errors.As(err, &conflict) {
+		httperror.Write(w, r, http.StatusConflict, conflict.Error())
+		return
+	} else if err != nil {
+		logger.Error("Failed to create slot", "error", err, "courtID", court.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create slot")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/schedule")
+	w.WriteHeader(http.StatusCreated)
+}
+
+func DeleteSlot(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	slot, ok := loadSlot(w, r)
+	if !ok {
+		return
+	}
+
+	if err := schedulerepo.DeleteSlot(db, slot.ID); err != nil {
+		logger.Error("Failed to delete slot", "error", err, "slotID", slot.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to delete slot")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/schedule")
+	w.WriteHeader(http.StatusOK)
+}
+
+func Book(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	slotID := scheduleshared.GetSlotID(w, r)
+	if slotID == 0 {
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid slot ID")
+		return
+	}
+	matchID, err := strconv.Atoi(r.FormValue("match_id"))
+	if err != nil {
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid match ID")
+		return
+	}
+
+	var conflict *scheduleservice.ConflictError
+	err = scheduleservice.BookSlot(db, slotID, matchID)
+	switch {
+	case err == nil:
+	case errors.As(err, &conflict):
+		httperror.Write(w, r, http.StatusConflict, conflict.Error())
+		return
+	case err == scheduleservice.ErrSlotNotFound:
+		httperror.NotFound(w, r, "Slot not found")
+		return
+	case err == scheduleservice.ErrMatchNotFound:
+		httperror.NotFound(w, r, "Match not found")
+		return
+	default:
+		logger.Error("Failed to book slot", "error", err, "slotID", slotID, "matchID", matchID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to book slot")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/schedule")
+	w.WriteHeader(http.StatusOK)
+}
+
+func Release(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	slot, ok := loadSlot(w, r)
+	if !ok {
+		return
+	}
+
+	// The match keeps its planned start; only the court is freed
+	if err := schedulerepo.ReleaseSlot(db, slot.ID); err != nil {
+		logger.Error("Failed to release slot", "error", err, "slotID", slot.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to release slot")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/schedule")
+	w.WriteHeader(http.StatusOK)
+}
+
+// Calendar serves an iCalendar feed of the player's upcoming matches.
+func Calendar(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	playerID := playershared.GetPlayerID(w, r)
+	if playerID == 0 {
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid player ID")
+		return
+	}
+
+	player, err := playerrepo.GetPlayer(db, playerID)
+	if err != nil {
+		logger.Error("Failed to get player", "error", err, "playerID", playerID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get player")
+		return
+	}
+	if player == nil {
+		httperror.NotFound(w, r, "Player not found")
+		return
+	}
+
+	matches, err := schedulerepo.GetUpcomingMatchesByPlayer(db, playerID)
+	if err != nil {
+		logger.Error("Failed to get upcoming matches", "error", err, "playerID", playerID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get upcoming matches")
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	events := make([]ical.Event, len(matches))
+	for i, match := range matches {
+		events[i] = ical.Event{
+			UID:      fmt.Sprintf("match-%d@%s", match.ID, r.Host),
+			Start:    match.MatchDate,
+			End:      match.End(),
+			Summary:  fmt.Sprintf("Padel: %s & %s vs %s & %s", match.Team1Player1.Name, match.Team1Player2.Name, match.Team2Player1.Name, match.Team2Player2.Name),
+			Location: match.CourtName.String,
+			URL:      fmt.Sprintf("%s://%s/matches/%d", scheme, r.Host, match.ID),
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="player-%d.ics"`, player.ID))
+	if err := ical.Write(w, player.Name+" - Padel", events, time.Now()); err != nil {
+		logger.Error("Failed to write calendar", "error", err, "playerID", playerID)
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+}
+
+func loadSlot(w http.ResponseWriter, r *http.Request) (*schedulemodel.Slot, bool) {
+	logger := logging.FromRequest(r)
+	db := database.GetDB()
+
+	slotID := scheduleshared.GetSlotID(w, r)
+	if slotID == 0 {
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid slot ID")
+		return nil, false
+	}
+
+	slot, err := schedulerepo.GetSlot(db, slotID)
+	if err != nil {
+		logger.Error("Failed to get slot", "error", err, "slotID", slotID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get slot")
+		return nil, false
+	}
+	if slot == nil {
+		httperror.NotFound(w, r, "Slot not found")
+		return nil, false
+	}
+	return slot, true
+}
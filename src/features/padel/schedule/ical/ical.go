@@ -0,0 +1,96 @@
+// Package ical writes iCalendar (RFC 5545) feeds.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// Event times are wall-clock club times, written as floating times so
+	// calendars show them as entered
+	floatingLayout = "20060102T150405"
+	utcLayout      = "20060102T150405Z"
+
+	// maxLineOctets is the longest a content line may be before folding
+	maxLineOctets = 75
+)
+
+type Event struct {
+	UID      string
+	Start    time.Time
+	End      time.Time
+	Summary  string
+	Location string
+	URL      string
+}
+
+// Write writes a calendar named name holding events. stamp is the time the
+// feed was generated.
+func Write(w io.Writer, name string, events []Event, stamp time.Time) error {
+	out := bufio.NewWriter(w)
+	line := func(property, value string) {
+		out.WriteString(fold(property + ":" + value))
+		out.WriteString("\r\n")
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//CT Padel Tracker//Schedule//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", escape(name))
+	for _, event := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(event.UID))
+		line("DTSTAMP", stamp.UTC().Format(utcLayout))
+		line("DTSTART", event.Start.Format(floatingLayout))
+		line("DTEND", event.End.Format(floatingLayout))
+		line("SUMMARY", escape(event.Summary))
+		if event.Location != "" {
+			line("LOCATION", escape(event.Location))
+		}
+		if event.URL != "" {
+			line("URL", event.URL)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return out.Flush()
+}
+
+// escape escapes a TEXT value.
+func escape(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
+
+// fold splits a content line into lines of at most 75 octets, continuing
+// each with a space, without splitting a UTF-8 sequence.
+func fold(line string) string {
+	var b strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts towards the next line's length
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
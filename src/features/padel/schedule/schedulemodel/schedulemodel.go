@@ -0,0 +1,71 @@
+package schedulemodel
+
+import (
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DefaultSlotMinutes is the length of a new slot when none is given, and
+// the assumed length of a match that has no slot
+const DefaultSlotMinutes = 90
+
+// Times are wall-clock club times, stored without a time zone.
+
+type Court struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Slot is a bookable block of time on a court. A slot holding a match fixes
+// the match's planned start.
+type Slot struct {
+	ID        int           `json:"id" db:"id"`
+	CourtID   int           `json:"court_id" db:"court_id"`
+	CourtName string        `json:"court_name"`
+	StartsAt  time.Time     `json:"starts_at" db:"starts_at"`
+	EndsAt    time.Time     `json:"ends_at" db:"ends_at"`
+	MatchID   sql.NullInt64 `json:"match_id" db:"match_id"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+func (s *Slot) Booked() bool {
+	return s.MatchID.Valid
+}
+
+// Conflict is an existing slot that clashes with a booking. PlayerName is
+// set when the clash is a player double-booked rather than the court.
+type Conflict struct {
+	SlotID     int
+	CourtName  string
+	StartsAt   time.Time
+	EndsAt     time.Time
+	MatchID    sql.NullInt64
+	PlayerName string
+}
+
+func (c Conflict) String() string {
+	when := c.StartsAt.Format("Mon 02 Jan 15:04") + "-" + c.EndsAt.Format("15:04")
+	if c.PlayerName != "" {
+		return fmt.Sprintf("%s is already playing on %s at %s", c.PlayerName, c.CourtName, when)
+	}
+	return fmt.Sprintf("%s is already booked at %s", c.CourtName, when)
+}
+
+// ScheduledMatch is an upcoming match with its booking, if it has one.
+type ScheduledMatch struct {
+	matchmodel.MatchWithPlayers
+	CourtName sql.NullString
+	EndsAt    sql.NullTime
+}
+
+// End returns when the match is planned to finish.
+func (m *ScheduledMatch) End() time.Time {
+	if m.EndsAt.Valid {
+		return m.EndsAt.Time
+	}
+	return m.MatchDate.Add(DefaultSlotMinutes * time.Minute)
+}
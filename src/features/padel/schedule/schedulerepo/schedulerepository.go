@@ -0,0 +1,203 @@
+package schedulerepo
+
+import (
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/schedule/schedulemodel"
+	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
+	"time"
+)
+
+const slotColumns = `s.id, s.court_id, c.name, s.starts_at, s.ends_at, s.match_id, s.created_at, s.updated_at`
+
+func CreateCourt(db *database.DB, court *schedulemodel.Court) error {
+	query := `INSERT INTO courts (name) VALUES ($1) RETURNING id, created_at`
+	return db.QueryRow(query, court.Name).Scan(&court.ID, &court.CreatedAt)
+}
+
+func GetCourt(db database.Querier, id int) (*schedulemodel.Court, error) {
+	court := &schedulemodel.Court{}
+	query := `SELECT id, name, created_at FROM courts WHERE id = $1`
+	err := db.QueryRow(query, id).Scan(&court.ID, &court.Name, &court.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return court, err
+}
+
+func GetAllCourts(db *database.DB) ([]*schedulemodel.Court, error) {
+	rows, err := db.Query(`SELECT id, name, created_at FROM courts ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var courts []*schedulemodel.Court
+	for rows.Next() {
+		var court schedulemodel.Court
+		if err := rows.Scan(&court.ID, &court.Name, &court.CreatedAt); err != nil {
+			return nil, err
+		}
+		courts = append(courts, &court)
+	}
+	return courts, rows.Err()
+}
+
+func CreateSlot(db database.Querier, slot *schedulemodel.Slot) error {
+	query := `INSERT INTO court_slots (court_id, starts_at, ends_at) VALUES ($1, $2, $3)
+			  RETURNING id, created_at, updated_at`
+	return db.QueryRow(query, slot.CourtID, slot.StartsAt, slot.EndsAt).
+		Scan(&slot.ID, &slot.CreatedAt, &slot.UpdatedAt)
+}
+
+func GetSlot(db database.Querier, id int) (*schedulemodel.Slot, error) {
+	query := `SELECT ` + slotColumns + `
+			  FROM court_slots s JOIN courts c ON c.id = s.court_id
+			  WHERE s.id = $1`
+	slot, err := scanSlot(db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return slot, err
+}
+
+// GetSlotsFrom returns every slot ending after since, by start time.
+func GetSlotsFrom(db *database.DB, since time.Time) ([]*schedulemodel.Slot, error) {
+	query := `SELECT ` + slotColumns + `
+			  FROM court_slots s JOIN courts c ON c.id = s.court_id
+			  WHERE s.ends_at > $1
+			  ORDER BY s.starts_at, c.name`
+	rows, err := db.Query(query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slots []*schedulemodel.Slot
+	for rows.Next() {
+		slot, err := scanSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+	return slots, rows.Err()
+}
+
+// GetCourtConflicts returns the slots on the court that overlap the given
+// time range.
+func GetCourtConflicts(db database.Querier, courtID int, startsAt, endsAt time.Time) ([]schedulemodel.Conflict, error) {
+	query := `SELECT s.id, c.name, s.starts_at, s.ends_at, s.match_id, ''
+			  FROM court_slots s JOIN courts c ON c.id = s.court_id
+			  WHERE s.court_id = $1 AND s.starts_at < $3 AND s.ends_at > $2
+			  ORDER BY s.starts_at`
+	return queryConflicts(db, query, courtID, startsAt, endsAt)
+}
+
+// GetPlayerConflicts returns the booked slots overlapping the given time
+// range whose match shares a player with the match, one row per shared
+// player.
+func GetPlayerConflicts(db database.Querier, matchID int, startsAt, endsAt time.Time) ([]schedulemodel.Conflict, error) {
+	query := `SELECT s.id, c.name, s.starts_at, s.ends_at, s.match_id, p.name
+			  FROM court_slots s
+			  JOIN courts c ON c.id = s.court_id
+			  JOIN matches other ON other.id = s.match_id
+			  JOIN matches m ON m.id = $1
+			  JOIN players p
+			    ON p.id IN (m.team1_player1_id, m.team1_player2_id, m.team2_player1_id, m.team2_player2_id)
+			   AND p.id IN (other.team1_player1_id, other.team1_player2_id, other.team2_player1_id, other.team2_player2_id)
+			  WHERE s.match_id <> $1 AND s.starts_at < $3 AND s.ends_at > $2
+			  ORDER BY s.starts_at, p.name`
+	return queryConflicts(db, query, matchID, startsAt, endsAt)
+}
+
+// BookSlot puts the match in the slot, releasing any slot it held before.
+func BookSlot(db database.Querier, slotID, matchID int) error {
+	if _, err := db.Exec(`UPDATE court_slots SET match_id = NULL, updated_at = CURRENT_TIMESTAMP
+						  WHERE match_id = $1 AND id <> $2`, matchID, slotID); err != nil {
+		return err
+	}
+	_, err := db.Exec(`UPDATE court_slots SET match_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, matchID, slotID)
+	return err
+}
+
+func ReleaseSlot(db *database.DB, id int) error {
+	_, err := db.Exec(`UPDATE court_slots SET match_id = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, id)
+	return err
+}
+
+func DeleteSlot(db *database.DB, id int) error {
+	_, err := db.Exec(`DELETE FROM court_slots WHERE id = $1`, id)
+	return err
+}
+
+// GetUpcomingMatchesByPlayer returns the player's open matches planned from
+// today onwards, with their court booking if they have one.
+func GetUpcomingMatchesByPlayer(db *database.DB, playerID int) ([]schedulemodel.ScheduledMatch, error) {
+	query := `SELECT` + matchrepo.WithPlayersColumns + `,
+		c.name, s.ends_at` + matchrepo.WithPlayersFrom + `
+	LEFT JOIN court_slots s ON s.match_id = m.id
+	LEFT JOIN courts c ON c.id = s.court_id
+	WHERE $1 IN (m.team1_player1_id, m.team1_player2_id, m.team2_player1_id, m.team2_player2_id)
+	  AND m.completed_at IS NULL
+	  AND m.match_date >= CURRENT_DATE
+	ORDER BY m.match_date`
+	rows, err := db.Query(query, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []schedulemodel.ScheduledMatch
+	for rows.Next() {
+		var match schedulemodel.ScheduledMatch
+		if err := matchrepo.ScanWithPlayers(rows, &match.MatchWithPlayers, &match.CourtName, &match.EndsAt); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSlot(row scanner) (*schedulemodel.Slot, error) {
+	slot := &schedulemodel.Slot{}
+	err := row.Scan(
+		&slot.ID,
+		&slot.CourtID,
+		&slot.CourtName,
+		&slot.StartsAt,
+		&slot.EndsAt,
+		&slot.MatchID,
+		&slot.CreatedAt,
+		&slot.UpdatedAt)
+	return slot, err
+}
+
+func queryConflicts(db database.Querier, query string, args ...any) ([]schedulemodel.Conflict, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conflicts []schedulemodel.Conflict
+	for rows.Next() {
+		var conflict schedulemodel.Conflict
+		err := rows.Scan(
+			&conflict.SlotID,
+			&conflict.CourtName,
+			&conflict.StartsAt,
+			&conflict.EndsAt,
+			&conflict.MatchID,
+			&conflict.PlayerName)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, rows.Err()
+}
@@ -0,0 +1,116 @@
+package scheduleservice
+
+import (
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/schedule/schedulemodel"
+	"ct-padel-s/src/features/padel/schedule/schedulerepo"
+	"ct-padel-s/src/infrastructure/database"
+	"errors"
+	"strings"
+)
+
+// lockKey is the advisory lock that serialises bookings, so two overlapping
+// requests can't both pass the conflict checks
+const lockKey = 3801
+
+var (
+	ErrSlotNotFound  = errors.New("slot not found")
+	ErrMatchNotFound = errors.New("match not found")
+)
+
+// ConflictError lists the existing bookings that clash with a request.
+type ConflictError struct {
+	Conflicts []schedulemodel.Conflict
+}
+
+func (e *ConflictError) Error() string {
+	messages := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		messages[i] = conflict.String()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// CreateSlot adds a slot to a court, refusing one that overlaps another
+// slot on the same court.
+func CreateSlot(db *database.DB, slot *schedulemodel.Slot) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, lockKey); err != nil {
+		return err
+	}
+
+	conflicts, err := schedulerepo.GetCourtConflicts(tx, slot.CourtID, slot.StartsAt, slot.EndsAt)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+
+	if err := schedulerepo.CreateSlot(tx, slot); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// BookSlot schedules the match in the slot and moves its planned start to
+// the start of the slot. It refuses a slot held by another match and a time
+// when any of the match's players is booked elsewhere. A match already
+// booked into another slot is moved.
+func BookSlot(db *database.DB, slotID, matchID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, lockKey); err != nil {
+		return err
+	}
+
+	slot, err := schedulerepo.GetSlot(tx, slotID)
+	if err != nil {
+		return err
+	}
+	if slot == nil {
+		return ErrSlotNotFound
+	}
+	if slot.Booked() && slot.MatchID.Int64 != int64(matchID) {
+		return &ConflictError{Conflicts: []schedulemodel.Conflict{{
+			SlotID:    slot.ID,
+			CourtName: slot.CourtName,
+			StartsAt:  slot.StartsAt,
+			EndsAt:    slot.EndsAt,
+			MatchID:   slot.MatchID,
+		}}}
+	}
+
+	match, err := matchrepo.GetMatch(tx, matchID)
+	if err != nil {
+		return err
+	}
+	if match == nil {
+		return ErrMatchNotFound
+	}
+
+	conflicts, err := schedulerepo.GetPlayerConflicts(tx, matchID, slot.StartsAt, slot.EndsAt)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+
+	if err := schedulerepo.BookSlot(tx, slot.ID, matchID); err != nil {
+		return err
+	}
+	if err := matchrepo.SetMatchDate(tx, matchID, slot.StartsAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
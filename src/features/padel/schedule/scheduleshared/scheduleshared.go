@@ -0,0 +1,20 @@
+package scheduleshared
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// DateTimeLayout is the format of datetime-local form inputs
+const DateTimeLayout = "2006-01-02T15:04"
+
+func GetSlotID(w http.ResponseWriter, r *http.Request) int {
+	slotID := r.PathValue("slotID")
+	id, err := strconv.Atoi(slotID)
+	if err != nil {
+		slog.Error("Invalid slot ID", "error", err)
+		return 0
+	}
+	return id
+}
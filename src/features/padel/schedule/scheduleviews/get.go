@@ -0,0 +1,62 @@
+package scheduleviews
+
+import (
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/schedule/schedulemodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+)
+
+//go:embed get.html
+var getHTML string
+var getComponent = utils.NewComponent("get.html", getHTML)
+
+//go:embed getbreadcrumb.html
+var getBreadcrumbHTML string
+var getBreadcrumbComponent = utils.NewComponent("getbreadcrumb.html", getBreadcrumbHTML)
+
+type slotView struct {
+	Slot  *schedulemodel.Slot
+	Match *matchmodel.MatchWithPlayers
+}
+
+type dayView struct {
+	Date  string
+	Slots []slotView
+}
+
+// RenderGet shows the slots grouped by day. matches are used to name booked
+// slots; the open ones can be booked into free slots.
+func RenderGet(courts []*schedulemodel.Court, slots []*schedulemodel.Slot, matches []matchmodel.MatchWithPlayers) (template.HTML, error) {
+	byID := make(map[int64]*matchmodel.MatchWithPlayers, len(matches))
+	var open []*matchmodel.MatchWithPlayers
+	for i := range matches {
+		match := &matches[i]
+		byID[int64(match.ID)] = match
+		if !match.CompletedAt.Valid {
+			open = append(open, match)
+		}
+	}
+
+	var days []dayView
+	for _, slot := range slots {
+		date := slot.StartsAt.Format("Monday 02 January 2006")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, dayView{Date: date})
+		}
+		day := &days[len(days)-1]
+		day.Slots = append(day.Slots, slotView{Slot: slot, Match: byID[slot.MatchID.Int64]})
+	}
+
+	return getComponent.Render(map[string]any{
+		"Courts":             courts,
+		"Days":               days,
+		"OpenMatches":        open,
+		"DefaultSlotMinutes": schedulemodel.DefaultSlotMinutes,
+	})
+}
+
+func RenderGetBreadcrumb() (template.HTML, error) {
+	return getBreadcrumbComponent.Render(nil)
+}
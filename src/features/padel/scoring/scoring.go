@@ -0,0 +1,146 @@
+// Package scoring keeps the score of a padel match point by point: games
+// with advantage (or golden point), sets to six games with a tiebreak at
+// six all, and matches best of three sets.
+package scoring
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"fmt"
+)
+
+// Rules are the format of a match.
+type Rules struct {
+	// SetsToWin is the number of sets that wins the match
+	SetsToWin int
+	// GamesToWin is the number of games that wins a set by two; at this
+	// many games all the set goes to a tiebreak
+	GamesToWin int
+	// TiebreakPoints is the number of points that wins a tiebreak by two
+	TiebreakPoints int
+	// GoldenPoint decides a game at deuce with a single point
+	GoldenPoint bool
+}
+
+// DefaultRules are best of three sets of six games with advantage scoring.
+var DefaultRules = Rules{SetsToWin: 2, GamesToWin: 6, TiebreakPoints: 7}
+
+// TiebreakGame is the number within its set of a game that is a tiebreak.
+func (r Rules) TiebreakGame() int {
+	return 2*r.GamesToWin + 1
+}
+
+// TiebreakTurns is how many times serve has changed hands after played
+// points of a tiebreak: the player due to serve serves the first point, then
+// serve passes on after every two points.
+func TiebreakTurns(played int) int {
+	return (played + 1) / 2
+}
+
+// Event reports what a point decided.
+type Event struct {
+	Game  bool
+	Set   bool
+	Match bool
+}
+
+// Score is the state of a match. Counts are indexed by analyticsmodel.Team.
+type Score struct {
+	Rules Rules
+	// Sets holds the games of every set played so far, the current one last
+	Sets    [][3]int
+	SetsWon [3]int
+	Points  [3]int
+	// Games is the number of games played, which decides the server
+	Games  int
+	Winner analyticsmodel.Team
+}
+
+func New(rules Rules) *Score {
+	return &Score{Rules: rules, Sets: [][3]int{{}}}
+}
+
+// Tiebreak reports whether the current game is a tiebreak.
+func (s *Score) Tiebreak() bool {
+	games := s.Sets[len(s.Sets)-1]
+	return games[analyticsmodel.Team1] == s.Rules.GamesToWin && games[analyticsmodel.Team2] == s.Rules.GamesToWin
+}
+
+// Server returns which of the four players serves the current game, in
+// serving order: team 1's first server, team 2's first server, team 1's
+// second server, team 2's second server. In a tiebreak serve moves on
+// through the same order after the first point and then every two points.
+func (s *Score) Server() int {
+	if s.Tiebreak() {
+		return (s.Games + TiebreakTurns(s.Points[analyticsmodel.Team1]+s.Points[analyticsmodel.Team2])) % 4
+	}
+	return s.Games % 4
+}
+
+// GamePoints shows the points of the game in progress from team 1's side:
+// the number won in a tiebreak, otherwise 0, 15, 30 and 40, then 40-40 and
+// AD-40 or 40-AD once both teams reach 40.
+func (s *Score) GamePoints() string {
+	a, b := s.Points[analyticsmodel.Team1], s.Points[analyticsmodel.Team2]
+	switch {
+	case s.Tiebreak():
+		return fmt.Sprintf("%d-%d", a, b)
+	case a >= 3 && b >= 3 && a == b:
+		return "40-40"
+	case a >= 3 && b >= 3 && a > b:
+		return "AD-40"
+	case a >= 3 && b >= 3:
+		return "40-AD"
+	}
+	return gamePoints[a] + "-" + gamePoints[b]
+}
+
+var gamePoints = []string{"0", "15", "30", "40"}
+
+// Point awards a point to team and reports what it decided. Points after
+// the match is over are ignored.
+func (s *Score) Point(team analyticsmodel.Team) Event {
+	if s.Winner != analyticsmodel.TeamUnknown || team == analyticsmodel.TeamUnknown {
+		return Event{}
+	}
+
+	s.Points[team]++
+	if !s.gameWon(team) {
+		return Event{}
+	}
+
+	event := Event{Game: true}
+	s.Points = [3]int{}
+	s.Games++
+	games := &s.Sets[len(s.Sets)-1]
+	games[team]++
+	if !s.setWon(team, *games) {
+		return event
+	}
+
+	event.Set = true
+	s.SetsWon[team]++
+	if s.SetsWon[team] == s.Rules.SetsToWin {
+		event.Match = true
+		s.Winner = team
+		return event
+	}
+	s.Sets = append(s.Sets, [3]int{})
+	return event
+}
+
+func (s *Score) gameWon(team analyticsmodel.Team) bool {
+	won, lost := s.Points[team], s.Points[team.Opponent()]
+	if s.Tiebreak() {
+		return won >= s.Rules.TiebreakPoints && won-lost >= 2
+	}
+	if s.Rules.GoldenPoint {
+		return won >= 4
+	}
+	return won >= 4 && won-lost >= 2
+}
+
+func (s *Score) setWon(team analyticsmodel.Team, games [3]int) bool {
+	won, lost := games[team], games[team.Opponent()]
+	// A tiebreak win takes the set to GamesToWin+1 against GamesToWin
+	return won >= s.Rules.GamesToWin && (won-lost >= 2 || won == s.Rules.GamesToWin+1)
+}
@@ -0,0 +1,203 @@
+package scoring
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"testing"
+)
+
+const (
+	t1 = analyticsmodel.Team1
+	t2 = analyticsmodel.Team2
+)
+
+// play awards points in order, each to the team named by its letter, and
+// returns the last event.
+func play(s *Score, points string) Event {
+	var event Event
+	for _, p := range points {
+		team := t1
+		if p == '2' {
+			team = t2
+		}
+		event = s.Point(team)
+	}
+	return event
+}
+
+// games gives team the next n games, leaving the score between games.
+func games(s *Score, team analyticsmodel.Team, n int) {
+	for range n {
+		for !s.Point(team).Game {
+		}
+	}
+}
+
+func TestGame(t *testing.T) {
+	tests := []struct {
+		name   string
+		golden bool
+		points string
+		game   bool
+		shown  string
+	}{
+		{"love thirty", false, "22", false, "0-30"},
+		{"forty fifteen", false, "1121", false, "40-15"},
+		{"game to love", false, "1111", true, "0-0"},
+		{"deuce", false, "111222", false, "40-40"},
+		{"advantage", false, "1112221", false, "AD-40"},
+		{"advantage receiver", false, "1112222", false, "40-AD"},
+		{"back to deuce", false, "11122212", false, "40-40"},
+		{"won from advantage", false, "11122211", true, "0-0"},
+		{"golden point", true, "1112221", true, "0-0"},
+		{"golden point receiver", true, "1112222", true, "0-0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules := DefaultRules
+			rules.GoldenPoint = test.golden
+			s := New(rules)
+			event := play(s, test.points)
+			if event.Game != test.game {
+				t.Errorf("game decided = %v, want %v", event.Game, test.game)
+			}
+			if got := s.GamePoints(); got != test.shown {
+				t.Errorf("GamePoints() = %q, want %q", got, test.shown)
+			}
+		})
+	}
+}
+
+func TestTiebreak(t *testing.T) {
+	tests := []struct {
+		name   string
+		points string
+		team1  int
+		team2  int
+	}{
+		{"seven five", "111111222221", 7, 6},
+		{"eight six", "11111122222211", 7, 6},
+		{"eight six to team 2", "22222211111122", 6, 7},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := sixAll()
+			if !s.Tiebreak() {
+				t.Fatal("6-6 is not a tiebreak")
+			}
+			event := play(s, test.points[:len(test.points)-1])
+			if event.Game {
+				t.Fatalf("tiebreak decided early at %s", s.GamePoints())
+			}
+			event = play(s, test.points[len(test.points)-1:])
+			if !event.Game || !event.Set {
+				t.Fatalf("last point decided %+v, want the game and the set", event)
+			}
+			if got := s.Sets[0]; got[t1] != test.team1 || got[t2] != test.team2 {
+				t.Errorf("set = %d-%d, want %d-%d", got[t1], got[t2], test.team1, test.team2)
+			}
+		})
+	}
+}
+
+func TestTiebreakPoints(t *testing.T) {
+	s := sixAll()
+	play(s, "1112221")
+	if got, want := s.GamePoints(), "4-3"; got != want {
+		t.Errorf("GamePoints() = %q, want %q", got, want)
+	}
+}
+
+func TestTiebreakServer(t *testing.T) {
+	s := sixAll()
+	// Twelve games have been played, so the first server opens the
+	// tiebreak; serve passes on after the first point, then every two
+	want := []int{0, 1, 1, 2, 2, 3, 3, 0, 0, 1}
+	for i, server := range want {
+		if got := s.Server(); got != server {
+			t.Errorf("point %d: Server() = %d, want %d", i+1, got, server)
+		}
+		s.Point(analyticsmodel.Team(1 + i%2))
+	}
+}
+
+func TestServerAfterTiebreak(t *testing.T) {
+	s := sixAll()
+	play(s, "1111111")
+	// The tiebreak counts as one game, so serve moves on from whoever
+	// opened it
+	if got, want := s.Server(), 1; got != want {
+		t.Errorf("Server() = %d, want %d", got, want)
+	}
+}
+
+func TestMatchInStraightSets(t *testing.T) {
+	s := New(DefaultRules)
+	games(s, t2, 6)
+	games(s, t2, 5)
+	if event := play(s, "2222"); !event.Match {
+		t.Fatalf("second set decided %+v, want the match", event)
+	}
+	if s.Winner != t2 || s.SetsWon[t2] != 2 {
+		t.Errorf("winner %v with sets won %v, want team 2 with 2", s.Winner, s.SetsWon)
+	}
+	if len(s.Sets) != 2 {
+		t.Errorf("played %d sets, want 2", len(s.Sets))
+	}
+}
+
+func TestMatchInThreeSets(t *testing.T) {
+	s := New(DefaultRules)
+	games(s, t1, 6)
+	if s.SetsWon[t1] != 1 || s.Winner != analyticsmodel.TeamUnknown {
+		t.Fatalf("after one set: sets won %v, winner %v", s.SetsWon, s.Winner)
+	}
+	games(s, t2, 6)
+	if s.SetsWon[t2] != 1 || s.Winner != analyticsmodel.TeamUnknown {
+		t.Fatalf("after two sets: sets won %v, winner %v", s.SetsWon, s.Winner)
+	}
+	games(s, t1, 5)
+	event := play(s, "111")
+	if event.Match {
+		t.Fatal("match decided before the last point")
+	}
+	event = play(s, "1")
+	if !event.Match || s.Winner != t1 {
+		t.Fatalf("last point decided %+v with winner %v, want the match to team 1", event, s.Winner)
+	}
+	if len(s.Sets) != 3 {
+		t.Errorf("played %d sets, want 3", len(s.Sets))
+	}
+
+	// Points after the match are ignored
+	if event := s.Point(t2); event != (Event{}) {
+		t.Errorf("point after the match decided %+v", event)
+	}
+	if s.Points != ([3]int{}) {
+		t.Errorf("points after the match = %v, want none", s.Points)
+	}
+}
+
+func TestSetWonByTwo(t *testing.T) {
+	s := New(DefaultRules)
+	games(s, t1, 5)
+	games(s, t2, 5)
+	games(s, t1, 1)
+	if s.SetsWon[t1] != 0 {
+		t.Fatal("set won at 6-5")
+	}
+	games(s, t1, 1)
+	if s.SetsWon[t1] != 1 {
+		t.Errorf("set not won at 7-5")
+	}
+}
+
+// sixAll returns a score at 6-6 in the first set, with the games served
+// alternately.
+func sixAll() *Score {
+	s := New(DefaultRules)
+	for range 6 {
+		games(s, t1, 1)
+		games(s, t2, 1)
+	}
+	return s
+}
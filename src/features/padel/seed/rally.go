@@ -0,0 +1,196 @@
+package seed
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"ct-padel-s/src/features/padel/court"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"math/rand/v2"
+	"time"
+)
+
+// maxShots ends a rally that has gone on implausibly long with an error
+const maxShots = 40
+
+// profile is how a generated player plays.
+type profile struct {
+	// aces and doubleFaults are the chances a serve ends the point
+	aces, doubleFaults float64
+	// winners and errors are the chances any other shot ends the point
+	winners, errors float64
+	// unforced is the share of errors that are unforced
+	unforced float64
+	// net is how often the player takes the ball at the net
+	net float64
+}
+
+func newProfile(rng *rand.Rand) profile {
+	return profile{
+		aces:         0.02 + rng.Float64()*0.05,
+		doubleFaults: 0.02 + rng.Float64()*0.04,
+		winners:      0.06 + rng.Float64()*0.08,
+		errors:       0.06 + rng.Float64()*0.08,
+		unforced:     0.3 + rng.Float64()*0.4,
+		net:          0.3 + rng.Float64()*0.4,
+	}
+}
+
+// shot is one generated play.
+type shot struct {
+	player  int
+	contact playmodel.ContactType
+	effect  playmodel.ShotEffect
+	hand    playmodel.HandSide
+	x, y    int
+	result  playmodel.ResultType
+	// walls and exit are where the ball went after it bounced
+	walls []string
+	exit  playmodel.ExitCourt
+	gap   time.Duration
+}
+
+// side is a player's place in a match: their team and which half of the
+// court they cover.
+type side struct {
+	team analyticsmodel.Team
+	left bool
+}
+
+// rally generates a point served by players[server], with players in
+// serving order: team 1's first server, team 2's first server, team 1's
+// second server, team 2's second server. Teams take turns, only the first
+// shot is a serve, smashes, bandejas and viboras are overheads and the last
+// shot carries the result. It returns the shots and the team that won the point.
+func rally(rng *rand.Rand, players [4]int, profiles map[int]profile, server int) ([]shot, analyticsmodel.Team) {
+	sides := map[int]side{
+		players[0]: {analyticsmodel.Team1, true},
+		players[1]: {analyticsmodel.Team2, true},
+		players[2]: {analyticsmodel.Team1, false},
+		players[3]: {analyticsmodel.Team2, false},
+	}
+
+	var shots []shot
+	hitter := players[server]
+	for {
+		s := shot{player: hitter, gap: time.Duration(1200+rng.IntN(1000)) * time.Millisecond}
+		p := profiles[hitter]
+		first := len(shots) == 0
+
+		var winner, err float64
+		if first {
+			s.contact, s.effect, s.hand = playmodel.ContactServe, pick(rng, playmodel.EffectFlat, playmodel.EffectLob), playmodel.HandForehand
+			winner, err = p.aces, p.doubleFaults
+		} else {
+			s.contact = contact(rng, p, len(shots))
+			s.effect = effect(rng, s.contact)
+			s.hand = pick(rng, playmodel.HandForehand, playmodel.HandBackhand)
+			winner, err = p.winners, p.errors
+			if s.effect == playmodel.EffectSmash {
+				winner *= 2.5
+			}
+		}
+		s.x, s.y = position(rng, sides[hitter], s.contact)
+
+		// A groundstroke from behind the service line is often played off
+		// the back glass, sometimes after the side glass too when it is
+		// played near it
+		if !first && s.contact == playmodel.ContactGroundstroke && court.BehindServiceLine(s.y) && rng.Float64() < 0.5 {
+			previous := &shots[len(shots)-1]
+			previous.walls = []string{string(court.WallBackGlass)}
+			if court.WallDistance(s.x, s.y, court.WallSideGlass) <= court.WallReach && rng.Float64() < 0.25 {
+				previous.walls = append(previous.walls, string(court.WallSideGlass))
+			}
+		}
+
+		roll := rng.Float64()
+		switch {
+		case roll < winner:
+			s.result = playmodel.ResultNoReturnWinner
+			if s.effect == playmodel.EffectSmash && rng.Float64() < 0.3 {
+				s.exit = pick(rng, playmodel.ExitOverWall, playmodel.ExitDoor)
+			}
+		case roll < winner+err || len(shots)+1 == maxShots:
+			s.result = playmodel.ResultError
+			if first || rng.Float64() < p.unforced {
+				s.result = playmodel.ResultUnforcedError
+			}
+		}
+		shots = append(shots, s)
+
+		if s.result != "" {
+			team := sides[hitter].team
+			if s.result != playmodel.ResultNoReturnWinner {
+				team = team.Opponent()
+			}
+			return shots, team
+		}
+
+		// The ball goes over to either player of the other team; teams sit
+		// at alternate places in serving order
+		hitter = players[(indexOf(players, hitter)+1)%2+2*rng.IntN(2)]
+	}
+}
+
+func contact(rng *rand.Rand, p profile, shotsSoFar int) playmodel.ContactType {
+	// The return of serve is a groundstroke
+	if shotsSoFar == 1 {
+		return playmodel.ContactGroundstroke
+	}
+	roll := rng.Float64()
+	switch {
+	case roll < p.net*0.7:
+		return playmodel.ContactVolley
+	case roll < p.net:
+		return playmodel.ContactOverhead
+	}
+	return playmodel.ContactGroundstroke
+}
+
+func effect(rng *rand.Rand, contact playmodel.ContactType) playmodel.ShotEffect {
+	switch contact {
+	case playmodel.ContactOverhead:
+		return pick(rng, playmodel.EffectSmash, playmodel.EffectBandeja, playmodel.EffectBandeja, playmodel.EffectVibora, playmodel.EffectLob)
+	case playmodel.ContactVolley:
+		return pick(rng, playmodel.EffectFlat, playmodel.EffectDown, playmodel.EffectDrop, playmodel.EffectBajada)
+	}
+	return pick(rng, playmodel.EffectFlat, playmodel.EffectLob, playmodel.EffectDown, playmodel.EffectDrop, playmodel.EffectChiquita)
+}
+
+// position returns where the shot was hit: serves and groundstrokes from
+// behind the service line, volleys near the net and overheads in between,
+// in the hitter's half of their own side. Team 1 plays the far end of the
+// court (y above the net at 10000), team 2 the near end.
+func position(rng *rand.Rand, s side, contact playmodel.ContactType) (int, int) {
+	var depth int
+	switch contact {
+	case playmodel.ContactServe:
+		depth = 7200 + rng.IntN(2300)
+	case playmodel.ContactGroundstroke:
+		depth = 6500 + rng.IntN(3300)
+	case playmodel.ContactVolley:
+		depth = 400 + rng.IntN(2600)
+	case playmodel.ContactOverhead:
+		depth = 2500 + rng.IntN(4000)
+	}
+
+	x := 300 + rng.IntN(4500)
+	if !s.left {
+		x += 4900
+	}
+	if s.team == analyticsmodel.Team1 {
+		return x, court.NetY + depth
+	}
+	return court.Width - x, court.NetY - depth
+}
+
+func pick[T any](rng *rand.Rand, options ...T) T {
+	return options[rng.IntN(len(options))]
+}
+
+func indexOf(players [4]int, player int) int {
+	for i, p := range players {
+		if p == player {
+			return i
+		}
+	}
+	return -1
+}
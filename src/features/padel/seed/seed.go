@@ -0,0 +1,168 @@
+// Package seed fills a development database with generated players and
+// complete matches, so stats pages have realistic data to show. The same
+// seed value always generates the same data.
+package seed
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"ct-padel-s/src/features/padel/game/gamemodel"
+	"ct-padel-s/src/features/padel/game/gamerepo"
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/match/matchservice"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/play/playrepo"
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"ct-padel-s/src/features/padel/player/playerrepo"
+	"ct-padel-s/src/features/padel/point/pointmodel"
+	"ct-padel-s/src/features/padel/point/pointrepo"
+	"ct-padel-s/src/features/padel/scoring"
+	"ct-padel-s/src/features/padel/set/setmodel"
+	"ct-padel-s/src/features/padel/set/setrepo"
+	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+var names = []string{
+	"Ana", "Bruno", "Carla", "Diego", "Elena", "Fernando", "Gemma", "Hugo",
+	"Inés", "Javier", "Lucía", "Marcos", "Nuria", "Óscar", "Paula", "Raúl",
+}
+
+// Options say how much to generate.
+type Options struct {
+	Seed    uint64
+	Players int
+	Matches int
+	// Start is when the first match starts; the rest follow a day apart
+	Start time.Time
+}
+
+// Summary counts what was generated.
+type Summary struct {
+	Players int
+	Matches int
+	Points  int
+	Plays   int
+}
+
+// Run generates the players, then the matches between random foursomes of
+// them, recorded live point by point, completed and rated.
+func Run(db *database.DB, options Options) (*Summary, error) {
+	if options.Players < 4 {
+		return nil, errors.New("at least 4 players are needed for a match")
+	}
+
+	rng := rand.New(rand.NewPCG(options.Seed, options.Seed))
+	summary := &Summary{}
+
+	players := make([]int, options.Players)
+	profiles := map[int]profile{}
+	for i := range players {
+		name := names[i%len(names)]
+		if i >= len(names) {
+			name = fmt.Sprintf("%s %d", name, i/len(names)+1)
+		}
+		player := playermodel.Player{Name: name}
+		if err := playerrepo.CreatePlayer(db, &player); err != nil {
+			return nil, err
+		}
+		players[i] = player.ID
+		profiles[player.ID] = newProfile(rng)
+		summary.Players++
+	}
+
+	for i := range options.Matches {
+		var foursome [4]int
+		for j, k := range rng.Perm(len(players))[:4] {
+			foursome[j] = players[k]
+		}
+		if err := generateMatch(db, rng, foursome, profiles, options.Start.AddDate(0, 0, i), summary); err != nil {
+			return nil, err
+		}
+		summary.Matches++
+	}
+	return summary, nil
+}
+
+// generateMatch records one match between players, in serving order, that
+// starts at start.
+func generateMatch(db *database.DB, rng *rand.Rand, players [4]int, profiles map[int]profile, start time.Time, summary *Summary) error {
+	match := matchmodel.Match{
+		Team1Player1ID: players[0],
+		Team1Player2ID: players[2],
+		Team2Player1ID: players[1],
+		Team2Player2ID: players[3],
+		MatchDate:      start,
+	}
+	if err := matchrepo.CreateMatch(db, &match); err != nil {
+		return err
+	}
+
+	score := scoring.New(scoring.DefaultRules)
+	clock := start
+	var set *setmodel.Set
+	var game *gamemodel.Game
+	for score.Winner == analyticsmodel.TeamUnknown {
+		if set == nil {
+			set = &setmodel.Set{MatchID: match.ID}
+			if err := setrepo.CreateSet(db, set); err != nil {
+				return err
+			}
+		}
+		if game == nil {
+			game = &gamemodel.Game{SetID: set.ID}
+			if err := gamerepo.CreateGame(db, game); err != nil {
+				return err
+			}
+		}
+
+		point := pointmodel.Point{GameID: game.ID}
+		if err := pointrepo.CreatePoint(db, &point); err != nil {
+			return err
+		}
+		shots, winner := rally(rng, players, profiles, score.Server())
+		for _, s := range shots {
+			clock = clock.Add(s.gap)
+			play := playmodel.Play{
+				PointID:       point.ID,
+				PlayerID:      sql.NullInt64{Int64: int64(s.player), Valid: true},
+				BallPositionX: s.x,
+				BallPositionY: s.y,
+				ResultType:    sql.NullString{String: string(s.result), Valid: s.result != ""},
+				HandSide:      sql.NullString{String: string(s.hand), Valid: true},
+				ContactType:   sql.NullString{String: string(s.contact), Valid: true},
+				ShotEffect:    sql.NullString{String: string(s.effect), Valid: true},
+				WallBounces:   s.walls,
+				ExitCourt:     sql.NullString{String: string(s.exit), Valid: s.exit != ""},
+				HitAt:         sql.NullTime{Time: clock, Valid: true},
+			}
+			if err := playrepo.CreatePlay(db, &play); err != nil {
+				return err
+			}
+			summary.Plays++
+		}
+		if _, err := pointrepo.EndPoint(db, point.ID, clock.Add(time.Second)); err != nil {
+			return err
+		}
+		summary.Points++
+
+		// Players take a breather between points and change ends between
+		// games
+		clock = clock.Add(time.Duration(15+rng.IntN(10)) * time.Second)
+		event := score.Point(winner)
+		if event.Game {
+			game = nil
+			clock = clock.Add(time.Minute)
+		}
+		if event.Set {
+			set = nil
+			clock = clock.Add(2 * time.Minute)
+		}
+	}
+
+	return matchservice.Complete(db, match.ID)
+}
@@ -3,12 +3,31 @@ package setrepo
 import (
 	"ct-padel-s/src/features/padel/set/setmodel"
 	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
 )
 
+// CreateSet inserts set as the next set of its match, assigning set.SetNumber.
+// The match row is locked for the duration so concurrent creates are numbered
+// one after the other instead of colliding on UNIQUE(match_id, set_number).
 func CreateSet(db *database.DB, set *setmodel.Set) error {
-	query := `INSERT INTO sets (match_id, set_number) VALUES ($1, $2) RETURNING id, created_at, updated_at`
-	err := db.QueryRow(query, set.MatchID, set.SetNumber).Scan(&set.ID, &set.CreatedAt, &set.UpdatedAt)
-	return err
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM matches WHERE id = $1 FOR UPDATE`, set.MatchID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO sets (match_id, set_number)
+			  SELECT $1, COALESCE(MAX(set_number), 0) + 1 FROM sets WHERE match_id = $1
+			  RETURNING id, set_number, created_at, updated_at`
+	if err := tx.QueryRow(query, set.MatchID).Scan(&set.ID, &set.SetNumber, &set.CreatedAt, &set.UpdatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func GetAllSets(db *database.DB) ([]*setmodel.Set, error) {
@@ -55,32 +74,47 @@ func GetSet(db *database.DB, setID int) (*setmodel.Set, error) {
 	query := `SELECT id, match_id, set_number, created_at, updated_at FROM sets WHERE id = $1`
 	var set setmodel.Set
 	err := db.QueryRow(query, setID).Scan(&set.ID, &set.MatchID, &set.SetNumber, &set.CreatedAt, &set.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	return &set, err
 }
 
+// DeleteSet deletes the set and renumbers the remaining sets.
 func DeleteSet(db *database.DB, setID int) error {
-	// First get the set to know its match_id and set_number
-	set, err := GetSet(db, setID)
+	// Begin transaction
+	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// Begin transaction
-	tx, err := db.Begin()
+	// Lock the match so deletes and creates of siblings do not interleave
+	var matchID int
+	err = tx.QueryRow(`SELECT match_id FROM sets WHERE id = $1`, setID).Scan(&matchID)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	if _, err := tx.Exec(`SELECT id FROM matches WHERE id = $1 FOR UPDATE`, matchID); err != nil {
+		return err
+	}
+
+	// Renumbering shifts siblings onto each other's numbers, so only check
+	// uniqueness once the whole transaction is done
+	if _, err := tx.Exec(`SET CONSTRAINTS ALL DEFERRED`); err != nil {
+		return err
+	}
 
 	// Delete the set
-	_, err = tx.Exec(`DELETE FROM sets WHERE id = $1`, setID)
+	var setNumber int
+	err = tx.QueryRow(`DELETE FROM sets WHERE id = $1 RETURNING set_number`, setID).Scan(&setNumber)
 	if err != nil {
 		return err
 	}
 
 	// Update set numbers for sets with higher numbers in the same match
-	_, err = tx.Exec(`UPDATE sets SET set_number = set_number - 1 WHERE match_id = $1 AND set_number > $2`, 
-		set.MatchID, set.SetNumber)
+	_, err = tx.Exec(`UPDATE sets SET set_number = set_number - 1 WHERE match_id = $1 AND set_number > $2`,
+		matchID, setNumber)
 	if err != nil {
 		return err
 	}
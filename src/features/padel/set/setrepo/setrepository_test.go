@@ -0,0 +1,31 @@
+package setrepo
+
+import (
+	"ct-padel-s/src/features/padel/set/setmodel"
+	"ct-padel-s/src/infrastructure/database/databasetest"
+	"testing"
+)
+
+const concurrentCreates = 20
+
+func TestCreateSetConcurrently(t *testing.T) {
+	db := databasetest.Open(t)
+	matchID := databasetest.Match(t, db)
+
+	databasetest.Concurrently(t, concurrentCreates, func() error {
+		return CreateSet(db, &setmodel.Set{MatchID: matchID})
+	})
+
+	sets, err := GetSetsByMatch(db, matchID)
+	if err != nil {
+		t.Fatalf("GetSetsByMatch: %v", err)
+	}
+	if len(sets) != concurrentCreates {
+		t.Fatalf("got %d sets, want %d", len(sets), concurrentCreates)
+	}
+	numbers := make([]int, len(sets))
+	for i, set := range sets {
+		numbers[i] = set.SetNumber
+	}
+	databasetest.Numbered(t, numbers)
+}
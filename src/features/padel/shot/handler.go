@@ -0,0 +1,293 @@
+package shot
+
+import (
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/shot/shotmodel"
+	"ct-padel-s/src/features/padel/shot/shotrepo"
+	"ct-padel-s/src/features/padel/shot/shotviews"
+	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/shared/components/footer"
+	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
+	"ct-padel-s/src/shared/templates"
+	"database/sql"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func Get(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	catalogue, err := shotrepo.GetCatalogue(db)
+	if err != nil {
+		logger.Error("Failed to get shot catalogue", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get shot catalogue")
+		return
+	}
+
+	breadcrumb, err := shotviews.RenderGetBreadcrumb()
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load shared components
+	headerHTML, err := header.Render(header.Data{Title: "Shot Catalogue - Padel Tracker", Breadcrumb: breadcrumb})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	footerHTML, err := footer.Render(footer.Data{})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load feature content and render with data
+	contentHTML, err := shotviews.RenderGet(catalogue)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Compose final page
+	page, err := templates.Render(templates.Data{
+		Title:       "Shot Catalogue - Padel Tracker",
+		HeaderHTML:  headerHTML,
+		ContentHTML: contentHTML,
+		FooterHTML:  footerHTML,
+	})
+
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	io.WriteString(w, string(page))
+}
+
+func CreateContactType(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	catalogue, err := shotrepo.GetCatalogue(db)
+	if err != nil {
+		logger.Error("Failed to get shot catalogue", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get shot catalogue")
+		return
+	}
+
+	contact := shotmodel.ContactType{
+		Code:   strings.TrimSpace(r.FormValue("code")),
+		Label:  strings.TrimSpace(r.FormValue("label")),
+		Active: true,
+	}
+	if !shotmodel.CodePattern.MatchString(contact.Code) {
+		httperror.Write(w, r, http.StatusBadRequest, "Code must be lowercase letters and underscores")
+		return
+	}
+	if catalogue.ContactType(contact.Code) != nil {
+		httperror.Write(w, r, http.StatusConflict, "Contact type "+strconv.Quote(contact.Code)+" already exists")
+		return
+	}
+	if contact.Label == "" {
+		httperror.Write(w, r, http.StatusBadRequest, "Label is required")
+		return
+	}
+	contact.Position = len(catalogue.ContactTypes) + 1
+	if !position(w, r, &contact.Position) {
+		return
+	}
+
+	if err := shotrepo.CreateContactType(db, &contact); err != nil {
+		logger.Error("Failed to create contact type", "error", err, "code", contact.Code)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create contact type")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/shots")
+	w.WriteHeader(http.StatusCreated)
+}
+
+func UpdateContactType(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	catalogue, err := shotrepo.GetCatalogue(db)
+	if err != nil {
+		logger.Error("Failed to get shot catalogue", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get shot catalogue")
+		return
+	}
+
+	existing := catalogue.ContactType(r.PathValue("code"))
+	if existing == nil {
+		httperror.NotFound(w, r, "Contact type not found")
+		return
+	}
+
+	contact := *existing
+	contact.Label = strings.TrimSpace(r.FormValue("label"))
+	contact.Active = r.FormValue("active") != ""
+	if contact.Label == "" {
+		httperror.Write(w, r, http.StatusBadRequest, "Label is required")
+		return
+	}
+	// Rally checks and stats find the serve by its code
+	if contact.Code == string(playmodel.ContactServe) && !contact.Active {
+		httperror.Write(w, r, http.StatusBadRequest, "The serve cannot be retired")
+		return
+	}
+	if !position(w, r, &contact.Position) {
+		return
+	}
+
+	if err := shotrepo.UpdateContactType(db, &contact); err != nil {
+		logger.Error("Failed to update contact type", "error", err, "code", contact.Code)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to update contact type")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/shots")
+	w.WriteHeader(http.StatusOK)
+}
+
+func CreateEffect(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	catalogue, err := shotrepo.GetCatalogue(db)
+	if err != nil {
+		logger.Error("Failed to get shot catalogue", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get shot catalogue")
+		return
+	}
+
+	effect := shotmodel.Effect{
+		Code:   strings.TrimSpace(r.FormValue("code")),
+		Label:  strings.TrimSpace(r.FormValue("label")),
+		Active: true,
+	}
+	if !shotmodel.CodePattern.MatchString(effect.Code) {
+		httperror.Write(w, r, http.StatusBadRequest, "Code must be lowercase letters and underscores")
+		return
+	}
+	if catalogue.Effect(effect.Code) != nil {
+		httperror.Write(w, r, http.StatusConflict, "Shot effect "+strconv.Quote(effect.Code)+" already exists")
+		return
+	}
+	if effect.Label == "" {
+		httperror.Write(w, r, http.StatusBadRequest, "Label is required")
+		return
+	}
+	if !effectContactType(w, r, catalogue, &effect) {
+		return
+	}
+	effect.Position = len(catalogue.Effects) + 1
+	if !position(w, r, &effect.Position) {
+		return
+	}
+
+	if err := shotrepo.CreateEffect(db, &effect); err != nil {
+		logger.Error("Failed to create shot effect", "error", err, "code", effect.Code)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create shot effect")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/shots")
+	w.WriteHeader(http.StatusCreated)
+}
+
+func UpdateEffect(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	catalogue, err := shotrepo.GetCatalogue(db)
+	if err != nil {
+		logger.Error("Failed to get shot catalogue", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get shot catalogue")
+		return
+	}
+
+	existing := catalogue.Effect(r.PathValue("code"))
+	if existing == nil {
+		httperror.NotFound(w, r, "Shot effect not found")
+		return
+	}
+
+	effect := *existing
+	effect.Label = strings.TrimSpace(r.FormValue("label"))
+	effect.Active = r.FormValue("active") != ""
+	if effect.Label == "" {
+		httperror.Write(w, r, http.StatusBadRequest, "Label is required")
+		return
+	}
+	if !effectContactType(w, r, catalogue, &effect) {
+		return
+	}
+	if !position(w, r, &effect.Position) {
+		return
+	}
+
+	if err := shotrepo.UpdateEffect(db, &effect); err != nil {
+		logger.Error("Failed to update shot effect", "error", err, "code", effect.Code)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to update shot effect")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/shots")
+	w.WriteHeader(http.StatusOK)
+}
+
+// position reads the position field into p, leaving it unchanged when the
+// field is empty. It answers the request and returns false when the field is
+// not a number.
+func position(w http.ResponseWriter, r *http.Request, p *int) bool {
+	value := strings.TrimSpace(r.FormValue("position"))
+	if value == "" {
+		return true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid position")
+		return false
+	}
+	*p = n
+	return true
+}
+
+// effectContactType reads the contact type the effect is tied to, empty for
+// none. It answers the request and returns false when the contact type is
+// not in the catalogue.
+func effectContactType(w http.ResponseWriter, r *http.Request, catalogue *shotmodel.Catalogue, effect *shotmodel.Effect) bool {
+	code := r.FormValue("contact_type")
+	if code == "" {
+		effect.ContactType = sql.NullString{}
+		return true
+	}
+	if catalogue.ContactType(code) == nil {
+		httperror.Write(w, r, http.StatusBadRequest, "Unknown contact type "+strconv.Quote(code))
+		return false
+	}
+	effect.ContactType = sql.NullString{String: code, Valid: true}
+	return true
+}
@@ -0,0 +1,102 @@
+// Package shotmodel is the shot catalogue: the contact types and shot
+// effects a play can record. Entries are added and retired by editing the
+// catalogue; retired entries stay so plays that recorded them keep a label.
+package shotmodel
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+)
+
+// CodePattern is what a catalogue code looks like, matching the schema
+var CodePattern = regexp.MustCompile(`^[a-z][a-z_]*$`)
+
+type ContactType struct {
+	Code      string    `json:"code" db:"code"`
+	Label     string    `json:"label" db:"label"`
+	Position  int       `json:"position" db:"position"`
+	Active    bool      `json:"active" db:"active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type Effect struct {
+	Code  string `json:"code" db:"code"`
+	Label string `json:"label" db:"label"`
+	// ContactType is the only contact type the effect is played with, if
+	// it is tied to one
+	ContactType sql.NullString `json:"contact_type" db:"contact_type"`
+	Position    int            `json:"position" db:"position"`
+	Active      bool           `json:"active" db:"active"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Catalogue holds every entry, active or retired, in display order.
+type Catalogue struct {
+	ContactTypes []*ContactType `json:"contact_types"`
+	Effects      []*Effect      `json:"effects"`
+}
+
+// ContactType returns the contact type with code, or nil.
+func (c *Catalogue) ContactType(code string) *ContactType {
+	for _, contact := range c.ContactTypes {
+		if contact.Code == code {
+			return contact
+		}
+	}
+	return nil
+}
+
+// Effect returns the effect with code, or nil.
+func (c *Catalogue) Effect(code string) *Effect {
+	for _, effect := range c.Effects {
+		if effect.Code == code {
+			return effect
+		}
+	}
+	return nil
+}
+
+// ContactLabel returns the label of the contact type with code, or the code
+// itself when it is not in the catalogue.
+func (c *Catalogue) ContactLabel(code string) string {
+	if contact := c.ContactType(code); contact != nil {
+		return contact.Label
+	}
+	return code
+}
+
+// EffectLabel returns the label of the effect with code, or the code itself
+// when it is not in the catalogue.
+func (c *Catalogue) EffectLabel(code string) string {
+	if effect := c.Effect(code); effect != nil {
+		return effect.Label
+	}
+	return code
+}
+
+// ContactTypeChoices returns the contact types a play may be given: the
+// active ones, plus current if it has been retired since it was recorded.
+func (c *Catalogue) ContactTypeChoices(current string) []*ContactType {
+	var choices []*ContactType
+	for _, contact := range c.ContactTypes {
+		if contact.Active || contact.Code == current {
+			choices = append(choices, contact)
+		}
+	}
+	return choices
+}
+
+// EffectChoices returns the effects a play may be given: the active ones,
+// plus current if it has been retired since it was recorded.
+func (c *Catalogue) EffectChoices(current string) []*Effect {
+	var choices []*Effect
+	for _, effect := range c.Effects {
+		if effect.Active || effect.Code == current {
+			choices = append(choices, effect)
+		}
+	}
+	return choices
+}
@@ -0,0 +1,94 @@
+package shotrepo
+
+import (
+	"ct-padel-s/src/features/padel/shot/shotmodel"
+	"ct-padel-s/src/infrastructure/database"
+)
+
+// GetCatalogue returns every contact type and shot effect, active or not, in
+// display order.
+func GetCatalogue(q database.Querier) (*shotmodel.Catalogue, error) {
+	contacts, err := getContactTypes(q)
+	if err != nil {
+		return nil, err
+	}
+	effects, err := getEffects(q)
+	if err != nil {
+		return nil, err
+	}
+	return &shotmodel.Catalogue{ContactTypes: contacts, Effects: effects}, nil
+}
+
+func getContactTypes(q database.Querier) ([]*shotmodel.ContactType, error) {
+	query := `SELECT code, label, position, active, created_at, updated_at
+			  FROM contact_types ORDER BY position, code`
+	rows, err := q.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contacts []*shotmodel.ContactType
+	for rows.Next() {
+		var contact shotmodel.ContactType
+		err := rows.Scan(&contact.Code, &contact.Label, &contact.Position, &contact.Active, &contact.CreatedAt, &contact.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, &contact)
+	}
+	return contacts, rows.Err()
+}
+
+func getEffects(q database.Querier) ([]*shotmodel.Effect, error) {
+	query := `SELECT code, label, contact_type, position, active, created_at, updated_at
+			  FROM shot_effects ORDER BY position, code`
+	rows, err := q.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var effects []*shotmodel.Effect
+	for rows.Next() {
+		var effect shotmodel.Effect
+		err := rows.Scan(&effect.Code, &effect.Label, &effect.ContactType, &effect.Position, &effect.Active, &effect.CreatedAt, &effect.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		effects = append(effects, &effect)
+	}
+	return effects, rows.Err()
+}
+
+func CreateContactType(db *database.DB, contact *shotmodel.ContactType) error {
+	query := `INSERT INTO contact_types (code, label, position, active) VALUES ($1, $2, $3, $4)
+			  RETURNING created_at, updated_at`
+	return db.QueryRow(query, contact.Code, contact.Label, contact.Position, contact.Active).
+		Scan(&contact.CreatedAt, &contact.UpdatedAt)
+}
+
+// UpdateContactType saves the contact type's label, position and whether it
+// is active. Its code never changes.
+func UpdateContactType(db *database.DB, contact *shotmodel.ContactType) error {
+	query := `UPDATE contact_types SET label = $1, position = $2, active = $3, updated_at = CURRENT_TIMESTAMP
+			  WHERE code = $4
+			  RETURNING updated_at`
+	return db.QueryRow(query, contact.Label, contact.Position, contact.Active, contact.Code).Scan(&contact.UpdatedAt)
+}
+
+func CreateEffect(db *database.DB, effect *shotmodel.Effect) error {
+	query := `INSERT INTO shot_effects (code, label, contact_type, position, active) VALUES ($1, $2, $3, $4, $5)
+			  RETURNING created_at, updated_at`
+	return db.QueryRow(query, effect.Code, effect.Label, effect.ContactType, effect.Position, effect.Active).
+		Scan(&effect.CreatedAt, &effect.UpdatedAt)
+}
+
+// UpdateEffect saves the effect's label, contact type, position and whether
+// it is active. Its code never changes.
+func UpdateEffect(db *database.DB, effect *shotmodel.Effect) error {
+	query := `UPDATE shot_effects SET label = $1, contact_type = $2, position = $3, active = $4, updated_at = CURRENT_TIMESTAMP
+			  WHERE code = $5
+			  RETURNING updated_at`
+	return db.QueryRow(query, effect.Label, effect.ContactType, effect.Position, effect.Active, effect.Code).Scan(&effect.UpdatedAt)
+}
@@ -0,0 +1,26 @@
+package shotviews
+
+import (
+	"ct-padel-s/src/features/padel/shot/shotmodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+)
+
+//go:embed get.html
+var getHTML string
+var getComponent = utils.NewComponent("get.html", getHTML)
+
+//go:embed getbreadcrumb.html
+var getBreadcrumbHTML string
+var getBreadcrumbComponent = utils.NewComponent("getbreadcrumb.html", getBreadcrumbHTML)
+
+// RenderGet shows the shot catalogue with a form to edit each entry and to
+// add new ones.
+func RenderGet(catalogue *shotmodel.Catalogue) (template.HTML, error) {
+	return getComponent.Render(map[string]any{"Catalogue": catalogue})
+}
+
+func RenderGetBreadcrumb() (template.HTML, error) {
+	return getBreadcrumbComponent.Render(nil)
+}
@@ -0,0 +1,153 @@
+package simulation
+
+import (
+	"ct-padel-s/src/features/padel/player/playerrepo"
+	"ct-padel-s/src/features/padel/scoring"
+	"ct-padel-s/src/features/padel/shot/shotrepo"
+	"ct-padel-s/src/features/padel/simulation/montecarlo"
+	"ct-padel-s/src/features/padel/simulation/simulationmodel"
+	"ct-padel-s/src/features/padel/simulation/simulationrepo"
+	"ct-padel-s/src/features/padel/simulation/simulationviews"
+	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/shared/components/footer"
+	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
+	"ct-padel-s/src/shared/templates"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultRuns = 2000
+	maxRuns     = 20000
+)
+
+// Get renders the simulator. Once all four players are chosen
+// (team1_player1, team1_player2, team2_player1 and team2_player2 query
+// parameters) it also simulates runs matches between the two pairs.
+func Get(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+	query := r.URL.Query()
+
+	runs := defaultRuns
+	if value := query.Get("runs"); value != "" {
+		var err error
+		runs, err = strconv.Atoi(value)
+		if err != nil || runs < 1 || runs > maxRuns {
+			httperror.Write(w, r, http.StatusBadRequest, "Matches to simulate must be between 1 and "+strconv.Itoa(maxRuns))
+			return
+		}
+	}
+
+	var selected [4]int
+	chosen := 0
+	for i, field := range simulationviews.Fields {
+		value := query.Get(field)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			logger.Warn("Invalid player", "error", err, "field", field, "value", value)
+			httperror.Write(w, r, http.StatusBadRequest, "Invalid player ID")
+			return
+		}
+		for _, other := range selected[:i] {
+			if other == id {
+				httperror.Write(w, r, http.StatusBadRequest, "Choose four different players")
+				return
+			}
+		}
+		selected[i] = id
+		chosen++
+	}
+
+	players, err := playerrepo.GetAllPlayers(db)
+	if err != nil {
+		logger.Error("Failed to get players", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get players")
+		return
+	}
+
+	catalogue, err := shotrepo.GetCatalogue(db)
+	if err != nil {
+		logger.Error("Failed to get shot catalogue", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get shot catalogue")
+		return
+	}
+
+	var lineup []simulationmodel.Profile
+	var result *simulationmodel.Result
+	if chosen == len(selected) {
+		stats, err := simulationrepo.GetPlayerStats(db)
+		if err != nil {
+			logger.Error("Failed to get player stats", "error", err)
+			httperror.Write(w, r, http.StatusInternalServerError, "Failed to get player stats")
+			return
+		}
+
+		league := montecarlo.League(stats)
+		contacts := montecarlo.RallyContacts(catalogue)
+		var profiles [4]simulationmodel.Profile
+		for i, id := range selected {
+			playerStats, ok := stats[id]
+			if !ok {
+				httperror.NotFound(w, r, "Player not found")
+				return
+			}
+			profiles[i] = montecarlo.BuildProfile(playerStats, league, contacts)
+		}
+
+		// Seed from the lineup so the same question gets the same answer
+		seed := uint64(selected[0])<<48 ^ uint64(selected[1])<<32 ^ uint64(selected[2])<<16 ^ uint64(selected[3])
+		simulated := montecarlo.Simulate(profiles, scoring.DefaultRules, runs, seed)
+		lineup, result = profiles[:], &simulated
+		logger.Info("Simulated lineup", "players", selected, "runs", runs, "team1WinPercent", simulated.Team1WinPercent())
+	}
+
+	breadcrumb, err := simulationviews.RenderBreadcrumb()
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load shared components
+	headerHTML, err := header.Render(header.Data{Title: "Match Simulator - Padel Tracker", Breadcrumb: breadcrumb})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	footerHTML, err := footer.Render(footer.Data{})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load feature content and render with data
+	contentHTML, err := simulationviews.RenderGet(players, selected, runs, lineup, result, catalogue)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Compose final page
+	page, err := templates.Render(templates.Data{
+		Title:       "Match Simulator - Padel Tracker",
+		HeaderHTML:  headerHTML,
+		ContentHTML: contentHTML,
+		FooterHTML:  footerHTML,
+	})
+
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	io.WriteString(w, string(page))
+}
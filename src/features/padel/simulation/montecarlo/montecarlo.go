@@ -0,0 +1,242 @@
+// Package montecarlo predicts matches by playing them out many times, shot
+// by shot, from each player's recorded serve, shot mix and how often each
+// kind of shot of theirs ends the point, scored by the scoring package.
+package montecarlo
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"ct-padel-s/src/features/padel/scoring"
+	"ct-padel-s/src/features/padel/shot/shotmodel"
+	"ct-padel-s/src/features/padel/simulation/simulationmodel"
+	"math/rand/v2"
+	"sort"
+)
+
+// priorWeight is how many shots of league-average play are blended into
+// every player's own record, so a player with few recorded shots does not
+// get extreme rates
+const priorWeight = 30
+
+// maxShots ends a rally that has gone on implausibly long with an error
+const maxShots = 60
+
+// RallyContacts returns the contact types played after the serve: every
+// one in the catalogue but the serve, retired ones too as past shots of
+// theirs still describe how a player plays.
+func RallyContacts(catalogue *shotmodel.Catalogue) []playmodel.ContactType {
+	var contacts []playmodel.ContactType
+	for _, contact := range catalogue.ContactTypes {
+		if playmodel.ContactType(contact.Code) != playmodel.ContactServe {
+			contacts = append(contacts, playmodel.ContactType(contact.Code))
+		}
+	}
+	return contacts
+}
+
+// fallbackRates is the league average for a contact type added to the
+// catalogue that fallback doesn't know
+var fallbackRates = simulationmodel.Rates{Winner: 0.08, Error: 0.10}
+
+// fallback is used for the league average when nothing is recorded at all.
+// A contact type without a fallback share is not played until a shot of it
+// is recorded.
+var fallback = struct {
+	rates map[playmodel.ContactType]simulationmodel.Rates
+	mix   map[playmodel.ContactType]float64
+}{
+	rates: map[playmodel.ContactType]simulationmodel.Rates{
+		playmodel.ContactServe:        {Winner: 0.05, Error: 0.04},
+		playmodel.ContactGroundstroke: {Winner: 0.05, Error: 0.10},
+		playmodel.ContactVolley:       {Winner: 0.10, Error: 0.08},
+		playmodel.ContactOverhead:     {Winner: 0.20, Error: 0.10},
+	},
+	mix: map[playmodel.ContactType]float64{
+		playmodel.ContactGroundstroke: 0.55,
+		playmodel.ContactVolley:       0.35,
+		playmodel.ContactOverhead:     0.10,
+	},
+}
+
+// League adds up the stats of every player, as the average player the
+// individual profiles are blended with.
+func League(stats map[int]*simulationmodel.PlayerStats) *simulationmodel.PlayerStats {
+	league := simulationmodel.NewPlayerStats(playermodel.Player{Name: "League average"})
+	for _, s := range stats {
+		for contact, counts := range s.Contacts {
+			league.Contacts[contact] = league.Contacts[contact].Add(counts)
+		}
+		league.ServePoints += s.ServePoints
+		league.ServePointsWon += s.ServePointsWon
+	}
+	return league
+}
+
+// BuildProfile turns a player's stats into simulator rates, blending them
+// with the league's. contacts are the rally contact types to play, from
+// RallyContacts.
+func BuildProfile(stats, league *simulationmodel.PlayerStats, contacts []playmodel.ContactType) simulationmodel.Profile {
+	profile := simulationmodel.Profile{
+		Stats:    stats,
+		Serve:    rates(stats.Contacts[playmodel.ContactServe], league.Contacts[playmodel.ContactServe], fallback.rates[playmodel.ContactServe]),
+		Contacts: contacts,
+		Mix:      map[playmodel.ContactType]float64{},
+		Shots:    map[playmodel.ContactType]simulationmodel.Rates{},
+	}
+
+	own, all := 0, 0
+	for _, contact := range contacts {
+		own += stats.Contacts[contact].Shots
+		all += league.Contacts[contact].Shots
+	}
+	for _, contact := range contacts {
+		leagueShare := fallback.mix[contact]
+		if all > 0 {
+			leagueShare = float64(league.Contacts[contact].Shots) / float64(all)
+		}
+		average, ok := fallback.rates[contact]
+		if !ok {
+			average = fallbackRates
+		}
+		profile.Mix[contact] = blend(stats.Contacts[contact].Shots, own, leagueShare)
+		profile.Shots[contact] = rates(stats.Contacts[contact], league.Contacts[contact], average)
+	}
+	return profile
+}
+
+func rates(own, league simulationmodel.ShotCounts, fallback simulationmodel.Rates) simulationmodel.Rates {
+	average := fallback
+	if league.Shots > 0 {
+		average = simulationmodel.Rates{
+			Winner: float64(league.Winners) / float64(league.Shots),
+			Error:  float64(league.Errors) / float64(league.Shots),
+		}
+	}
+	return simulationmodel.Rates{
+		Winner: blend(own.Winners, own.Shots, average.Winner),
+		Error:  blend(own.Errors, own.Shots, average.Error),
+	}
+}
+
+// blend is count/total with priorWeight observations at the prior rate
+// added.
+func blend(count, total int, prior float64) float64 {
+	return (float64(count) + priorWeight*prior) / (float64(total) + priorWeight)
+}
+
+// Simulate plays the lineup runs times and summarises the results. The
+// lineup is team 1's players, then team 2's. The teams take turns to serve
+// first. The same seed gives the same result.
+func Simulate(lineup [4]simulationmodel.Profile, rules scoring.Rules, runs int, seed uint64) simulationmodel.Result {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	result := simulationmodel.Result{Runs: runs}
+	outcomes := map[[2]int]int{}
+	var served, won [4]int
+	points, games, tiebreaks := 0, 0, 0
+
+	for run := range runs {
+		// Serving order alternates between the teams
+		order := [4]int{0, 2, 1, 3}
+		if run%2 == 1 {
+			order = [4]int{2, 0, 3, 1}
+		}
+
+		score := scoring.New(rules)
+		for score.Winner == analyticsmodel.TeamUnknown {
+			if score.Tiebreak() && score.Points == [3]int{} {
+				tiebreaks++
+			}
+			server := order[score.Server()]
+			winner := point(rng, lineup, server)
+			served[server]++
+			if winner == teamOf(server) {
+				won[server]++
+			}
+			points++
+			if score.Point(winner).Game {
+				games++
+			}
+		}
+
+		if score.Winner == analyticsmodel.Team1 {
+			result.Team1Wins++
+		}
+		outcomes[[2]int{score.SetsWon[analyticsmodel.Team1], score.SetsWon[analyticsmodel.Team2]}]++
+	}
+
+	for sets, count := range outcomes {
+		result.Outcomes = append(result.Outcomes, simulationmodel.Outcome{
+			Team1Sets: sets[0],
+			Team2Sets: sets[1],
+			Matches:   count,
+			Percent:   float64(count) * 100 / float64(runs),
+		})
+	}
+	// Best result for team 1 first
+	sort.Slice(result.Outcomes, func(i, j int) bool {
+		a, b := result.Outcomes[i], result.Outcomes[j]
+		return a.Team1Sets-a.Team2Sets > b.Team1Sets-b.Team2Sets
+	})
+
+	if runs > 0 {
+		result.Points = float64(points) / float64(runs)
+		result.Games = float64(games) / float64(runs)
+		result.Tiebreaks = float64(tiebreaks) / float64(runs)
+	}
+	for i := range lineup {
+		if served[i] > 0 {
+			result.ServeWinPercent[i] = float64(won[i]) * 100 / float64(served[i])
+		}
+	}
+	return result
+}
+
+// point plays out a rally served by lineup[server] and returns the team
+// that won it.
+func point(rng *rand.Rand, lineup [4]simulationmodel.Profile, server int) analyticsmodel.Team {
+	hitter := server
+	for shot := 1; ; shot++ {
+		profile := lineup[hitter]
+		r := profile.Serve
+		if shot > 1 {
+			r = profile.Shots[contact(rng, profile)]
+		}
+
+		roll := rng.Float64()
+		switch {
+		case roll < r.Winner:
+			return teamOf(hitter)
+		case roll < r.Winner+r.Error || shot == maxShots:
+			return teamOf(hitter).Opponent()
+		}
+
+		// Either player of the other team takes the next shot
+		if teamOf(hitter) == analyticsmodel.Team1 {
+			hitter = 2 + rng.IntN(2)
+		} else {
+			hitter = rng.IntN(2)
+		}
+	}
+}
+
+func contact(rng *rand.Rand, profile simulationmodel.Profile) playmodel.ContactType {
+	roll := rng.Float64()
+	for _, contact := range profile.Contacts {
+		roll -= profile.Mix[contact]
+		if roll < 0 {
+			return contact
+		}
+	}
+	if len(profile.Contacts) == 0 {
+		return playmodel.ContactGroundstroke
+	}
+	return profile.Contacts[len(profile.Contacts)-1]
+}
+
+func teamOf(lineupIndex int) analyticsmodel.Team {
+	if lineupIndex < 2 {
+		return analyticsmodel.Team1
+	}
+	return analyticsmodel.Team2
+}
@@ -0,0 +1,105 @@
+package simulationmodel
+
+import (
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/player/playermodel"
+)
+
+// ShotCounts counts a player's recorded shots of one contact type and how
+// many of them ended the point.
+type ShotCounts struct {
+	Shots   int `json:"shots"`
+	Winners int `json:"winners"`
+	Errors  int `json:"errors"`
+}
+
+func (c ShotCounts) Add(other ShotCounts) ShotCounts {
+	return ShotCounts{Shots: c.Shots + other.Shots, Winners: c.Winners + other.Winners, Errors: c.Errors + other.Errors}
+}
+
+// PlayerStats is what a player's recorded plays say about how they play.
+type PlayerStats struct {
+	Player   playermodel.Player                   `json:"player"`
+	Contacts map[playmodel.ContactType]ShotCounts `json:"contacts"`
+	// ServePoints counts the decided points the player served, and
+	// ServePointsWon those their team won
+	ServePoints    int `json:"serve_points"`
+	ServePointsWon int `json:"serve_points_won"`
+}
+
+func NewPlayerStats(player playermodel.Player) *PlayerStats {
+	return &PlayerStats{Player: player, Contacts: map[playmodel.ContactType]ShotCounts{}}
+}
+
+// Shots counts the player's recorded shots of every contact type.
+func (s *PlayerStats) Shots() int {
+	total := 0
+	for _, counts := range s.Contacts {
+		total += counts.Shots
+	}
+	return total
+}
+
+func (s *PlayerStats) ServeWinPercent() float64 {
+	if s.ServePoints == 0 {
+		return 0
+	}
+	return float64(s.ServePointsWon) * 100 / float64(s.ServePoints)
+}
+
+// Rates are the chances that a shot ends the point.
+type Rates struct {
+	Winner float64 `json:"winner"`
+	Error  float64 `json:"error"`
+}
+
+// Profile is how the simulator plays a player: how their serve ends
+// points, which shots they play in a rally and how each of those ends
+// points.
+type Profile struct {
+	Stats *PlayerStats `json:"stats"`
+	// Serve holds the ace and double fault rates
+	Serve Rates `json:"serve"`
+	// Contacts are the contact types played after the serve, in catalogue
+	// order
+	Contacts []playmodel.ContactType `json:"contacts"`
+	// Mix is the share of each rally contact type among the player's shots
+	Mix   map[playmodel.ContactType]float64 `json:"mix"`
+	Shots map[playmodel.ContactType]Rates   `json:"shots"`
+}
+
+// Outcome is how often a final score in sets came up.
+type Outcome struct {
+	Team1Sets int     `json:"team1_sets"`
+	Team2Sets int     `json:"team2_sets"`
+	Matches   int     `json:"matches"`
+	Percent   float64 `json:"percent"`
+}
+
+// Result summarises a simulation of a lineup. Per-player slices follow the
+// lineup order: team 1's players, then team 2's.
+type Result struct {
+	Runs      int       `json:"runs"`
+	Team1Wins int       `json:"team1_wins"`
+	Outcomes  []Outcome `json:"outcomes"`
+	// Points and Games are the average match length
+	Points    float64 `json:"points"`
+	Games     float64 `json:"games"`
+	Tiebreaks float64 `json:"tiebreaks"`
+	// ServeWinPercent is the simulated share of service points won
+	ServeWinPercent [4]float64 `json:"serve_win_percent"`
+}
+
+func (r Result) Team1WinPercent() float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return float64(r.Team1Wins) * 100 / float64(r.Runs)
+}
+
+func (r Result) Team2WinPercent() float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return 100 - r.Team1WinPercent()
+}
@@ -0,0 +1,103 @@
+package simulationrepo
+
+import (
+	"ct-padel-s/src/features/padel/play/playmodel"
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"ct-padel-s/src/features/padel/simulation/simulationmodel"
+	"ct-padel-s/src/infrastructure/database"
+)
+
+// GetPlayerStats returns the shot and serve statistics of every player,
+// keyed by player ID. Players without recorded plays have empty stats.
+func GetPlayerStats(db database.Querier) (map[int]*simulationmodel.PlayerStats, error) {
+	stats := map[int]*simulationmodel.PlayerStats{}
+
+	rows, err := db.Query(`SELECT id, name, created_at FROM players`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var player playermodel.Player
+		if err := rows.Scan(&player.ID, &player.Name, &player.CreatedAt); err != nil {
+			return nil, err
+		}
+		stats[player.ID] = simulationmodel.NewPlayerStats(player)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := addContacts(db, stats); err != nil {
+		return nil, err
+	}
+	if err := addServes(db, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func addContacts(db database.Querier, stats map[int]*simulationmodel.PlayerStats) error {
+	query := `SELECT player_id, contact_type, COUNT(*),
+				  COUNT(*) FILTER (WHERE result_type = 'no_return_winner'),
+				  COUNT(*) FILTER (WHERE result_type IN ('error', 'unforced_error'))
+			  FROM plays
+			  WHERE player_id IS NOT NULL AND contact_type IS NOT NULL
+			  GROUP BY player_id, contact_type`
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var playerID int
+		var contact playmodel.ContactType
+		var counts simulationmodel.ShotCounts
+		if err := rows.Scan(&playerID, &contact, &counts.Shots, &counts.Winners, &counts.Errors); err != nil {
+			return err
+		}
+		if s, ok := stats[playerID]; ok {
+			s.Contacts[contact] = counts
+		}
+	}
+	return rows.Err()
+}
+
+// addServes counts the decided points each player served: the points whose
+// first play they hit and whose last play has a result.
+func addServes(db database.Querier, stats map[int]*simulationmodel.PlayerStats) error {
+	query := `SELECT fp.player_id, COUNT(*),
+				  COUNT(*) FILTER (WHERE
+					  ((fp.player_id IN (m.team1_player1_id, m.team1_player2_id)) = (lp.player_id IN (m.team1_player1_id, m.team1_player2_id)))
+					  = (lp.result_type = 'no_return_winner'))
+			  FROM plays fp
+			  JOIN points pt ON pt.id = fp.point_id
+			  JOIN games g ON g.id = pt.game_id
+			  JOIN sets s ON s.id = g.set_id
+			  JOIN matches m ON m.id = s.match_id
+			  JOIN LATERAL (
+				  SELECT player_id, result_type FROM plays
+				  WHERE point_id = pt.id
+				  ORDER BY play_number DESC
+				  LIMIT 1
+			  ) lp ON lp.result_type IS NOT NULL AND lp.player_id IS NOT NULL
+			  WHERE fp.play_number = 1 AND fp.player_id IS NOT NULL
+			  GROUP BY fp.player_id`
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var playerID, served, won int
+		if err := rows.Scan(&playerID, &served, &won); err != nil {
+			return err
+		}
+		if s, ok := stats[playerID]; ok {
+			s.ServePoints, s.ServePointsWon = served, won
+		}
+	}
+	return rows.Err()
+}
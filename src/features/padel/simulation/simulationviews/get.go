@@ -0,0 +1,100 @@
+package simulationviews
+
+import (
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"ct-padel-s/src/features/padel/shot/shotmodel"
+	"ct-padel-s/src/features/padel/simulation/simulationmodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+)
+
+//go:embed get.html
+var getHTML string
+var getComponent = utils.NewComponent("get.html", getHTML)
+
+//go:embed breadcrumb.html
+var breadcrumbHTML string
+var breadcrumbComponent = utils.NewComponent("breadcrumb.html", breadcrumbHTML)
+
+// Fields are the form fields choosing the lineup, in lineup order.
+var Fields = [4]string{"team1_player1", "team1_player2", "team2_player1", "team2_player2"}
+
+type slotView struct {
+	Field    string
+	Label    string
+	Selected int
+}
+
+type contactView struct {
+	Name   string
+	Mix    float64
+	Winner float64
+	Error  float64
+}
+
+type playerView struct {
+	Player            playermodel.Player
+	Shots             int
+	ServePoints       int
+	ServeWinPercent   float64
+	SimulatedServeWin float64
+	Aces              float64
+	DoubleFaults      float64
+	Contacts          []contactView
+}
+
+type getViewModel struct {
+	Players   []*playermodel.Player
+	Slots     []slotView
+	Runs      int
+	Simulated bool
+	Team1     string
+	Team2     string
+	Lineup    []playerView
+	Result    simulationmodel.Result
+}
+
+// RenderGet renders the simulator form with selected echoed back, and the
+// prediction when lineup and result are given. Contact types are named
+// from catalogue.
+func RenderGet(players []*playermodel.Player, selected [4]int, runs int, lineup []simulationmodel.Profile, result *simulationmodel.Result, catalogue *shotmodel.Catalogue) (template.HTML, error) {
+	labels := [4]string{"Team 1, player 1", "Team 1, player 2", "Team 2, player 1", "Team 2, player 2"}
+	view := getViewModel{Players: players, Runs: runs}
+	for i, field := range Fields {
+		view.Slots = append(view.Slots, slotView{Field: field, Label: labels[i], Selected: selected[i]})
+	}
+
+	if result != nil {
+		view.Simulated = true
+		view.Result = *result
+		for i, profile := range lineup {
+			player := playerView{
+				Player:            profile.Stats.Player,
+				Shots:             profile.Stats.Shots(),
+				ServePoints:       profile.Stats.ServePoints,
+				ServeWinPercent:   profile.Stats.ServeWinPercent(),
+				SimulatedServeWin: result.ServeWinPercent[i],
+				Aces:              profile.Serve.Winner * 100,
+				DoubleFaults:      profile.Serve.Error * 100,
+			}
+			for _, contact := range profile.Contacts {
+				rates := profile.Shots[contact]
+				player.Contacts = append(player.Contacts, contactView{
+					Name:   catalogue.ContactLabel(string(contact)),
+					Mix:    profile.Mix[contact] * 100,
+					Winner: rates.Winner * 100,
+					Error:  rates.Error * 100,
+				})
+			}
+			view.Lineup = append(view.Lineup, player)
+		}
+		view.Team1 = lineup[0].Stats.Player.Name + " & " + lineup[1].Stats.Player.Name
+		view.Team2 = lineup[2].Stats.Player.Name + " & " + lineup[3].Stats.Player.Name
+	}
+	return getComponent.Render(view)
+}
+
+func RenderBreadcrumb() (template.HTML, error) {
+	return breadcrumbComponent.Render(nil)
+}
@@ -0,0 +1,117 @@
+package fixtures
+
+// Slot is a generated fixture before it is stored. Pair IDs of 0 mean the
+// slot is still open (later knockout rounds) or a bye.
+type Slot struct {
+	Round    int
+	Position int
+	Pair1    int
+	Pair2    int
+	// Winner is set for first-round byes, which need no match
+	Winner int
+}
+
+// RoundRobin schedules every pair against every other pair once using the
+// circle method, so each pair plays at most once per round. With an odd
+// number of pairs one pair sits out each round.
+func RoundRobin(pairIDs []int) []Slot {
+	circle := append([]int(nil), pairIDs...)
+	if len(circle)%2 == 1 {
+		circle = append(circle, 0)
+	}
+	n := len(circle)
+
+	var slots []Slot
+	for round := 1; round < n; round++ {
+		position := 0
+		for i := 0; i < n/2; i++ {
+			pair1, pair2 := circle[i], circle[n-1-i]
+			if pair1 == 0 || pair2 == 0 {
+				continue
+			}
+			slots = append(slots, Slot{Round: round, Position: position, Pair1: pair1, Pair2: pair2})
+			position++
+		}
+
+		// Keep the first pair fixed and rotate the rest one place
+		last := circle[n-1]
+		copy(circle[2:], circle[1:n-1])
+		circle[1] = last
+	}
+	return slots
+}
+
+// SingleElimination builds a knockout bracket for pairs given in seed order
+// (best first). The bracket is padded to a power of two with byes, which go
+// to the top seeds, and seeds are placed so the top two can only meet in the
+// final. Every round is generated; slots after the first round are filled as
+// winners advance, except where a bye already decides them.
+func SingleElimination(seededPairIDs []int) []Slot {
+	size := 2
+	for size < len(seededPairIDs) {
+		size *= 2
+	}
+
+	pairAt := func(seed int) int {
+		if seed <= len(seededPairIDs) {
+			return seededPairIDs[seed-1]
+		}
+		return 0
+	}
+
+	var slots []Slot
+	order := SeedOrder(size)
+	for position := 0; position < size/2; position++ {
+		slot := Slot{Round: 1, Position: position, Pair1: pairAt(order[2*position]), Pair2: pairAt(order[2*position+1])}
+		if slot.Pair1 == 0 || slot.Pair2 == 0 {
+			slot.Winner = slot.Pair1 + slot.Pair2
+		}
+		slots = append(slots, slot)
+	}
+
+	round := 2
+	for fixtures := size / 4; fixtures >= 1; fixtures /= 2 {
+		for position := 0; position < fixtures; position++ {
+			slots = append(slots, Slot{Round: round, Position: position})
+		}
+		round++
+	}
+
+	// Byes advance straight into the second round
+	for _, slot := range slots {
+		if slot.Round != 1 || slot.Winner == 0 || size == 2 {
+			continue
+		}
+		nextRound, nextPosition, first := Next(slot.Round, slot.Position)
+		for i := range slots {
+			if slots[i].Round == nextRound && slots[i].Position == nextPosition {
+				if first {
+					slots[i].Pair1 = slot.Winner
+				} else {
+					slots[i].Pair2 = slot.Winner
+				}
+			}
+		}
+	}
+	return slots
+}
+
+// SeedOrder lists seeds 1..size in bracket order: adjacent entries meet in
+// the first round and seed 1 and 2 sit in opposite halves.
+func SeedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, 2*len(order)+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// Next returns the knockout fixture the winner of (round, position) moves
+// into, and whether they take its first or second slot.
+func Next(round, position int) (nextRound, nextPosition int, first bool) {
+	return round + 1, position / 2, position%2 == 0
+}
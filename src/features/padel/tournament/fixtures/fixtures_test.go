@@ -0,0 +1,124 @@
+package fixtures
+
+import (
+	"slices"
+	"testing"
+)
+
+// pairIDs returns n pair IDs in seed order, distinct from the seeds so the
+// tests can't mix them up.
+func pairIDs(n int) []int {
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = 100 + i + 1
+	}
+	return ids
+}
+
+func TestRoundRobin(t *testing.T) {
+	for _, n := range []int{2, 3, 4, 5, 6, 7, 8} {
+		ids := pairIDs(n)
+		slots := RoundRobin(ids)
+
+		met := map[[2]int]int{}
+		playing := map[[2]int]bool{}
+		for _, slot := range slots {
+			if slot.Pair1 == 0 || slot.Pair2 == 0 || slot.Pair1 == slot.Pair2 {
+				t.Fatalf("%d pairs: slot %+v does not have two pairs", n, slot)
+			}
+			met[[2]int{min(slot.Pair1, slot.Pair2), max(slot.Pair1, slot.Pair2)}]++
+			for _, pair := range []int{slot.Pair1, slot.Pair2} {
+				if playing[[2]int{slot.Round, pair}] {
+					t.Errorf("%d pairs: pair %d plays twice in round %d", n, pair, slot.Round)
+				}
+				playing[[2]int{slot.Round, pair}] = true
+			}
+		}
+
+		if want := n * (n - 1) / 2; len(slots) != want {
+			t.Errorf("%d pairs: %d fixtures, want %d", n, len(slots), want)
+		}
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				if met[[2]int{a, b}] != 1 {
+					t.Errorf("%d pairs: %d and %d meet %d times, want once", n, a, b, met[[2]int{a, b}])
+				}
+			}
+		}
+	}
+}
+
+func TestSingleElimination(t *testing.T) {
+	tests := []struct {
+		pairs  int
+		size   int
+		byes   int
+		rounds int
+	}{
+		{3, 4, 1, 2},
+		{5, 8, 3, 3},
+		{8, 8, 0, 3},
+	}
+	for _, test := range tests {
+		ids := pairIDs(test.pairs)
+		slots := SingleElimination(ids)
+
+		if want := test.size - 1; len(slots) != want {
+			t.Errorf("%d pairs: %d fixtures, want %d", test.pairs, len(slots), want)
+		}
+		if last := slots[len(slots)-1]; last.Round != test.rounds {
+			t.Errorf("%d pairs: last round %d, want %d", test.pairs, last.Round, test.rounds)
+		}
+
+		at := func(round, position int) *Slot {
+			for i := range slots {
+				if slots[i].Round == round && slots[i].Position == position {
+					return &slots[i]
+				}
+			}
+			t.Fatalf("%d pairs: no fixture at round %d position %d", test.pairs, round, position)
+			return nil
+		}
+
+		// Every pair plays in or gets a bye from the first round, and the
+		// byes go to the top seeds and advance to round two
+		var byes, seen []int
+		half := map[int]int{}
+		for _, slot := range slots {
+			if slot.Round != 1 {
+				continue
+			}
+			for _, pair := range []int{slot.Pair1, slot.Pair2} {
+				if pair != 0 {
+					seen = append(seen, pair)
+					half[pair] = slot.Position / (test.size / 4)
+				}
+			}
+			if slot.Winner == 0 {
+				continue
+			}
+			byes = append(byes, slot.Winner)
+			round, position, first := Next(slot.Round, slot.Position)
+			next := at(round, position)
+			advanced := next.Pair2
+			if first {
+				advanced = next.Pair1
+			}
+			if advanced != slot.Winner {
+				t.Errorf("%d pairs: bye for %d not advanced into %+v", test.pairs, slot.Winner, *next)
+			}
+		}
+		slices.Sort(seen)
+		if !slices.Equal(seen, ids) {
+			t.Errorf("%d pairs: first round has pairs %v, want %v", test.pairs, seen, ids)
+		}
+		slices.Sort(byes)
+		if !slices.Equal(byes, ids[:test.byes]) {
+			t.Errorf("%d pairs: byes for %v, want the top %d seeds %v", test.pairs, byes, test.byes, ids[:test.byes])
+		}
+
+		if half[ids[0]] == half[ids[1]] {
+			t.Errorf("%d pairs: seeds 1 and 2 are in the same half", test.pairs)
+		}
+	}
+}
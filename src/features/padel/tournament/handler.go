@@ -0,0 +1,414 @@
+package tournament
+
+import (
+	"ct-padel-s/src/features/padel/player/playerrepo"
+	"ct-padel-s/src/features/padel/tournament/standings"
+	"ct-padel-s/src/features/padel/tournament/tournamentmodel"
+	"ct-padel-s/src/features/padel/tournament/tournamentrepo"
+	"ct-padel-s/src/features/padel/tournament/tournamentservice"
+	"ct-padel-s/src/features/padel/tournament/tournamentshared"
+	"ct-padel-s/src/features/padel/tournament/tournamentviews"
+	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/shared/components/footer"
+	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/httperror"
+	"ct-padel-s/src/shared/templates"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func GetAll(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	tournaments, err := tournamentrepo.GetAllTournaments(db)
+	if err != nil {
+		logger.Error("Failed to get tournaments", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get tournaments")
+		return
+	}
+
+	breadcrumb, err := tournamentviews.RenderGetAllBreadcrumb()
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load shared components
+	headerHTML, err := header.Render(header.Data{Title: "Tournaments - Padel Tracker", Breadcrumb: breadcrumb})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	footerHTML, err := footer.Render(footer.Data{})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load feature content and render with data
+	contentHTML, err := tournamentviews.RenderGetAll(tournaments)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Compose final page
+	page, err := templates.Render(templates.Data{
+		Title:       "Tournaments - Padel Tracker",
+		HeaderHTML:  headerHTML,
+		ContentHTML: contentHTML,
+		FooterHTML:  footerHTML,
+	})
+
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	io.WriteString(w, string(page))
+}
+
+func Create(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	tournament := tournamentmodel.Tournament{
+		Name:      strings.TrimSpace(r.FormValue("name")),
+		Format:    r.FormValue("format"),
+		Tiebreaks: r.FormValue("tiebreaks"),
+	}
+	if tournament.Name == "" {
+		httperror.Write(w, r, http.StatusBadRequest, "Tournament name is required")
+		return
+	}
+	if !tournamentmodel.ValidFormat(tournament.Format) {
+		httperror.Write(w, r, http.StatusBadRequest, "Unknown tournament format")
+		return
+	}
+
+	rules, err := tournamentmodel.ParseTiebreaks(tournament.Tiebreaks)
+	if err != nil {
+		httperror.Write(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	tournament.Tiebreaks = strings.Join(rules, ",")
+
+	if err := tournamentrepo.CreateTournament(db, &tournament); err != nil {
+		logger.Error("Failed to create tournament", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create tournament")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/tournaments/%d", tournament.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func Get(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	tournament, ok := loadTournament(w, r)
+	if !ok {
+		return
+	}
+
+	divisions, err := tournamentrepo.GetDivisionsByTournament(db, tournament.ID)
+	if err != nil {
+		logger.Error("Failed to get divisions", "error", err, "tournamentID", tournament.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get divisions")
+		return
+	}
+
+	divisionData := make([]tournamentviews.DivisionData, 0, len(divisions))
+	for _, division := range divisions {
+		data, err := loadDivisionData(db, tournament, division)
+		if err != nil {
+			logger.Error("Failed to get division", "error", err, "divisionID", division.ID)
+			httperror.Write(w, r, http.StatusInternalServerError, "Failed to get division")
+			return
+		}
+		divisionData = append(divisionData, data)
+	}
+
+	players, err := playerrepo.GetAllPlayers(db)
+	if err != nil {
+		logger.Error("Failed to get players", "error", err)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get players")
+		return
+	}
+
+	// Load shared components
+	title := "Tournament: " + tournament.Name
+
+	breadcrumb, err := tournamentviews.RenderGetBreadcrumb(tournament)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	headerHTML, err := header.Render(header.Data{Title: title + " - Padel Tracker", Breadcrumb: breadcrumb})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	footerHTML, err := footer.Render(footer.Data{})
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Load feature content and render with data
+	contentHTML, err := tournamentviews.RenderGet(tournament, divisionData, players)
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	// Compose final page
+	page, err := templates.Render(templates.Data{
+		Title:       title + " - Padel Tracker",
+		HeaderHTML:  headerHTML,
+		ContentHTML: contentHTML,
+		FooterHTML:  footerHTML,
+	})
+
+	if err != nil {
+		httperror.Write(w, r, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+	io.WriteString(w, string(page))
+}
+
+func Delete(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	tournament, ok := loadTournament(w, r)
+	if !ok {
+		return
+	}
+
+	// Fixture matches are kept; they remain ordinary matches
+	if err := tournamentrepo.DeleteTournament(db, tournament.ID); err != nil {
+		logger.Error("Failed to delete tournament", "error", err, "tournamentID", tournament.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to delete tournament")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", "/tournaments")
+	w.WriteHeader(http.StatusOK)
+}
+
+func CreateDivision(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	tournament, ok := loadTournament(w, r)
+	if !ok {
+		return
+	}
+
+	division := tournamentmodel.Division{TournamentID: tournament.ID, Name: strings.TrimSpace(r.FormValue("name"))}
+	if division.Name == "" {
+		httperror.Write(w, r, http.StatusBadRequest, "Division name is required")
+		return
+	}
+
+	if err := tournamentrepo.CreateDivision(db, &division); err != nil {
+		logger.Error("Failed to create division", "error", err, "tournamentID", tournament.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to create division")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/tournaments/%d", tournament.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func CreatePair(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	tournament, division, ok := loadDivision(w, r)
+	if !ok {
+		return
+	}
+
+	player1ID, err1 := strconv.Atoi(r.FormValue("player1_id"))
+	player2ID, err2 := strconv.Atoi(r.FormValue("player2_id"))
+	if err1 != nil || err2 != nil {
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid player ID")
+		return
+	}
+	if player1ID == player2ID {
+		httperror.Write(w, r, http.StatusBadRequest, "A pair needs two different players")
+		return
+	}
+
+	pair := tournamentmodel.Pair{DivisionID: division.ID}
+	pair.Player1.ID = player1ID
+	pair.Player2.ID = player2ID
+	if value := r.FormValue("seed"); value != "" {
+		seed, err := strconv.Atoi(value)
+		if err != nil || seed < 1 {
+			httperror.Write(w, r, http.StatusBadRequest, "Seed must be a positive number")
+			return
+		}
+		pair.Seed = sql.NullInt64{Int64: int64(seed), Valid: true}
+	}
+
+	fixtures, err := tournamentrepo.GetFixturesByDivision(db, division.ID)
+	if err != nil {
+		logger.Error("Failed to get fixtures", "error", err, "divisionID", division.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get fixtures")
+		return
+	}
+	if len(fixtures) > 0 {
+		httperror.Write(w, r, http.StatusConflict, "Fixtures have already been generated for this division")
+		return
+	}
+
+	if err := tournamentrepo.CreatePair(db, &pair); err != nil {
+		logger.Error("Failed to register pair", "error", err, "divisionID", division.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to register pair")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/tournaments/%d", tournament.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func GenerateFixtures(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+
+	tournament, division, ok := loadDivision(w, r)
+	if !ok {
+		return
+	}
+
+	err := tournamentservice.GenerateFixtures(db, tournament, division.ID)
+	switch err {
+	case nil:
+	case tournamentservice.ErrFixturesExist:
+		httperror.Write(w, r, http.StatusConflict, "Fixtures have already been generated for this division")
+		return
+	case tournamentservice.ErrNotEnoughPairs:
+		httperror.Write(w, r, http.StatusBadRequest, "Register at least two pairs before generating fixtures")
+		return
+	default:
+		logger.Error("Failed to generate fixtures", "error", err, "divisionID", division.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to generate fixtures")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/tournaments/%d", tournament.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// loadTournament resolves the tournamentID path value, writing the error
+// response itself when it cannot.
+func loadTournament(w http.ResponseWriter, r *http.Request) (*tournamentmodel.Tournament, bool) {
+	logger := logging.FromRequest(r)
+	db := database.GetDB()
+
+	tournamentID := tournamentshared.GetTournamentID(w, r)
+	if tournamentID == 0 {
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid tournament ID")
+		return nil, false
+	}
+
+	tournament, err := tournamentrepo.GetTournament(db, tournamentID)
+	if err != nil {
+		logger.Error("Failed to get tournament", "error", err, "tournamentID", tournamentID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get tournament")
+		return nil, false
+	}
+	if tournament == nil {
+		httperror.NotFound(w, r, "Tournament not found")
+		return nil, false
+	}
+	return tournament, true
+}
+
+// loadDivision resolves the tournament and division in the path and checks
+// the division belongs to the tournament.
+func loadDivision(w http.ResponseWriter, r *http.Request) (*tournamentmodel.Tournament, *tournamentmodel.Division, bool) {
+	logger := logging.FromRequest(r)
+	db := database.GetDB()
+
+	tournament, ok := loadTournament(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+
+	divisionID := tournamentshared.GetDivisionID(w, r)
+	if divisionID == 0 {
+		httperror.Write(w, r, http.StatusBadRequest, "Invalid division ID")
+		return nil, nil, false
+	}
+
+	division, err := tournamentrepo.GetDivision(db, divisionID)
+	if err != nil {
+		logger.Error("Failed to get division", "error", err, "divisionID", divisionID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get division")
+		return nil, nil, false
+	}
+	if division == nil || division.TournamentID != tournament.ID {
+		httperror.NotFound(w, r, "Division not found")
+		return nil, nil, false
+	}
+	return tournament, division, true
+}
+
+func loadDivisionData(db *database.DB, tournament *tournamentmodel.Tournament, division *tournamentmodel.Division) (tournamentviews.DivisionData, error) {
+	data := tournamentviews.DivisionData{Division: division}
+
+	pairs, err := tournamentrepo.GetPairsByDivision(db, division.ID)
+	if err != nil {
+		return data, err
+	}
+	data.Pairs = pairs
+
+	fixtures, err := tournamentrepo.GetFixturesByDivision(db, division.ID)
+	if err != nil {
+		return data, err
+	}
+	data.Fixtures = fixtures
+
+	if !tournament.IsKnockout() && len(fixtures) > 0 {
+		results, err := tournamentrepo.GetFixtureResults(db, division.ID)
+		if err != nil {
+			return data, err
+		}
+		data.Standings = standings.Compute(pairs, results, tournament.TiebreakRules())
+	}
+	return data, nil
+}
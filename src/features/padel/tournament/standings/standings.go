@@ -0,0 +1,155 @@
+package standings
+
+import (
+	"ct-padel-s/src/features/padel/tournament/tournamentmodel"
+	"sort"
+)
+
+// Row is one pair's line in a league table.
+type Row struct {
+	Position  int                   `json:"position"`
+	Pair      *tournamentmodel.Pair `json:"pair"`
+	Played    int                   `json:"played"`
+	Won       int                   `json:"won"`
+	Lost      int                   `json:"lost"`
+	SetsWon   int                   `json:"sets_won"`
+	SetsLost  int                   `json:"sets_lost"`
+	GamesWon  int                   `json:"games_won"`
+	GamesLost int                   `json:"games_lost"`
+}
+
+func (r *Row) SetDifference() int {
+	return r.SetsWon - r.SetsLost
+}
+
+func (r *Row) GameDifference() int {
+	return r.GamesWon - r.GamesLost
+}
+
+// Compute builds the league table for pairs from decided fixture results.
+// Pairs are ranked by wins, then by each tiebreak rule in turn, applied only
+// among pairs still level. Head-to-head compares wins in the matches played
+// between the level pairs. Pairs level after every rule are ordered by seed
+// and then name.
+func Compute(pairs []*tournamentmodel.Pair, results []tournamentmodel.FixtureResult, tiebreaks []string) []Row {
+	rows := make([]*Row, len(pairs))
+	byPair := map[int]*Row{}
+	for i, pair := range pairs {
+		rows[i] = &Row{Pair: pair}
+		byPair[pair.ID] = rows[i]
+	}
+
+	for _, result := range results {
+		row1, row2 := byPair[result.Pair1ID], byPair[result.Pair2ID]
+		if row1 == nil || row2 == nil {
+			continue
+		}
+		row1.Played++
+		row2.Played++
+		if result.WinnerTeam == 1 {
+			row1.Won++
+			row2.Lost++
+		} else {
+			row2.Won++
+			row1.Lost++
+		}
+		row1.SetsWon += result.Team1Sets
+		row1.SetsLost += result.Team2Sets
+		row2.SetsWon += result.Team2Sets
+		row2.SetsLost += result.Team1Sets
+		row1.GamesWon += result.Team1Games
+		row1.GamesLost += result.Team2Games
+		row2.GamesWon += result.Team2Games
+		row2.GamesLost += result.Team1Games
+	}
+
+	rules := append([]string{"wins"}, tiebreaks...)
+	ranked := rank(rows, rules, results)
+
+	table := make([]Row, len(ranked))
+	for i, row := range ranked {
+		row.Position = i + 1
+		table[i] = *row
+	}
+	return table
+}
+
+// rank orders group by the first rule and recurses into runs of rows that
+// the rule leaves level.
+func rank(group []*Row, rules []string, results []tournamentmodel.FixtureResult) []*Row {
+	if len(group) < 2 {
+		return group
+	}
+	if len(rules) == 0 {
+		sort.SliceStable(group, func(i, j int) bool {
+			return fallbackLess(group[i], group[j])
+		})
+		return group
+	}
+
+	keys := map[*Row]int{}
+	for _, row := range group {
+		keys[row] = key(rules[0], row, group, results)
+	}
+	sort.SliceStable(group, func(i, j int) bool {
+		return keys[group[i]] > keys[group[j]]
+	})
+
+	ranked := make([]*Row, 0, len(group))
+	for start := 0; start < len(group); {
+		end := start + 1
+		for end < len(group) && keys[group[end]] == keys[group[start]] {
+			end++
+		}
+		ranked = append(ranked, rank(group[start:end], rules[1:], results)...)
+		start = end
+	}
+	return ranked
+}
+
+// key scores row under rule; higher ranks first.
+func key(rule string, row *Row, group []*Row, results []tournamentmodel.FixtureResult) int {
+	switch rule {
+	case "wins":
+		return row.Won
+	case tournamentmodel.TiebreakHeadToHead:
+		return headToHeadWins(row, group, results)
+	case tournamentmodel.TiebreakSetDifference:
+		return row.SetDifference()
+	case tournamentmodel.TiebreakGameDifference:
+		return row.GameDifference()
+	case tournamentmodel.TiebreakSetsWon:
+		return row.SetsWon
+	case tournamentmodel.TiebreakGamesWon:
+		return row.GamesWon
+	}
+	return 0
+}
+
+func headToHeadWins(row *Row, group []*Row, results []tournamentmodel.FixtureResult) int {
+	inGroup := map[int]bool{}
+	for _, other := range group {
+		inGroup[other.Pair.ID] = true
+	}
+
+	wins := 0
+	for _, result := range results {
+		if !inGroup[result.Pair1ID] || !inGroup[result.Pair2ID] {
+			continue
+		}
+		if (result.Pair1ID == row.Pair.ID && result.WinnerTeam == 1) || (result.Pair2ID == row.Pair.ID && result.WinnerTeam == 2) {
+			wins++
+		}
+	}
+	return wins
+}
+
+func fallbackLess(a, b *Row) bool {
+	if a.Pair.Seed.Valid != b.Pair.Seed.Valid {
+		return a.Pair.Seed.Valid
+	}
+	if a.Pair.Seed.Int64 != b.Pair.Seed.Int64 {
+		return a.Pair.Seed.Int64 < b.Pair.Seed.Int64
+	}
+	return a.Pair.Name() < b.Pair.Name()
+}
@@ -0,0 +1,114 @@
+package standings
+
+import (
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"ct-padel-s/src/features/padel/tournament/tournamentmodel"
+	"database/sql"
+	"slices"
+	"testing"
+)
+
+// pair returns pair id, both of whose players are called name, with seed.
+func pair(id int, name string, seed int64) *tournamentmodel.Pair {
+	return &tournamentmodel.Pair{
+		ID:      id,
+		Player1: playermodel.Player{Name: name},
+		Player2: playermodel.Player{Name: name},
+		Seed:    sql.NullInt64{Int64: seed, Valid: true},
+	}
+}
+
+// result is a straight-sets match between pair1 and pair2, both sets won
+// games1 to games2 from pair 1's side.
+func result(pair1, pair2, games1, games2 int) tournamentmodel.FixtureResult {
+	r := tournamentmodel.FixtureResult{Pair1ID: pair1, Pair2ID: pair2, Team1Games: 2 * games1, Team2Games: 2 * games2}
+	if games1 > games2 {
+		r.WinnerTeam, r.Team1Sets = 1, 2
+	} else {
+		r.WinnerTeam, r.Team2Sets = 2, 2
+	}
+	return r
+}
+
+const (
+	a = iota + 1
+	b
+	c
+	d
+	e
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name      string
+		pairs     []*tournamentmodel.Pair
+		results   []tournamentmodel.FixtureResult
+		tiebreaks []string
+		want      []int
+	}{
+		{
+			// B and A are level on two wins and B beat A. D and E are level
+			// on one and D beat E. Counting head-to-head over every pair
+			// would leave both level and fall back to seeds.
+			name:  "head to head among level pairs",
+			pairs: []*tournamentmodel.Pair{pair(a, "A", 1), pair(b, "B", 3), pair(c, "C", 4), pair(d, "D", 5), pair(e, "E", 2)},
+			results: []tournamentmodel.FixtureResult{
+				result(c, a, 6, 4), result(c, b, 6, 4), result(c, d, 6, 4), result(c, e, 6, 4),
+				result(b, a, 6, 4), result(a, d, 6, 4), result(a, e, 6, 4),
+				result(b, d, 6, 4), result(e, b, 6, 4),
+				result(d, e, 6, 4),
+			},
+			tiebreaks: []string{tournamentmodel.TiebreakHeadToHead},
+			want:      []int{c, b, a, d, e},
+		},
+		{
+			// A, B and C beat each other in a circle, so head-to-head leaves
+			// them level and game difference decides
+			name:  "level after head to head",
+			pairs: []*tournamentmodel.Pair{pair(a, "A", 3), pair(b, "B", 2), pair(c, "C", 1)},
+			results: []tournamentmodel.FixtureResult{
+				result(a, b, 6, 0), result(b, c, 6, 4), result(c, a, 6, 4),
+			},
+			tiebreaks: []string{tournamentmodel.TiebreakHeadToHead, tournamentmodel.TiebreakGameDifference},
+			want:      []int{a, c, b},
+		},
+		{
+			name:  "level after every rule",
+			pairs: []*tournamentmodel.Pair{pair(a, "A", 3), pair(b, "B", 2), pair(c, "C", 1)},
+			results: []tournamentmodel.FixtureResult{
+				result(a, b, 6, 4), result(b, c, 6, 4), result(c, a, 6, 4),
+			},
+			tiebreaks: []string{tournamentmodel.TiebreakHeadToHead, tournamentmodel.TiebreakGameDifference},
+			want:      []int{c, b, a},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := Compute(test.pairs, test.results, test.tiebreaks)
+
+			var got []int
+			for i, row := range table {
+				got = append(got, row.Pair.ID)
+				if row.Position != i+1 {
+					t.Errorf("row %d has position %d", i, row.Position)
+				}
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("order = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestComputeTotals(t *testing.T) {
+	pairs := []*tournamentmodel.Pair{pair(a, "A", 1), pair(b, "B", 2)}
+	table := Compute(pairs, []tournamentmodel.FixtureResult{result(b, a, 6, 3)}, nil)
+
+	want := Row{Position: 1, Pair: pairs[1], Played: 1, Won: 1, SetsWon: 2, GamesWon: 12, GamesLost: 6}
+	if table[0] != want {
+		t.Errorf("winner row = %+v, want %+v", table[0], want)
+	}
+	if got := table[1]; got.Lost != 1 || got.SetDifference() != -2 || got.GameDifference() != -6 {
+		t.Errorf("loser row = %+v, want one loss, -2 sets and -6 games", got)
+	}
+}
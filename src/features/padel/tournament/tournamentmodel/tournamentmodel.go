@@ -0,0 +1,126 @@
+package tournamentmodel
+
+import (
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tournament formats
+const (
+	FormatRoundRobin        = "round_robin"
+	FormatSingleElimination = "single_elimination"
+)
+
+// Tiebreak rules, applied in the configured order to pairs level on wins
+const (
+	TiebreakHeadToHead     = "head_to_head"
+	TiebreakSetDifference  = "set_difference"
+	TiebreakGameDifference = "game_difference"
+	TiebreakSetsWon        = "sets_won"
+	TiebreakGamesWon       = "games_won"
+)
+
+// DefaultTiebreaks matches the column default in the tournaments table
+const DefaultTiebreaks = "head_to_head,set_difference,game_difference"
+
+var tiebreakRules = map[string]bool{
+	TiebreakHeadToHead:     true,
+	TiebreakSetDifference:  true,
+	TiebreakGameDifference: true,
+	TiebreakSetsWon:        true,
+	TiebreakGamesWon:       true,
+}
+
+type Tournament struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Format    string    `json:"format" db:"format"`
+	Tiebreaks string    `json:"tiebreaks" db:"tiebreaks"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (t *Tournament) IsKnockout() bool {
+	return t.Format == FormatSingleElimination
+}
+
+// TiebreakRules returns the configured tiebreak rules in order.
+func (t *Tournament) TiebreakRules() []string {
+	rules, _ := ParseTiebreaks(t.Tiebreaks)
+	return rules
+}
+
+// ParseTiebreaks splits a comma separated list of tiebreak rules and checks
+// each one is known.
+func ParseTiebreaks(value string) ([]string, error) {
+	var rules []string
+	for _, rule := range strings.Split(value, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		if !tiebreakRules[rule] {
+			return nil, fmt.Errorf("unknown tiebreak rule %q", rule)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ValidFormat reports whether format is a known tournament format.
+func ValidFormat(format string) bool {
+	return format == FormatRoundRobin || format == FormatSingleElimination
+}
+
+type Division struct {
+	ID           int       `json:"id" db:"id"`
+	TournamentID int       `json:"tournament_id" db:"tournament_id"`
+	Name         string    `json:"name" db:"name"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// Pair is a registered doubles team. Player1 has the lower player ID.
+type Pair struct {
+	ID         int                `json:"id" db:"id"`
+	DivisionID int                `json:"division_id" db:"division_id"`
+	Player1    playermodel.Player `json:"player1"`
+	Player2    playermodel.Player `json:"player2"`
+	Seed       sql.NullInt64      `json:"seed" db:"seed"`
+	CreatedAt  time.Time          `json:"created_at" db:"created_at"`
+}
+
+func (p *Pair) Name() string {
+	return p.Player1.Name + " & " + p.Player2.Name
+}
+
+// Fixture is one scheduled meeting. Knockout fixtures beyond the first round
+// have no pairs until the previous round is decided, and a first-round
+// fixture with a single pair is a bye.
+type Fixture struct {
+	ID           int           `json:"id" db:"id"`
+	DivisionID   int           `json:"division_id" db:"division_id"`
+	Round        int           `json:"round" db:"round"`
+	Position     int           `json:"position" db:"position"`
+	Pair1ID      sql.NullInt64 `json:"pair1_id" db:"pair1_id"`
+	Pair2ID      sql.NullInt64 `json:"pair2_id" db:"pair2_id"`
+	WinnerPairID sql.NullInt64 `json:"winner_pair_id" db:"winner_pair_id"`
+	MatchID      sql.NullInt64 `json:"match_id" db:"match_id"`
+	CreatedAt    time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// FixtureResult is the decided outcome of a fixture's completed match, from
+// pair 1's side.
+type FixtureResult struct {
+	FixtureID  int
+	Pair1ID    int
+	Pair2ID    int
+	WinnerTeam int
+	Team1Sets  int
+	Team2Sets  int
+	Team1Games int
+	Team2Games int
+}
@@ -0,0 +1,269 @@
+package tournamentrepo
+
+import (
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/tournament/tournamentmodel"
+	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
+)
+
+func CreateTournament(db *database.DB, tournament *tournamentmodel.Tournament) error {
+	query := `INSERT INTO tournaments (name, format, tiebreaks) VALUES ($1, $2, $3)
+			  RETURNING id, created_at, updated_at`
+	return db.QueryRow(query, tournament.Name, tournament.Format, tournament.Tiebreaks).
+		Scan(&tournament.ID, &tournament.CreatedAt, &tournament.UpdatedAt)
+}
+
+func GetTournament(db database.Querier, id int) (*tournamentmodel.Tournament, error) {
+	tournament := &tournamentmodel.Tournament{}
+	query := `SELECT id, name, format, tiebreaks, created_at, updated_at FROM tournaments WHERE id = $1`
+	err := db.QueryRow(query, id).Scan(
+		&tournament.ID,
+		&tournament.Name,
+		&tournament.Format,
+		&tournament.Tiebreaks,
+		&tournament.CreatedAt,
+		&tournament.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return tournament, err
+}
+
+func GetAllTournaments(db *database.DB) ([]*tournamentmodel.Tournament, error) {
+	query := `SELECT id, name, format, tiebreaks, created_at, updated_at FROM tournaments ORDER BY created_at DESC`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tournaments []*tournamentmodel.Tournament
+	for rows.Next() {
+		var tournament tournamentmodel.Tournament
+		err := rows.Scan(
+			&tournament.ID,
+			&tournament.Name,
+			&tournament.Format,
+			&tournament.Tiebreaks,
+			&tournament.CreatedAt,
+			&tournament.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, &tournament)
+	}
+	return tournaments, rows.Err()
+}
+
+func DeleteTournament(db *database.DB, id int) error {
+	_, err := db.Exec(`DELETE FROM tournaments WHERE id = $1`, id)
+	return err
+}
+
+func CreateDivision(db *database.DB, division *tournamentmodel.Division) error {
+	query := `INSERT INTO divisions (tournament_id, name) VALUES ($1, $2) RETURNING id, created_at`
+	return db.QueryRow(query, division.TournamentID, division.Name).Scan(&division.ID, &division.CreatedAt)
+}
+
+func GetDivision(db database.Querier, id int) (*tournamentmodel.Division, error) {
+	division := &tournamentmodel.Division{}
+	query := `SELECT id, tournament_id, name, created_at FROM divisions WHERE id = $1`
+	err := db.QueryRow(query, id).Scan(&division.ID, &division.TournamentID, &division.Name, &division.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return division, err
+}
+
+func GetDivisionsByTournament(db *database.DB, tournamentID int) ([]*tournamentmodel.Division, error) {
+	query := `SELECT id, tournament_id, name, created_at FROM divisions WHERE tournament_id = $1 ORDER BY name`
+	rows, err := db.Query(query, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var divisions []*tournamentmodel.Division
+	for rows.Next() {
+		var division tournamentmodel.Division
+		if err := rows.Scan(&division.ID, &division.TournamentID, &division.Name, &division.CreatedAt); err != nil {
+			return nil, err
+		}
+		divisions = append(divisions, &division)
+	}
+	return divisions, rows.Err()
+}
+
+// LockDivision takes a row lock on the division so fixture generation and
+// advancement within it run one at a time.
+func LockDivision(q database.Querier, divisionID int) error {
+	_, err := q.Exec(`SELECT id FROM divisions WHERE id = $1 FOR UPDATE`, divisionID)
+	return err
+}
+
+// CreatePair registers a pair. The players are stored lowest ID first so the
+// same two players cannot register twice in one division.
+func CreatePair(db *database.DB, pair *tournamentmodel.Pair) error {
+	if pair.Player1.ID > pair.Player2.ID {
+		pair.Player1, pair.Player2 = pair.Player2, pair.Player1
+	}
+	query := `INSERT INTO tournament_pairs (division_id, player1_id, player2_id, seed) VALUES ($1, $2, $3, $4)
+			  RETURNING id, created_at`
+	return db.QueryRow(query, pair.DivisionID, pair.Player1.ID, pair.Player2.ID, pair.Seed).Scan(&pair.ID, &pair.CreatedAt)
+}
+
+// GetPairsByDivision returns the division's pairs in seed order, unseeded
+// pairs last in registration order.
+func GetPairsByDivision(q database.Querier, divisionID int) ([]*tournamentmodel.Pair, error) {
+	query := `SELECT tp.id, tp.division_id, tp.seed, tp.created_at,
+			  p1.id, p1.name, p1.created_at,
+			  p2.id, p2.name, p2.created_at
+			  FROM tournament_pairs tp
+			  JOIN players p1 ON p1.id = tp.player1_id
+			  JOIN players p2 ON p2.id = tp.player2_id
+			  WHERE tp.division_id = $1
+			  ORDER BY tp.seed NULLS LAST, tp.created_at, tp.id`
+	rows, err := q.Query(query, divisionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs []*tournamentmodel.Pair
+	for rows.Next() {
+		var pair tournamentmodel.Pair
+		err := rows.Scan(
+			&pair.ID, &pair.DivisionID, &pair.Seed, &pair.CreatedAt,
+			&pair.Player1.ID, &pair.Player1.Name, &pair.Player1.CreatedAt,
+			&pair.Player2.ID, &pair.Player2.Name, &pair.Player2.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, &pair)
+	}
+	return pairs, rows.Err()
+}
+
+func CreateFixture(q database.Querier, fixture *tournamentmodel.Fixture) error {
+	query := `INSERT INTO fixtures (division_id, round, position, pair1_id, pair2_id, winner_pair_id)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING id, created_at, updated_at`
+	return q.QueryRow(query,
+		fixture.DivisionID,
+		fixture.Round,
+		fixture.Position,
+		fixture.Pair1ID,
+		fixture.Pair2ID,
+		fixture.WinnerPairID).Scan(&fixture.ID, &fixture.CreatedAt, &fixture.UpdatedAt)
+}
+
+const fixtureColumns = `id, division_id, round, position, pair1_id, pair2_id, winner_pair_id, match_id, created_at, updated_at`
+
+func scanFixture(row interface{ Scan(...any) error }, fixture *tournamentmodel.Fixture) error {
+	return row.Scan(
+		&fixture.ID,
+		&fixture.DivisionID,
+		&fixture.Round,
+		&fixture.Position,
+		&fixture.Pair1ID,
+		&fixture.Pair2ID,
+		&fixture.WinnerPairID,
+		&fixture.MatchID,
+		&fixture.CreatedAt,
+		&fixture.UpdatedAt)
+}
+
+// GetFixturesByDivision returns the division's fixtures by round and position.
+func GetFixturesByDivision(q database.Querier, divisionID int) ([]*tournamentmodel.Fixture, error) {
+	query := `SELECT ` + fixtureColumns + ` FROM fixtures WHERE division_id = $1 ORDER BY round, position`
+	rows, err := q.Query(query, divisionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fixtures []*tournamentmodel.Fixture
+	for rows.Next() {
+		var fixture tournamentmodel.Fixture
+		if err := scanFixture(rows, &fixture); err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, &fixture)
+	}
+	return fixtures, rows.Err()
+}
+
+// GetFixtureByMatch returns the fixture played as matchID, or nil if the
+// match is not a tournament fixture.
+func GetFixtureByMatch(q database.Querier, matchID int) (*tournamentmodel.Fixture, error) {
+	fixture := &tournamentmodel.Fixture{}
+	err := scanFixture(q.QueryRow(`SELECT `+fixtureColumns+` FROM fixtures WHERE match_id = $1`, matchID), fixture)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return fixture, err
+}
+
+// GetFixture returns the fixture at (round, position) of a division, or nil.
+func GetFixture(q database.Querier, divisionID, round, position int) (*tournamentmodel.Fixture, error) {
+	fixture := &tournamentmodel.Fixture{}
+	query := `SELECT ` + fixtureColumns + ` FROM fixtures WHERE division_id = $1 AND round = $2 AND position = $3`
+	err := scanFixture(q.QueryRow(query, divisionID, round, position), fixture)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return fixture, err
+}
+
+// UpdateFixture stores the fixture's pairs, winner and match.
+func UpdateFixture(q database.Querier, fixture *tournamentmodel.Fixture) error {
+	query := `UPDATE fixtures SET pair1_id = $1, pair2_id = $2, winner_pair_id = $3, match_id = $4, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $5
+			  RETURNING updated_at`
+	return q.QueryRow(query,
+		fixture.Pair1ID,
+		fixture.Pair2ID,
+		fixture.WinnerPairID,
+		fixture.MatchID,
+		fixture.ID).Scan(&fixture.UpdatedAt)
+}
+
+// GetFixtureResults returns the decided results of the division's completed
+// fixture matches. Team 1 of each match is the fixture's first pair.
+func GetFixtureResults(q database.Querier, divisionID int) ([]tournamentmodel.FixtureResult, error) {
+	query := `WITH division_matches AS (
+				  SELECT m.* FROM matches m
+				  JOIN fixtures f ON f.match_id = m.id
+				  WHERE f.division_id = $1 AND m.completed_at IS NOT NULL
+			  ), ` + matchrepo.ResultsCTE("division_matches") + `
+			  SELECT f.id, f.pair1_id, f.pair2_id, mr.winner_team, mr.team1_sets, mr.team2_sets, mr.team1_games, mr.team2_games
+			  FROM fixtures f
+			  JOIN match_results mr ON mr.match_id = f.match_id
+			  WHERE f.division_id = $1 AND mr.winner_team IS NOT NULL
+			  ORDER BY f.round, f.position`
+	rows, err := q.Query(query, divisionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []tournamentmodel.FixtureResult
+	for rows.Next() {
+		var result tournamentmodel.FixtureResult
+		err := rows.Scan(
+			&result.FixtureID,
+			&result.Pair1ID,
+			&result.Pair2ID,
+			&result.WinnerTeam,
+			&result.Team1Sets,
+			&result.Team2Sets,
+			&result.Team1Games,
+			&result.Team2Games)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
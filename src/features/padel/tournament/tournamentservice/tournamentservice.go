@@ -0,0 +1,199 @@
+package tournamentservice
+
+import (
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/tournament/fixtures"
+	"ct-padel-s/src/features/padel/tournament/tournamentmodel"
+	"ct-padel-s/src/features/padel/tournament/tournamentrepo"
+	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
+	"errors"
+	"log/slog"
+)
+
+var (
+	// ErrFixturesExist is returned when a division already has fixtures
+	ErrFixturesExist = errors.New("fixtures have already been generated")
+	// ErrNotEnoughPairs is returned when fewer than two pairs are registered
+	ErrNotEnoughPairs = errors.New("at least two pairs are needed")
+)
+
+// GenerateFixtures schedules the division in the tournament's format and
+// creates a match for every fixture whose pairs are already known.
+func GenerateFixtures(db *database.DB, tournament *tournamentmodel.Tournament, divisionID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tournamentrepo.LockDivision(tx, divisionID); err != nil {
+		return err
+	}
+
+	existing, err := tournamentrepo.GetFixturesByDivision(tx, divisionID)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return ErrFixturesExist
+	}
+
+	pairs, err := tournamentrepo.GetPairsByDivision(tx, divisionID)
+	if err != nil {
+		return err
+	}
+	if len(pairs) < 2 {
+		return ErrNotEnoughPairs
+	}
+
+	// Pairs arrive in seed order, which the knockout bracket relies on
+	pairIDs := make([]int, len(pairs))
+	for i, pair := range pairs {
+		pairIDs[i] = pair.ID
+	}
+	byID := pairsByID(pairs)
+
+	var slots []fixtures.Slot
+	if tournament.IsKnockout() {
+		slots = fixtures.SingleElimination(pairIDs)
+	} else {
+		slots = fixtures.RoundRobin(pairIDs)
+	}
+
+	for _, slot := range slots {
+		fixture := tournamentmodel.Fixture{
+			DivisionID:   divisionID,
+			Round:        slot.Round,
+			Position:     slot.Position,
+			Pair1ID:      nullID(slot.Pair1),
+			Pair2ID:      nullID(slot.Pair2),
+			WinnerPairID: nullID(slot.Winner),
+		}
+		if err := tournamentrepo.CreateFixture(tx, &fixture); err != nil {
+			return err
+		}
+		if err := scheduleMatch(tx, &fixture, byID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RecordResult updates the fixture played as matchID after the match is
+// completed: it stores the winner and, in a knockout, moves them into the
+// next round, scheduling that match once both pairs are known. Matches that
+// are not fixtures, or have no decided winner, are ignored. It runs in the
+// caller's transaction, so the fixture is updated with the completion.
+func RecordResult(q database.Querier, matchID int) error {
+	fixture, err := tournamentrepo.GetFixtureByMatch(q, matchID)
+	if err != nil || fixture == nil {
+		return err
+	}
+
+	if err := tournamentrepo.LockDivision(q, fixture.DivisionID); err != nil {
+		return err
+	}
+
+	results, err := tournamentrepo.GetFixtureResults(q, fixture.DivisionID)
+	if err != nil {
+		return err
+	}
+	var result *tournamentmodel.FixtureResult
+	for i := range results {
+		if results[i].FixtureID == fixture.ID {
+			result = &results[i]
+		}
+	}
+	if result == nil {
+		slog.Warn("Fixture match has no decided winner", "fixtureID", fixture.ID, "matchID", matchID)
+		return nil
+	}
+
+	winner := result.Pair1ID
+	if result.WinnerTeam == 2 {
+		winner = result.Pair2ID
+	}
+	fixture.WinnerPairID = nullID(winner)
+	if err := tournamentrepo.UpdateFixture(q, fixture); err != nil {
+		return err
+	}
+
+	// Only knockouts advance winners; round-robin rounds are independent
+	division, err := tournamentrepo.GetDivision(q, fixture.DivisionID)
+	if err != nil {
+		return err
+	}
+	tournament, err := tournamentrepo.GetTournament(q, division.TournamentID)
+	if err != nil {
+		return err
+	}
+	if !tournament.IsKnockout() {
+		return nil
+	}
+
+	nextRound, nextPosition, first := fixtures.Next(fixture.Round, fixture.Position)
+	next, err := tournamentrepo.GetFixture(q, fixture.DivisionID, nextRound, nextPosition)
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		// The final has been decided
+		return nil
+	}
+
+	if first {
+		next.Pair1ID = nullID(winner)
+	} else {
+		next.Pair2ID = nullID(winner)
+	}
+	if err := tournamentrepo.UpdateFixture(q, next); err != nil {
+		return err
+	}
+
+	pairs, err := tournamentrepo.GetPairsByDivision(q, fixture.DivisionID)
+	if err != nil {
+		return err
+	}
+	if err := scheduleMatch(q, next, pairsByID(pairs)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// scheduleMatch creates the match for a fixture once both of its pairs are
+// known, with pair 1 as team 1.
+func scheduleMatch(q database.Querier, fixture *tournamentmodel.Fixture, pairs map[int]*tournamentmodel.Pair) error {
+	if !fixture.Pair1ID.Valid || !fixture.Pair2ID.Valid || fixture.WinnerPairID.Valid || fixture.MatchID.Valid {
+		return nil
+	}
+
+	pair1, pair2 := pairs[int(fixture.Pair1ID.Int64)], pairs[int(fixture.Pair2ID.Int64)]
+	match := matchmodel.Match{
+		Team1Player1ID: pair1.Player1.ID,
+		Team1Player2ID: pair1.Player2.ID,
+		Team2Player1ID: pair2.Player1.ID,
+		Team2Player2ID: pair2.Player2.ID,
+	}
+	if err := matchrepo.CreateMatch(q, &match); err != nil {
+		return err
+	}
+
+	fixture.MatchID = nullID(match.ID)
+	return tournamentrepo.UpdateFixture(q, fixture)
+}
+
+func pairsByID(pairs []*tournamentmodel.Pair) map[int]*tournamentmodel.Pair {
+	byID := map[int]*tournamentmodel.Pair{}
+	for _, pair := range pairs {
+		byID[pair.ID] = pair
+	}
+	return byID
+}
+
+func nullID(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
@@ -0,0 +1,27 @@
+package tournamentshared
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func GetTournamentID(w http.ResponseWriter, r *http.Request) int {
+	tournamentID := r.PathValue("tournamentID")
+	id, err := strconv.Atoi(tournamentID)
+	if err != nil {
+		slog.Error("Invalid tournament ID", "error", err)
+		return 0
+	}
+	return id
+}
+
+func GetDivisionID(w http.ResponseWriter, r *http.Request) int {
+	divisionID := r.PathValue("divisionID")
+	id, err := strconv.Atoi(divisionID)
+	if err != nil {
+		slog.Error("Invalid division ID", "error", err)
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,102 @@
+package tournamentviews
+
+import (
+	"ct-padel-s/src/features/padel/player/playermodel"
+	"ct-padel-s/src/features/padel/tournament/standings"
+	"ct-padel-s/src/features/padel/tournament/tournamentmodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+	"strconv"
+)
+
+//go:embed get.html
+var getHTML string
+var getComponent = utils.NewComponent("get.html", getHTML)
+
+//go:embed getbreadcrumb.html
+var getBreadcrumbHTML string
+var getBreadcrumbComponent = utils.NewComponent("getbreadcrumb.html", getBreadcrumbHTML)
+
+// DivisionData is everything shown for one division.
+type DivisionData struct {
+	Division  *tournamentmodel.Division
+	Pairs     []*tournamentmodel.Pair
+	Fixtures  []*tournamentmodel.Fixture
+	Standings []standings.Row
+}
+
+type fixtureView struct {
+	Fixture *tournamentmodel.Fixture
+	Pair1   *tournamentmodel.Pair
+	Pair2   *tournamentmodel.Pair
+	Winner  *tournamentmodel.Pair
+}
+
+type roundView struct {
+	Name     string
+	Fixtures []fixtureView
+}
+
+type divisionView struct {
+	DivisionData
+	Rounds []roundView
+}
+
+func RenderGet(tournament *tournamentmodel.Tournament, divisions []DivisionData, players []*playermodel.Player) (template.HTML, error) {
+	views := make([]divisionView, len(divisions))
+	for i, division := range divisions {
+		views[i] = divisionView{DivisionData: division, Rounds: rounds(tournament, division)}
+	}
+
+	return getComponent.Render(map[string]any{
+		"Tournament": tournament,
+		"Divisions":  views,
+		"Players":    players,
+	})
+}
+
+func RenderGetBreadcrumb(tournament *tournamentmodel.Tournament) (template.HTML, error) {
+	return getBreadcrumbComponent.Render(map[string]any{"Tournament": tournament})
+}
+
+// rounds groups the division's fixtures (ordered by round) for display.
+func rounds(tournament *tournamentmodel.Tournament, division DivisionData) []roundView {
+	pairs := map[int64]*tournamentmodel.Pair{}
+	for _, pair := range division.Pairs {
+		pairs[int64(pair.ID)] = pair
+	}
+
+	lastRound := 0
+	for _, fixture := range division.Fixtures {
+		lastRound = max(lastRound, fixture.Round)
+	}
+
+	var views []roundView
+	for _, fixture := range division.Fixtures {
+		if len(views) < fixture.Round {
+			views = append(views, roundView{Name: roundName(tournament, fixture.Round, lastRound)})
+		}
+		views[fixture.Round-1].Fixtures = append(views[fixture.Round-1].Fixtures, fixtureView{
+			Fixture: fixture,
+			Pair1:   pairs[fixture.Pair1ID.Int64],
+			Pair2:   pairs[fixture.Pair2ID.Int64],
+			Winner:  pairs[fixture.WinnerPairID.Int64],
+		})
+	}
+	return views
+}
+
+func roundName(tournament *tournamentmodel.Tournament, round, lastRound int) string {
+	if tournament.IsKnockout() {
+		switch lastRound - round {
+		case 0:
+			return "Final"
+		case 1:
+			return "Semi-finals"
+		case 2:
+			return "Quarter-finals"
+		}
+	}
+	return "Round " + strconv.Itoa(round)
+}
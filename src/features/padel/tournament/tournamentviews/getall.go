@@ -0,0 +1,27 @@
+package tournamentviews
+
+import (
+	"ct-padel-s/src/features/padel/tournament/tournamentmodel"
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+)
+
+//go:embed getall.html
+var getAllHTML string
+var getAllComponent = utils.NewComponent("getall.html", getAllHTML)
+
+//go:embed getallbreadcrumb.html
+var getAllBreadcrumbHTML string
+var getAllBreadcrumbComponent = utils.NewComponent("getallbreadcrumb.html", getAllBreadcrumbHTML)
+
+func RenderGetAll(tournaments []*tournamentmodel.Tournament) (template.HTML, error) {
+	return getAllComponent.Render(map[string]any{
+		"Tournaments":      tournaments,
+		"DefaultTiebreaks": tournamentmodel.DefaultTiebreaks,
+	})
+}
+
+func RenderGetAllBreadcrumb() (template.HTML, error) {
+	return getAllBreadcrumbComponent.Render(nil)
+}
@@ -0,0 +1,105 @@
+// Package chapters turns a match's points into WebVTT chapter cues so a
+// video player can jump from point to point.
+package chapters
+
+import (
+	"bufio"
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"ct-padel-s/src/features/padel/match/matchmodel"
+	"ct-padel-s/src/features/padel/scoring"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// tailMs is how long the last chapter runs past its final shot
+const tailMs = 5000
+
+// Chapter is one point of the match in the video, titled with the score
+// before the point was played.
+type Chapter struct {
+	StartMs int64
+	EndMs   int64
+	Title   string
+}
+
+// Build returns a chapter for every point with a video offset, in match
+// order. A point without its own offset starts at its first play's offset.
+// Each chapter ends where the next begins. The score is kept under rules,
+// skipping rallies whose winner is unknown.
+func Build(match *matchmodel.MatchWithPlayers, rules scoring.Rules, rallies []*analyticsmodel.Rally) []Chapter {
+	var chapters []Chapter
+	var lastShots []int64
+	score := scoring.New(rules)
+
+	for _, rally := range rallies {
+		games := score.Sets[len(score.Sets)-1]
+		title := fmt.Sprintf("Set %d, Game %d, Point %d (sets %d-%d, games %d-%d, %s)",
+			rally.SetNumber, rally.GameNumber, rally.PointNumber,
+			score.SetsWon[analyticsmodel.Team1], score.SetsWon[analyticsmodel.Team2],
+			games[analyticsmodel.Team1], games[analyticsmodel.Team2],
+			score.GamePoints())
+
+		score.Point(rally.Winner(match))
+
+		start, lastShot, ok := offsets(rally)
+		if !ok {
+			continue
+		}
+		chapters = append(chapters, Chapter{StartMs: start, Title: title})
+		lastShots = append(lastShots, lastShot)
+	}
+
+	for i := range chapters {
+		end := lastShots[i] + tailMs
+		if i+1 < len(chapters) {
+			end = chapters[i+1].StartMs
+		}
+		// Cues must have a positive length even when taps coincide
+		if end <= chapters[i].StartMs {
+			end = chapters[i].StartMs + 1
+		}
+		chapters[i].EndMs = end
+	}
+	return chapters
+}
+
+// offsets returns where the rally starts and its last timed shot.
+func offsets(rally *analyticsmodel.Rally) (start, lastShot int64, ok bool) {
+	for _, play := range rally.Plays {
+		if !play.VideoOffsetMs.Valid {
+			continue
+		}
+		if !ok {
+			start, ok = play.VideoOffsetMs.Int64, true
+		}
+		lastShot = play.VideoOffsetMs.Int64
+	}
+	if rally.VideoOffsetMs.Valid {
+		start = rally.VideoOffsetMs.Int64
+		if !ok || lastShot < start {
+			lastShot = start
+		}
+		ok = true
+	}
+	return start, lastShot, ok
+}
+
+// WriteVTT writes chapters as a WebVTT file.
+func WriteVTT(w io.Writer, chapters []Chapter) error {
+	out := bufio.NewWriter(w)
+	out.WriteString("WEBVTT\n")
+	for i, chapter := range chapters {
+		fmt.Fprintf(out, "\n%d\n%s --> %s\n%s\n",
+			i+1, timestamp(chapter.StartMs), timestamp(chapter.EndMs), escape(chapter.Title))
+	}
+	return out.Flush()
+}
+
+func timestamp(ms int64) string {
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+func escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
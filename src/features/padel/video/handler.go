@@ -0,0 +1,133 @@
+package video
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsrepo"
+	"ct-padel-s/src/features/padel/hierarchy"
+	"ct-padel-s/src/features/padel/match/matchrepo"
+	"ct-padel-s/src/features/padel/scoring"
+	"ct-padel-s/src/features/padel/video/chapters"
+	"ct-padel-s/src/features/padel/video/videomodel"
+	"ct-padel-s/src/features/padel/video/videorepo"
+	"ct-padel-s/src/infrastructure/database"
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/shared/httperror"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SetURL sets the match video, or clears it when video_url is empty.
+func SetURL(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+	match := hierarchy.FromRequest(r).Match
+
+	value := strings.TrimSpace(r.FormValue("video_url"))
+	if value != "" {
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			httperror.Write(w, r, http.StatusBadRequest, "Video must be an http or https URL")
+			return
+		}
+	}
+
+	if err := matchrepo.SetVideoURL(db, match.ID, sql.NullString{String: value, Valid: value != ""}); err != nil {
+		logger.Error("Failed to set match video", "error", err, "matchID", match.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to set match video")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/matches/%d", match.ID))
+	w.WriteHeader(http.StatusOK)
+}
+
+// SetOffsets assigns video offsets from a "tap on each hit" sequence to the
+// match's plays in order, starting at from_play_id or the first play.
+func SetOffsets(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+	match := hierarchy.FromRequest(r).Match
+
+	taps, err := videomodel.ParseTaps(r.FormValue("taps"))
+	if err != nil {
+		httperror.Write(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(taps) == 0 {
+		httperror.Write(w, r, http.StatusBadRequest, "No taps given")
+		return
+	}
+
+	fromPlayID := 0
+	if value := r.FormValue("from_play_id"); value != "" {
+		fromPlayID, err = strconv.Atoi(value)
+		if err != nil {
+			httperror.Write(w, r, http.StatusBadRequest, "Invalid play ID")
+			return
+		}
+	}
+
+	rallies, err := analyticsrepo.GetRalliesByMatch(db, match.ID)
+	if err != nil {
+		logger.Error("Failed to get rallies", "error", err, "matchID", match.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get plays")
+		return
+	}
+
+	offsets, err := videomodel.AssignTaps(rallies, fromPlayID, taps)
+	switch {
+	case err == nil:
+	case errors.Is(err, videomodel.ErrPlayNotFound):
+		httperror.NotFound(w, r, "Play not found in this match")
+		return
+	case errors.Is(err, videomodel.ErrTooManyTaps):
+		httperror.Write(w, r, http.StatusBadRequest, err.Error())
+		return
+	default:
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to assign taps")
+		return
+	}
+
+	if err := videorepo.SetOffsets(db, offsets); err != nil {
+		logger.Error("Failed to set video offsets", "error", err, "matchID", match.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to set video offsets")
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path, "plays", len(offsets))
+
+	w.Header().Set("HX-Redirect", fmt.Sprintf("/matches/%d", match.ID))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Chapters serves a WebVTT chapter file with a cue for every timed point.
+func Chapters(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromRequest(r)
+	logger.Debug("Handling", "method", r.Method, "path", r.URL.Path)
+	db := database.GetDB()
+	match := hierarchy.FromRequest(r).Match
+
+	rallies, err := analyticsrepo.GetRalliesByMatch(db, match.ID)
+	if err != nil {
+		logger.Error("Failed to get rallies", "error", err, "matchID", match.ID)
+		httperror.Write(w, r, http.StatusInternalServerError, "Failed to get points")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="match-%d-chapters.vtt"`, match.ID))
+	if err := chapters.WriteVTT(w, chapters.Build(match, scoring.DefaultRules, rallies)); err != nil {
+		logger.Error("Failed to write chapters", "error", err, "matchID", match.ID)
+		return
+	}
+
+	logger.Info("Handled", "method", r.Method, "path", r.URL.Path)
+}
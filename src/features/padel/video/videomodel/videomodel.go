@@ -0,0 +1,120 @@
+package videomodel
+
+import (
+	"ct-padel-s/src/features/padel/analytics/analyticsmodel"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrPlayNotFound = errors.New("play not found in match")
+	ErrTooManyTaps  = errors.New("more taps than plays")
+)
+
+// PlayOffset is where a play happens in the match video. FirstInPoint marks
+// the opening play, whose offset also starts the point.
+type PlayOffset struct {
+	PlayID       int
+	PointID      int
+	OffsetMs     int64
+	FirstInPoint bool
+}
+
+// ParseTaps parses a "tap on each hit" sequence: video offsets separated by
+// commas or whitespace, each either seconds ("83.4") or a clock time
+// ("1:23.4", "1:01:23.4"). Offsets may not go backwards.
+func ParseTaps(value string) ([]int64, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	taps := make([]int64, 0, len(fields))
+	for i, field := range fields {
+		offset, err := ParseOffset(field)
+		if err != nil {
+			return nil, fmt.Errorf("tap %d: %w", i+1, err)
+		}
+		if len(taps) > 0 && offset < taps[len(taps)-1] {
+			return nil, fmt.Errorf("tap %d (%s) is earlier than the tap before it", i+1, field)
+		}
+		taps = append(taps, offset)
+	}
+	return taps, nil
+}
+
+// MaxOffsetMs is the largest offset the database stores, about 596 hours
+const MaxOffsetMs = math.MaxInt32
+
+// ParseOffset parses seconds or a clock time into milliseconds, up to
+// MaxOffsetMs.
+func ParseOffset(value string) (int64, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid offset %q", value)
+	}
+
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil || math.IsNaN(seconds) || seconds < 0 || math.IsInf(seconds, 0) || (len(parts) > 1 && seconds >= 60) {
+		return 0, fmt.Errorf("invalid offset %q", value)
+	}
+	for i, part := range parts[:len(parts)-1] {
+		n, err := strconv.Atoi(part)
+		// Only the leading unit may exceed its clock range
+		if err != nil || n < 0 || (i > 0 && n >= 60) {
+			return 0, fmt.Errorf("invalid offset %q", value)
+		}
+		seconds += float64(n) * math.Pow(60, float64(len(parts)-1-i))
+	}
+	// Checked before converting, as a float beyond int64 has no defined
+	// conversion
+	if math.Round(seconds*1000) > MaxOffsetMs {
+		return 0, fmt.Errorf("offset %q is too long", value)
+	}
+	return int64(math.Round(seconds * 1000)), nil
+}
+
+// FormatOffset formats milliseconds as a clock time such as "1:23.4".
+func FormatOffset(ms int64) string {
+	tenths := (ms + 50) / 100
+	seconds := tenths / 10
+	text := fmt.Sprintf("%d:%02d.%d", seconds/60%60, seconds%60, tenths%10)
+	if hours := seconds / 3600; hours > 0 {
+		text = fmt.Sprintf("%d:%02d:%02d.%d", hours, seconds/60%60, seconds%60, tenths%10)
+	}
+	return text
+}
+
+// AssignTaps pairs taps with the match's plays in order, starting from the
+// play fromPlayID, or the first play when it is zero. Plays after the last
+// tap keep their offsets.
+func AssignTaps(rallies []*analyticsmodel.Rally, fromPlayID int, taps []int64) ([]PlayOffset, error) {
+	var offsets []PlayOffset
+	started := fromPlayID == 0
+	for _, rally := range rallies {
+		for i, play := range rally.Plays {
+			if !started && play.ID == fromPlayID {
+				started = true
+			}
+			if !started || len(offsets) == len(taps) {
+				continue
+			}
+			offsets = append(offsets, PlayOffset{
+				PlayID:       play.ID,
+				PointID:      play.PointID,
+				OffsetMs:     taps[len(offsets)],
+				FirstInPoint: i == 0,
+			})
+		}
+	}
+
+	if !started {
+		return nil, ErrPlayNotFound
+	}
+	if len(offsets) < len(taps) {
+		return nil, fmt.Errorf("%w: %d taps for %d plays", ErrTooManyTaps, len(taps), len(offsets))
+	}
+	return offsets, nil
+}
@@ -0,0 +1,95 @@
+package videomodel
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseOffset(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{"0", 0},
+		{"83.4", 83400},
+		{"83", 83000},
+		{"0.0005", 1},
+		{"1:23.4", 83400},
+		{"0:05", 5000},
+		{"90:00", 5400000},
+		{"1:01:23.4", 3683400},
+		{"12:00:00", 43200000},
+		{"2147483.647", MaxOffsetMs},
+	}
+	for _, test := range tests {
+		got, err := ParseOffset(test.value)
+		if err != nil {
+			t.Errorf("ParseOffset(%q): %v", test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseOffset(%q) = %d, want %d", test.value, got, test.want)
+		}
+	}
+}
+
+func TestParseOffsetInvalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"abc",
+		"1:60",
+		"1:60:00",
+		"1:-5",
+		"-1",
+		"-0.5",
+		"NaN",
+		"nan",
+		"Inf",
+		"+Inf",
+		"-Inf",
+		"1:NaN",
+		"1e300",
+		"2147483.648",
+		"596:31:24",
+		"1:2:3:4",
+	} {
+		if got, err := ParseOffset(value); err == nil {
+			t.Errorf("ParseOffset(%q) = %d, want an error", value, got)
+		}
+	}
+}
+
+func TestParseTaps(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []int64
+	}{
+		{"", []int64{}},
+		{"1.5", []int64{1500}},
+		{"1.5, 2.5,3", []int64{1500, 2500, 3000}},
+		{"59.5 1:00.5\n1:00.5", []int64{59500, 60500, 60500}},
+	}
+	for _, test := range tests {
+		got, err := ParseTaps(test.value)
+		if err != nil {
+			t.Errorf("ParseTaps(%q): %v", test.value, err)
+			continue
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("ParseTaps(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestParseTapsInvalid(t *testing.T) {
+	for _, value := range []string{
+		"2.5, 1.5",
+		"1:00, 59.9",
+		"1, 2, x",
+		"1, NaN",
+	} {
+		if got, err := ParseTaps(value); err == nil {
+			t.Errorf("ParseTaps(%q) = %v, want an error", value, got)
+		}
+	}
+}
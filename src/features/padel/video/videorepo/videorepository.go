@@ -0,0 +1,32 @@
+package videorepo
+
+import (
+	"ct-padel-s/src/features/padel/video/videomodel"
+	"ct-padel-s/src/infrastructure/database"
+)
+
+// SetOffsets stores the video offsets of plays, and of the points they open,
+// in one transaction.
+func SetOffsets(db *database.DB, offsets []videomodel.PlayOffset) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, offset := range offsets {
+		if _, err := tx.Exec(`UPDATE plays SET video_offset_ms = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`,
+			offset.OffsetMs, offset.PlayID); err != nil {
+			return err
+		}
+		if !offset.FirstInPoint {
+			continue
+		}
+		if _, err := tx.Exec(`UPDATE points SET video_offset_ms = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`,
+			offset.OffsetMs, offset.PointID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
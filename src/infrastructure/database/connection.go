@@ -3,6 +3,7 @@ package database
 import (
 	"ct-padel-s/src/infrastructure/env"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -10,10 +11,22 @@ import (
 	_ "github.com/lib/pq"
 )
 
+// ErrConflict is returned by repositories when a conditional write finds the
+// row at a different version than the caller last read.
+var ErrConflict = errors.New("row was modified concurrently")
+
 type DB struct {
 	*sql.DB
 }
 
+// Querier is satisfied by both *DB and *sql.Tx, so repository functions that
+// accept it can run inside or outside a transaction.
+type Querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
 var instance *DB
 
 func Initialize() (*DB, error) {
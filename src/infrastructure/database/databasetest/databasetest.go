@@ -0,0 +1,116 @@
+// Package databasetest runs repository tests against a real Postgres
+// database, named by TEST_DATABASE_URL. Tests that need one are skipped
+// when it is not set. Each fixture is inserted fresh, so tests don't depend
+// on what is already in the database.
+package databasetest
+
+import (
+	"ct-padel-s/src/infrastructure/database"
+	"database/sql"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+// Open connects to TEST_DATABASE_URL and runs the migrations, skipping the
+// test when it is not set.
+func Open(t testing.TB) *database.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	conn, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	db := &database.DB{DB: conn}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+	return db
+}
+
+// Match inserts four players and a match between them and returns the
+// match's id.
+func Match(t testing.TB, db *database.DB) int {
+	t.Helper()
+	var players [4]int
+	for i := range players {
+		players[i] = insert(t, db, `INSERT INTO players (name) VALUES ('Test player') RETURNING id`)
+	}
+	return insert(t, db, `INSERT INTO matches (team1_player1_id, team1_player2_id, team2_player1_id, team2_player2_id)
+						  VALUES ($1, $2, $3, $4) RETURNING id`, players[0], players[1], players[2], players[3])
+}
+
+// Set inserts a match with one set and returns the set's id.
+func Set(t testing.TB, db *database.DB) int {
+	t.Helper()
+	return insert(t, db, `INSERT INTO sets (match_id, set_number) VALUES ($1, 1) RETURNING id`, Match(t, db))
+}
+
+// Game inserts a match with one set of one game and returns the game's id.
+func Game(t testing.TB, db *database.DB) int {
+	t.Helper()
+	return insert(t, db, `INSERT INTO games (set_id, game_number) VALUES ($1, 1) RETURNING id`, Set(t, db))
+}
+
+// Point inserts a match down to a single point and returns the point's id.
+func Point(t testing.TB, db *database.DB) int {
+	t.Helper()
+	return insert(t, db, `INSERT INTO points (game_id, point_number) VALUES ($1, 1) RETURNING id`, Game(t, db))
+}
+
+// Concurrently calls create n times at once, failing the test for each
+// call that returns an error.
+func Concurrently(t testing.TB, n int, create func() error) {
+	t.Helper()
+	start := make(chan struct{})
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- create()
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("create: %v", err)
+		}
+	}
+}
+
+// Numbered fails the test unless numbers, in any order, are 1 to
+// len(numbers).
+func Numbered(t testing.TB, numbers []int) {
+	t.Helper()
+	sorted := slices.Sorted(slices.Values(numbers))
+	for i, number := range sorted {
+		if number != i+1 {
+			t.Errorf("numbers = %v, want 1 to %d", sorted, len(numbers))
+			return
+		}
+	}
+}
+
+func insert(t testing.TB, db *database.DB, query string, args ...any) int {
+	t.Helper()
+	var id int
+	if err := db.QueryRow(query, args...).Scan(&id); err != nil {
+		t.Fatalf("insert fixture: %v", err)
+	}
+	return id
+}
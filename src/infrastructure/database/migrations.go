@@ -17,6 +17,8 @@ type Migration struct {
 	Down    string
 }
 
+// migrations is kept in version order as they are registered, so it is
+// never reordered while requests read it
 var migrations = []Migration{}
 
 func RegisterMigration(version int, name, up, down string) {
@@ -26,6 +28,9 @@ func RegisterMigration(version int, name, up, down string) {
 		Up:      up,
 		Down:    down,
 	})
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
 }
 
 func RunMigrations(db *DB) error {
@@ -38,10 +43,6 @@ func RunMigrations(db *DB) error {
 		return fmt.Errorf("failed to get applied migrations: %w", err)
 	}
 
-	sort.Slice(migrations, func(i, j int) bool {
-		return migrations[i].Version < migrations[j].Version
-	})
-
 	for _, migration := range migrations {
 		if _, applied := appliedMigrations[migration.Version]; applied {
 			slog.Debug("Migration already applied", "version", migration.Version, "name", migration.Name)
@@ -75,6 +76,24 @@ func RunMigrations(db *DB) error {
 	return nil
 }
 
+// PendingMigrations returns the registered migrations that have not yet been
+// recorded in schema_migrations, ordered by version.
+func PendingMigrations(db *DB) ([]Migration, error) {
+	appliedMigrations, err := getAppliedMigrations(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if !appliedMigrations[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
 func createMigrationsTable(db *DB) error {
 	query := `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
@@ -111,4 +130,59 @@ func init() {
 	v001Down, _ := migrationFiles.ReadFile("migrations/001_down.sql")
 
 	RegisterMigration(1, "create_padel_tables", string(v001Up), string(v001Down))
+
+	v002Up, _ := migrationFiles.ReadFile("migrations/002_up.sql")
+	v002Down, _ := migrationFiles.ReadFile("migrations/002_down.sql")
+
+	RegisterMigration(2, "add_row_versions", string(v002Up), string(v002Down))
+
+	v003Up, _ := migrationFiles.ReadFile("migrations/003_up.sql")
+	v003Down, _ := migrationFiles.ReadFile("migrations/003_down.sql")
+
+	RegisterMigration(3, "deferrable_numbering_constraints", string(v003Up), string(v003Down))
+
+	v004Up, _ := migrationFiles.ReadFile("migrations/004_up.sql")
+	v004Down, _ := migrationFiles.ReadFile("migrations/004_down.sql")
+
+	RegisterMigration(4, "add_career_stats_indexes", string(v004Up), string(v004Down))
+
+	v005Up, _ := migrationFiles.ReadFile("migrations/005_up.sql")
+	v005Down, _ := migrationFiles.ReadFile("migrations/005_down.sql")
+
+	RegisterMigration(5, "add_match_completion_and_ratings", string(v005Up), string(v005Down))
+
+	v006Up, _ := migrationFiles.ReadFile("migrations/006_up.sql")
+	v006Down, _ := migrationFiles.ReadFile("migrations/006_down.sql")
+
+	RegisterMigration(6, "create_tournament_tables", string(v006Up), string(v006Down))
+
+	v007Up, _ := migrationFiles.ReadFile("migrations/007_up.sql")
+	v007Down, _ := migrationFiles.ReadFile("migrations/007_down.sql")
+
+	RegisterMigration(7, "create_scheduling_tables", string(v007Up), string(v007Down))
+
+	v008Up, _ := migrationFiles.ReadFile("migrations/008_up.sql")
+	v008Down, _ := migrationFiles.ReadFile("migrations/008_down.sql")
+
+	RegisterMigration(8, "add_video_offsets", string(v008Up), string(v008Down))
+
+	v009Up, _ := migrationFiles.ReadFile("migrations/009_up.sql")
+	v009Down, _ := migrationFiles.ReadFile("migrations/009_down.sql")
+
+	RegisterMigration(9, "add_point_timing", string(v009Up), string(v009Down))
+
+	v010Up, _ := migrationFiles.ReadFile("migrations/010_up.sql")
+	v010Down, _ := migrationFiles.ReadFile("migrations/010_down.sql")
+
+	RegisterMigration(10, "add_rally_checks", string(v010Up), string(v010Down))
+
+	v011Up, _ := migrationFiles.ReadFile("migrations/011_up.sql")
+	v011Down, _ := migrationFiles.ReadFile("migrations/011_down.sql")
+
+	RegisterMigration(11, "add_play_walls", string(v011Up), string(v011Down))
+
+	v012Up, _ := migrationFiles.ReadFile("migrations/012_up.sql")
+	v012Down, _ := migrationFiles.ReadFile("migrations/012_down.sql")
+
+	RegisterMigration(12, "create_shot_catalogue", string(v012Up), string(v012Down))
 }
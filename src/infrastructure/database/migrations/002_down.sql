@@ -0,0 +1,3 @@
+ALTER TABLE plays DROP COLUMN IF EXISTS version;
+ALTER TABLE points DROP COLUMN IF EXISTS version;
+ALTER TABLE games DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Row versions for optimistic concurrency control on edits
+ALTER TABLE games ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE points ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE plays ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,15 @@
+ALTER TABLE plays
+    DROP CONSTRAINT plays_point_id_play_number_key,
+    ADD CONSTRAINT plays_point_id_play_number_key UNIQUE (point_id, play_number);
+
+ALTER TABLE points
+    DROP CONSTRAINT points_game_id_point_number_key,
+    ADD CONSTRAINT points_game_id_point_number_key UNIQUE (game_id, point_number);
+
+ALTER TABLE games
+    DROP CONSTRAINT games_set_id_game_number_key,
+    ADD CONSTRAINT games_set_id_game_number_key UNIQUE (set_id, game_number);
+
+ALTER TABLE sets
+    DROP CONSTRAINT sets_match_id_set_number_key,
+    ADD CONSTRAINT sets_match_id_set_number_key UNIQUE (match_id, set_number);
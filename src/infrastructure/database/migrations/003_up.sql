@@ -0,0 +1,17 @@
+-- Make the per-parent numbering constraints deferrable so that renumbering
+-- siblings after a delete can be checked once at commit instead of per row.
+ALTER TABLE sets
+    DROP CONSTRAINT sets_match_id_set_number_key,
+    ADD CONSTRAINT sets_match_id_set_number_key UNIQUE (match_id, set_number) DEFERRABLE INITIALLY IMMEDIATE;
+
+ALTER TABLE games
+    DROP CONSTRAINT games_set_id_game_number_key,
+    ADD CONSTRAINT games_set_id_game_number_key UNIQUE (set_id, game_number) DEFERRABLE INITIALLY IMMEDIATE;
+
+ALTER TABLE points
+    DROP CONSTRAINT points_game_id_point_number_key,
+    ADD CONSTRAINT points_game_id_point_number_key UNIQUE (game_id, point_number) DEFERRABLE INITIALLY IMMEDIATE;
+
+ALTER TABLE plays
+    DROP CONSTRAINT plays_point_id_play_number_key,
+    ADD CONSTRAINT plays_point_id_play_number_key UNIQUE (point_id, play_number) DEFERRABLE INITIALLY IMMEDIATE;
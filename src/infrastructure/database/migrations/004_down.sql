@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_matches_match_date;
+DROP INDEX IF EXISTS idx_plays_player_id;
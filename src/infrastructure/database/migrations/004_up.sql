@@ -0,0 +1,3 @@
+-- Indexes for player career statistics
+CREATE INDEX idx_plays_player_id ON plays(player_id);
+CREATE INDEX idx_matches_match_date ON matches(match_date);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS rating_history;
+ALTER TABLE matches DROP COLUMN IF EXISTS completed_at;
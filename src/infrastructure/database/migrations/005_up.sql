@@ -0,0 +1,17 @@
+-- Matches are rated once they are marked complete
+ALTER TABLE matches ADD COLUMN completed_at TIMESTAMP;
+
+-- Rating history: one row per player per rated match
+CREATE TABLE rating_history (
+    id SERIAL PRIMARY KEY,
+    player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    rating_before DOUBLE PRECISION NOT NULL,
+    rating_after DOUBLE PRECISION NOT NULL,
+    rated_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(player_id, match_id)
+);
+
+CREATE INDEX idx_rating_history_player_id ON rating_history(player_id, rated_at);
+CREATE INDEX idx_rating_history_match_id ON rating_history(match_id);
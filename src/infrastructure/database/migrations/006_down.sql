@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS fixtures;
+DROP TABLE IF EXISTS tournament_pairs;
+DROP TABLE IF EXISTS divisions;
+DROP TABLE IF EXISTS tournaments;
@@ -0,0 +1,49 @@
+-- Tournaments: round-robin leagues and single-elimination knockouts
+CREATE TABLE tournaments (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    format VARCHAR(50) NOT NULL CHECK (format IN ('round_robin', 'single_elimination')),
+    tiebreaks VARCHAR(255) NOT NULL DEFAULT 'head_to_head,set_difference,game_difference',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Divisions split a tournament's entries into separately played groups
+CREATE TABLE divisions (
+    id SERIAL PRIMARY KEY,
+    tournament_id INTEGER NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tournament_id, name)
+);
+
+-- Registered pairs, stored with the lower player ID first
+CREATE TABLE tournament_pairs (
+    id SERIAL PRIMARY KEY,
+    division_id INTEGER NOT NULL REFERENCES divisions(id) ON DELETE CASCADE,
+    player1_id INTEGER NOT NULL REFERENCES players(id),
+    player2_id INTEGER NOT NULL REFERENCES players(id),
+    seed INTEGER CHECK (seed > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (player1_id < player2_id),
+    UNIQUE(division_id, player1_id, player2_id)
+);
+
+-- Fixtures; knockout fixtures beyond the first round start without pairs
+CREATE TABLE fixtures (
+    id SERIAL PRIMARY KEY,
+    division_id INTEGER NOT NULL REFERENCES divisions(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL CHECK (round > 0),
+    position INTEGER NOT NULL CHECK (position >= 0),
+    pair1_id INTEGER REFERENCES tournament_pairs(id) ON DELETE CASCADE,
+    pair2_id INTEGER REFERENCES tournament_pairs(id) ON DELETE CASCADE,
+    winner_pair_id INTEGER REFERENCES tournament_pairs(id) ON DELETE SET NULL,
+    match_id INTEGER UNIQUE REFERENCES matches(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(division_id, round, position)
+);
+
+CREATE INDEX idx_divisions_tournament_id ON divisions(tournament_id);
+CREATE INDEX idx_tournament_pairs_division_id ON tournament_pairs(division_id);
+CREATE INDEX idx_fixtures_division_id ON fixtures(division_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS court_slots;
+DROP TABLE IF EXISTS courts;
@@ -0,0 +1,21 @@
+-- Courts that matches can be booked on
+CREATE TABLE courts (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Bookable time slots; a slot holding a match fixes the match's planned start
+CREATE TABLE court_slots (
+    id SERIAL PRIMARY KEY,
+    court_id INTEGER NOT NULL REFERENCES courts(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    match_id INTEGER UNIQUE REFERENCES matches(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_court_slots_court_id_starts_at ON court_slots(court_id, starts_at);
+CREATE INDEX idx_court_slots_starts_at ON court_slots(starts_at);
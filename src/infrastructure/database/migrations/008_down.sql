@@ -0,0 +1,3 @@
+ALTER TABLE plays DROP COLUMN IF EXISTS video_offset_ms;
+ALTER TABLE points DROP COLUMN IF EXISTS video_offset_ms;
+ALTER TABLE matches DROP COLUMN IF EXISTS video_url;
@@ -0,0 +1,4 @@
+-- Match video, and where each point and play happens in it
+ALTER TABLE matches ADD COLUMN video_url TEXT;
+ALTER TABLE points ADD COLUMN video_offset_ms INTEGER CHECK (video_offset_ms >= 0);
+ALTER TABLE plays ADD COLUMN video_offset_ms INTEGER CHECK (video_offset_ms >= 0);
@@ -0,0 +1,4 @@
+ALTER TABLE plays DROP COLUMN IF EXISTS hit_at;
+ALTER TABLE points DROP CONSTRAINT IF EXISTS points_ended_after_started;
+ALTER TABLE points DROP COLUMN IF EXISTS ended_at;
+ALTER TABLE points DROP COLUMN IF EXISTS started_at;
@@ -0,0 +1,6 @@
+-- Match-time timing, as opposed to created_at/updated_at which record data
+-- entry. All are optional: points entered after the match have none.
+ALTER TABLE points ADD COLUMN started_at TIMESTAMP;
+ALTER TABLE points ADD COLUMN ended_at TIMESTAMP;
+ALTER TABLE points ADD CONSTRAINT points_ended_after_started CHECK (ended_at >= started_at);
+ALTER TABLE plays ADD COLUMN hit_at TIMESTAMP;
@@ -0,0 +1,4 @@
+-- How a match treats rallies that break the rules: warn and save anyway,
+-- or refuse the edit
+ALTER TABLE matches ADD COLUMN rally_checks TEXT NOT NULL DEFAULT 'warn'
+    CHECK (rally_checks IN ('warn', 'enforce'));
@@ -0,0 +1,2 @@
+ALTER TABLE plays DROP COLUMN IF EXISTS exit_court;
+ALTER TABLE plays DROP COLUMN IF EXISTS wall_bounces;
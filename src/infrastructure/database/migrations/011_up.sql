@@ -0,0 +1,5 @@
+-- How the ball travelled after each shot: the walls it hit, in order, and
+-- where it left the court, if it did
+ALTER TABLE plays ADD COLUMN wall_bounces TEXT[] NOT NULL DEFAULT '{}'
+    CHECK (wall_bounces <@ ARRAY['back_glass', 'side_glass', 'fence'] AND cardinality(wall_bounces) <= 3);
+ALTER TABLE plays ADD COLUMN exit_court VARCHAR(50) CHECK (exit_court IN ('door', 'over_wall'));
@@ -0,0 +1,18 @@
+ALTER TABLE plays DROP CONSTRAINT IF EXISTS plays_shot_effect_fkey;
+ALTER TABLE plays DROP CONSTRAINT IF EXISTS plays_contact_type_fkey;
+
+-- Fold the catalogue back onto the fixed lists: lobs go up, overhead
+-- specialities are smashes, a chiquita is a drop, anything else is cleared
+UPDATE plays SET shot_effect = 'up', version = version + 1 WHERE shot_effect = 'lob';
+UPDATE plays SET shot_effect = 'smash', version = version + 1 WHERE shot_effect IN ('bandeja', 'vibora', 'bajada');
+UPDATE plays SET shot_effect = 'drop', version = version + 1 WHERE shot_effect = 'chiquita';
+UPDATE plays SET shot_effect = NULL, version = version + 1 WHERE shot_effect NOT IN ('flat', 'up', 'down', 'drop', 'smash');
+UPDATE plays SET contact_type = NULL, version = version + 1 WHERE contact_type NOT IN ('serve', 'groundstroke', 'volley', 'overhead');
+
+ALTER TABLE plays ADD CONSTRAINT plays_contact_type_check
+    CHECK (contact_type IN ('serve', 'groundstroke', 'volley', 'overhead'));
+ALTER TABLE plays ADD CONSTRAINT plays_shot_effect_check
+    CHECK (shot_effect IN ('flat', 'up', 'down', 'drop', 'smash'));
+
+DROP TABLE IF EXISTS shot_effects;
+DROP TABLE IF EXISTS contact_types;
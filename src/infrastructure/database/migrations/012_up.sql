@@ -0,0 +1,50 @@
+-- Shot catalogue: the contact types and shot effects a play may record,
+-- replacing the fixed CHECK lists on plays. Entries are retired rather than
+-- deleted so plays that recorded them keep their meaning.
+CREATE TABLE contact_types (
+    code VARCHAR(50) PRIMARY KEY CHECK (code ~ '^[a-z][a-z_]*$'),
+    label VARCHAR(100) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- contact_type, when set, is the only contact type the effect is played
+-- with, e.g. a bandeja is an overhead
+CREATE TABLE shot_effects (
+    code VARCHAR(50) PRIMARY KEY CHECK (code ~ '^[a-z][a-z_]*$'),
+    label VARCHAR(100) NOT NULL,
+    contact_type VARCHAR(50) REFERENCES contact_types(code) ON UPDATE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO contact_types (code, label, position) VALUES
+    ('serve', 'Serve', 1),
+    ('groundstroke', 'Groundstroke', 2),
+    ('volley', 'Volley', 3),
+    ('overhead', 'Overhead', 4);
+
+INSERT INTO shot_effects (code, label, contact_type, position) VALUES
+    ('flat', 'Flat', NULL, 1),
+    ('lob', 'Lob', NULL, 2),
+    ('down', 'Down', NULL, 3),
+    ('drop', 'Drop', NULL, 4),
+    ('chiquita', 'Chiquita', NULL, 5),
+    ('smash', 'Smash', 'overhead', 6),
+    ('bandeja', 'Bandeja', 'overhead', 7),
+    ('vibora', 'Víbora', 'overhead', 8),
+    ('bajada', 'Bajada', NULL, 9);
+
+-- A ball hit up is a lob
+UPDATE plays SET shot_effect = 'lob', version = version + 1 WHERE shot_effect = 'up';
+
+ALTER TABLE plays DROP CONSTRAINT IF EXISTS plays_contact_type_check;
+ALTER TABLE plays DROP CONSTRAINT IF EXISTS plays_shot_effect_check;
+ALTER TABLE plays ADD CONSTRAINT plays_contact_type_fkey
+    FOREIGN KEY (contact_type) REFERENCES contact_types(code) ON UPDATE CASCADE;
+ALTER TABLE plays ADD CONSTRAINT plays_shot_effect_fkey
+    FOREIGN KEY (shot_effect) REFERENCES shot_effects(code) ON UPDATE CASCADE;
@@ -1,13 +1,19 @@
 package env
 
 import (
+	"errors"
+	"io/fs"
 	"log/slog"
 	"os"
 
 	"github.com/joho/godotenv"
 )
 
-var DatabaseURL string
+var (
+	DatabaseURL string
+	LogFormat   string
+	LogLevel    string
+)
 
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -18,10 +24,14 @@ func getEnv(key, defaultValue string) string {
 
 func init() {
 	slog.Debug("Environment variables initialized", "component", "env")
-	if err := godotenv.Load(); err != nil {
+	// A missing .env is fine, e.g. under go test, where settings come from
+	// the environment
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		slog.Error("Failed to load environment variables", "error", err)
 		panic(err)
 	}
 
 	DatabaseURL = getEnv("DATABASE_URL", "")
+	LogFormat = getEnv("LOG_FORMAT", "text")
+	LogLevel = getEnv("LOG_LEVEL", "debug")
 }
@@ -0,0 +1,27 @@
+// Package httpstatus lets middleware see the status code a handler answered
+// with.
+package httpstatus
+
+import "net/http"
+
+// Recorder wraps a ResponseWriter and remembers the status code written
+// through it, http.StatusOK when the handler never calls WriteHeader.
+type Recorder struct {
+	http.ResponseWriter
+	Status int
+}
+
+// NewRecorder wraps w in a Recorder.
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w, Status: http.StatusOK}
+}
+
+func (rec *Recorder) WriteHeader(statusCode int) {
+	rec.Status = statusCode
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Unwrap lets http.ResponseController reach the underlying ResponseWriter.
+func (rec *Recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -2,13 +2,16 @@ package logging
 
 import (
 	"context"
+	"ct-padel-s/src/infrastructure/env"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // ANSI color codes
@@ -24,6 +27,13 @@ const (
 type ColorHandler struct {
 	opts slog.HandlerOptions
 	out  io.Writer
+	mu   *sync.Mutex
+
+	// attrs holds attributes added through WithAttrs, already formatted with
+	// the group prefix that was open at the time they were added.
+	attrs string
+	// groups holds the groups opened through WithGroup, outermost first.
+	groups []string
 }
 
 func NewColorHandler(out io.Writer, opts *slog.HandlerOptions) *ColorHandler {
@@ -33,11 +43,16 @@ func NewColorHandler(out io.Writer, opts *slog.HandlerOptions) *ColorHandler {
 	return &ColorHandler{
 		opts: *opts,
 		out:  out,
+		mu:   &sync.Mutex{},
 	}
 }
 
 func (h *ColorHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.opts.Level.Level()
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
 }
 
 func (h *ColorHandler) Handle(ctx context.Context, r slog.Record) error {
@@ -80,37 +95,120 @@ func (h *ColorHandler) Handle(ctx context.Context, r slog.Record) error {
 	// Message
 	buf.WriteString(r.Message)
 
-	// Attributes
+	// Attributes added through WithAttrs, then those on the record itself
+	buf.WriteString(h.attrs)
+	prefix := groupPrefix(h.groups)
 	r.Attrs(func(a slog.Attr) bool {
-		buf.WriteString(" ")
-		buf.WriteString(a.Key)
-		buf.WriteString("=")
-		buf.WriteString(a.Value.String())
+		h.appendAttr(&buf, prefix, a)
 		return true
 	})
 
 	buf.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	_, err := h.out.Write([]byte(buf.String()))
 	return err
 }
 
 func (h *ColorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	// For simplicity, return the same handler
-	return h
+	if len(attrs) == 0 {
+		return h
+	}
+
+	var buf strings.Builder
+	buf.WriteString(h.attrs)
+	prefix := groupPrefix(h.groups)
+	for _, a := range attrs {
+		h.appendAttr(&buf, prefix, a)
+	}
+
+	clone := *h
+	clone.attrs = buf.String()
+	return &clone
 }
 
 func (h *ColorHandler) WithGroup(name string) slog.Handler {
-	// For simplicity, return the same handler
-	return h
+	if name == "" {
+		return h
+	}
+
+	clone := *h
+	clone.groups = append(slices.Clip(h.groups), name)
+	return &clone
+}
+
+// appendAttr writes a as " key=value", qualifying the key with prefix and
+// flattening nested groups into dotted keys.
+func (h *ColorHandler) appendAttr(buf *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if h.opts.ReplaceAttr != nil && a.Value.Kind() != slog.KindGroup {
+		a = h.opts.ReplaceAttr(h.groups, a)
+		a.Value = a.Value.Resolve()
+	}
+
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		groupAttrs := a.Value.Group()
+		if len(groupAttrs) == 0 {
+			return
+		}
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix += a.Key + "."
+		}
+		for _, ga := range groupAttrs {
+			h.appendAttr(buf, groupPrefix, ga)
+		}
+		return
+	}
+
+	buf.WriteString(" ")
+	buf.WriteString(prefix)
+	buf.WriteString(a.Key)
+	buf.WriteString("=")
+	buf.WriteString(a.Value.String())
+}
+
+func groupPrefix(groups []string) string {
+	if len(groups) == 0 {
+		return ""
+	}
+	return strings.Join(groups, ".") + "."
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
 }
 
 func init() {
-	// Configure slog with colored output and source location information
+	// Configure slog from LOG_FORMAT / LOG_LEVEL: colored text for local
+	// development, JSON for production log collectors.
 	opts := &slog.HandlerOptions{
-		Level:     slog.LevelDebug,
+		Level:     parseLevel(env.LogLevel),
 		AddSource: true,
 	}
-	handler := NewColorHandler(os.Stdout, opts)
+
+	var handler slog.Handler
+	switch strings.ToLower(env.LogFormat) {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	default:
+		handler = NewColorHandler(os.Stdout, opts)
+	}
+
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
 }
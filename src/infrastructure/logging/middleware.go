@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"ct-padel-s/src/infrastructure/httpstatus"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+type contextKey struct{}
+
+// Middleware attaches a request-scoped logger carrying the request ID and
+// method to the request context, echoes the request ID in the response and
+// logs the route, status and duration once the request completes. Handlers
+// log each request they handle at Info, so the completion line is Debug
+// unless the request failed.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		logger := slog.Default().With("request_id", requestID, "method", r.Method)
+		r = r.WithContext(WithLogger(r.Context(), logger))
+
+		rec := httpstatus.NewRecorder(w)
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelDebug
+		if rec.Status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.Log(r.Context(), level, "Request completed",
+			"route", r.Pattern,
+			"path", r.URL.Path,
+			"status", rec.Status,
+			"duration", time.Since(start))
+	})
+}
+
+// WithLogger returns a copy of ctx carrying logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx, or the default logger when
+// the context carries none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// FromRequest returns the request-scoped logger, annotated with the route
+// pattern the ServeMux matched for r.
+func FromRequest(r *http.Request) *slog.Logger {
+	logger := FromContext(r.Context())
+	if r.Pattern != "" {
+		logger = logger.With("route", r.Pattern)
+	}
+	return logger
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
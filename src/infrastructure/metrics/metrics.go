@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"ct-padel-s/src/infrastructure/database"
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "padel"
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+
+	httpRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests currently being served.",
+	})
+
+	MatchesCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "matches_created_total",
+		Help:      "Number of matches created.",
+	})
+
+	SetsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sets_created_total",
+		Help:      "Number of sets created.",
+	})
+
+	GamesCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_created_total",
+		Help:      "Number of games created.",
+	})
+
+	PointsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "points_created_total",
+		Help:      "Number of points created.",
+	})
+
+	PlaysRecorded = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "plays_recorded_total",
+		Help:      "Number of plays recorded.",
+	})
+
+	PlaysUpdated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "plays_updated_total",
+		Help:      "Number of play edits saved.",
+	})
+)
+
+// RegisterDB exposes the connection pool statistics (sql.DBStats) of db.
+func RegisterDB(db *database.DB) {
+	if err := prometheus.Register(collectors.NewDBStatsCollector(db.DB, "postgres")); err != nil {
+		slog.Error("Failed to register database metrics", "error", err)
+	}
+}
+
+// Handler serves all registered metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"ct-padel-s/src/infrastructure/httpstatus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware records the latency of every request handled by next, labelled by
+// the ServeMux pattern that matched it rather than the raw path so that IDs in
+// the URL do not explode the number of series.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		httpRequestsInFlight.Inc()
+		defer httpRequestsInFlight.Dec()
+
+		rec := httpstatus.NewRecorder(w)
+		next.ServeHTTP(rec, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+
+		httpRequestDuration.
+			WithLabelValues(route, r.Method, strconv.Itoa(rec.Status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
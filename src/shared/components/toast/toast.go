@@ -0,0 +1,35 @@
+package toast
+
+import (
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+	"log/slog"
+)
+
+//go:embed toast.html
+var toastHTML string
+var component = utils.NewComponent("toast.html", toastHTML)
+
+// ContainerID is the id of the element in page.html that toasts are appended to.
+const ContainerID = "toasts"
+
+type Data struct {
+	Status  int
+	Message string
+}
+
+func init() {
+	slog.Debug("Toast component initialized", "component", "toast")
+}
+
+func Render(data Data) (template.HTML, error) {
+	slog.Debug("Rendering toast component", "component", "toast")
+	result, err := component.Render(data)
+	if err != nil {
+		slog.Error("Failed to render toast component", "error", err)
+		return "", err
+	}
+	slog.Debug("Toast component rendered successfully", "component", "toast")
+	return result, nil
+}
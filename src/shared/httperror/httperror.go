@@ -0,0 +1,120 @@
+package httperror
+
+import (
+	"ct-padel-s/src/infrastructure/logging"
+	"ct-padel-s/src/shared/components/footer"
+	"ct-padel-s/src/shared/components/header"
+	"ct-padel-s/src/shared/components/toast"
+	"ct-padel-s/src/shared/templates"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type jsonError struct {
+	Status    int    `json:"status"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Write sends an error response in the shape the client expects: a toast
+// fragment retargeted at the page's toast container for HTMX requests, JSON
+// for API clients and a full themed page for normal navigation.
+func Write(w http.ResponseWriter, r *http.Request, status int, message string) {
+	switch {
+	case IsHTMX(r):
+		writeToast(w, r, status, message)
+	case WantsJSON(r):
+		writeJSON(w, status, message)
+	default:
+		writePage(w, r, status, message)
+	}
+}
+
+// NotFound writes a 404 response for the requested resource.
+func NotFound(w http.ResponseWriter, r *http.Request, message string) {
+	Write(w, r, http.StatusNotFound, message)
+}
+
+// IsHTMX reports whether r was issued by HTMX.
+func IsHTMX(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
+
+// WantsJSON reports whether the client prefers a JSON response over HTML.
+func WantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+func writeToast(w http.ResponseWriter, r *http.Request, status int, message string) {
+	html, err := toast.Render(toast.Data{Status: status, Message: message})
+	if err != nil {
+		logging.FromRequest(r).Error("Failed to render error toast", "error", err)
+		http.Error(w, message, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("HX-Retarget", "#"+toast.ContainerID)
+	w.Header().Set("HX-Reswap", "beforeend")
+	w.WriteHeader(status)
+	io.WriteString(w, string(html))
+}
+
+func writeJSON(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(jsonError{
+		Status:    status,
+		Error:     message,
+		RequestID: w.Header().Get(logging.RequestIDHeader),
+	})
+}
+
+func writePage(w http.ResponseWriter, r *http.Request, status int, message string) {
+	logger := logging.FromRequest(r)
+	title := http.StatusText(status) + " - Padel Tracker"
+
+	headerHTML, err := header.Render(header.Data{Title: title})
+	if err != nil {
+		logger.Error("Failed to render error page header", "error", err)
+		http.Error(w, message, status)
+		return
+	}
+
+	footerHTML, err := footer.Render(footer.Data{})
+	if err != nil {
+		logger.Error("Failed to render error page footer", "error", err)
+		http.Error(w, message, status)
+		return
+	}
+
+	contentHTML, err := templates.RenderError(templates.ErrorData{
+		Status:    status,
+		Message:   message,
+		RequestID: w.Header().Get(logging.RequestIDHeader),
+	})
+	if err != nil {
+		logger.Error("Failed to render error page content", "error", err)
+		http.Error(w, message, status)
+		return
+	}
+
+	page, err := templates.Render(templates.Data{
+		Title:       title,
+		HeaderHTML:  headerHTML,
+		ContentHTML: contentHTML,
+		FooterHTML:  footerHTML,
+	})
+	if err != nil {
+		logger.Error("Failed to render error page", "error", err)
+		http.Error(w, message, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	io.WriteString(w, string(page))
+}
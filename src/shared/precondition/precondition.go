@@ -0,0 +1,43 @@
+package precondition
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// FormField is the hidden form field carrying the version an editor was
+// rendered with, for clients that cannot set an If-Match header.
+const FormField = "version"
+
+// ETag formats a row version as a strong entity tag.
+func ETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// SetETag advertises version on the response so clients can send it back in
+// If-Match.
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", ETag(version))
+}
+
+// ExpectedVersion returns the version the client expects to modify, read from
+// the If-Match header or else the version form field. ok is false when the
+// client sent no precondition; err is set when the one it sent is malformed.
+func ExpectedVersion(r *http.Request) (version int, ok bool, err error) {
+	raw := r.Header.Get("If-Match")
+	if raw == "" {
+		raw = r.FormValue(FormField)
+	}
+	if raw == "" {
+		return 0, false, nil
+	}
+
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "W/")
+	version, err = strconv.Atoi(strings.Trim(raw, `"`))
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid precondition %q: %w", raw, err)
+	}
+	return version, true, nil
+}
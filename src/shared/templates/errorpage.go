@@ -0,0 +1,31 @@
+package templates
+
+import (
+	"ct-padel-s/src/shared/utils"
+	_ "embed"
+	"html/template"
+	"net/http"
+)
+
+//go:embed notfound.html
+var notFoundHTML string
+var notFoundComponent = utils.NewComponent("notfound.html", notFoundHTML)
+
+//go:embed error.html
+var errorHTML string
+var errorComponent = utils.NewComponent("error.html", errorHTML)
+
+type ErrorData struct {
+	Status    int
+	Message   string
+	RequestID string
+}
+
+// RenderError renders the page content for an error response, using the
+// not found template for 404s and the generic error template otherwise.
+func RenderError(data ErrorData) (template.HTML, error) {
+	if data.Status == http.StatusNotFound {
+		return notFoundComponent.Render(data)
+	}
+	return errorComponent.Render(data)
+}